@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,28 +10,44 @@ import (
 
 	"github.com/amaumene/gostremiofr/internal/adapters"
 	"github.com/amaumene/gostremiofr/internal/cache"
+	"github.com/amaumene/gostremiofr/internal/config"
 	"github.com/amaumene/gostremiofr/internal/database"
 	"github.com/amaumene/gostremiofr/internal/handlers"
 	"github.com/amaumene/gostremiofr/internal/services"
+	"github.com/amaumene/gostremiofr/internal/tracing"
 	log "github.com/amaumene/gostremiofr/pkg/logger"
+	"github.com/amaumene/gostremiofr/pkg/security"
 	"github.com/amaumene/gostremiofr/pkg/torrentsearch"
-	"github.com/amaumene/gostremiofr/pkg/torrentsearch/providers"
 )
 
 // Global application components
 var (
 	logger      log.Logger
+	appConfig   *config.Config
 	db          database.Database
 	tmdbCache   *cache.LRUCache
 	httpHandler *handlers.Handler
 	container   *services.Container
+
+	shutdownTracing = func(context.Context) error { return nil }
 )
 
 // initLogger initializes the application logger.
 func initLogger() {
+	log.SetRedactor(security.NewAPIKeyValidator().MaskAPIKey)
 	logger = log.New()
 }
 
+// initTracing installs the OpenTelemetry exporter selected by OTEL_TRACES_EXPORTER.
+func initTracing() {
+	shutdown, err := tracing.Setup(context.Background())
+	if err != nil {
+		logger.Warnf("tracing disabled: %v", err)
+		return
+	}
+	shutdownTracing = shutdown
+}
+
 // initDatabase initializes the BoltDB database.
 func initDatabase() {
 	dbPath := getDatabasePath()
@@ -51,11 +68,20 @@ func getDatabasePath() string {
 	return filepath.Join(dir, "data.db")
 }
 
+// initConfig loads the configuration file and environment variables.
+func initConfig() {
+	var err error
+	appConfig, err = config.Load()
+	if err != nil {
+		panic(fmt.Sprintf("failed to load configuration: %v", err))
+	}
+}
+
 // initServices creates and initializes all application services.
 func initServices() {
 	tmdbCache = createCache()
 	container = createServiceContainer(tmdbCache, db)
-	httpHandler = handlers.New(container, nil)
+	httpHandler = handlers.New(container, appConfig)
 }
 
 // createCache creates a new LRU cache instance.
@@ -64,7 +90,7 @@ func createCache() *cache.LRUCache {
 		cacheSize = 5000
 		cacheTTL  = 24 * time.Hour
 	)
-	return cache.New(cacheSize, cacheTTL)
+	return cache.NewNamed("shared", cacheSize, cacheTTL)
 }
 
 // createServiceContainer creates and configures the service container.
@@ -72,29 +98,44 @@ func createServiceContainer(c *cache.LRUCache, d database.Database) *services.Co
 	// Initialize services
 	tmdb := services.NewTMDB("", c)
 	
-	// Configure AllDebrid service
-	allDebrid := services.NewAllDebrid("")
-	allDebrid.SetDB(d)
+	// Configure debrid providers
+	debridProviders := services.NewDebridProviders(d)
 	
 	// Create cleanup service
-	cleanup := services.NewCleanupService(d, allDebrid)
+	cleanup := services.NewCleanupService(d, debridProviders)
 	
 	// Create torrentsearch with native providers
 	torrentSearch := createTorrentSearch(c)
 	
 	return &services.Container{
 		TMDB:          tmdb,
-		AllDebrid:     allDebrid,
+		Debrid:        debridProviders,
+		DebridHealth:  services.NewAccountHealth(),
 		Cache:         c,
 		DB:            d,
 		Logger:        log.New(),
-		TorrentSorter: services.NewTorrentSorter(nil),
 		Cleanup:       cleanup,
 		TorrentSearch: torrentSearch,
+		Anime:         loadAnimeMapper(),
+	}
+}
+
+// loadAnimeMapper loads the anime ID mapping when configured; anime IDs are unsupported without it.
+func loadAnimeMapper() *services.AnimeMapper {
+	if appConfig.AnimeMappingPath == "" {
+		return nil
+	}
+
+	mapper, err := services.LoadAnimeMapper(appConfig.AnimeMappingPath)
+	if err != nil {
+		logger.Warnf("anime IDs disabled: %v", err)
+		return nil
 	}
+	logger.Infof("loaded %d anime ID mappings", mapper.Len())
+	return mapper
 }
 
-// createTorrentSearch creates the smart torrentsearch with providers.
+// createTorrentSearch creates the smart torrentsearch with the configured providers.
 func createTorrentSearch(c *cache.LRUCache) *torrentsearch.TorrentSearch {
 	// Create cache adapter
 	cacheAdapter := adapters.NewCacheAdapter(c)
@@ -104,18 +145,15 @@ func createTorrentSearch(c *cache.LRUCache) *torrentsearch.TorrentSearch {
 	
 	// Don't set TMDB key here - it will be set per request from client
 	
-	// Register native providers directly
-	yggProvider := providers.NewYGGProvider()
-	yggProvider.SetCache(cacheAdapter)
-	search.RegisterProvider(providers.ProviderYGG, yggProvider)
-	
-	torrentsCSVProvider := providers.NewTorrentsCSVProvider()
-	torrentsCSVProvider.SetCache(cacheAdapter)
-	search.RegisterProvider(providers.ProviderTorrentsCSV, torrentsCSVProvider)
-	
-	apibayProvider := providers.NewApiBayProvider()
-	apibayProvider.SetCache(cacheAdapter)
-	search.RegisterProvider(providers.ProviderApiBay, apibayProvider)
+	// Register providers from configuration (built-in defaults, config file and environment)
+	if err := search.RegisterProviders(appConfig.ProviderConfigs()); err != nil {
+		panic(fmt.Sprintf("failed to configure torrent providers: %v", err))
+	}
+	logger.Infof("registered torrent providers: %v", search.ProviderNames())
+
+	if err := search.SetRoutingRules(appConfig.LanguageRoutingRules()); err != nil {
+		panic(fmt.Sprintf("failed to configure language routing: %v", err))
+	}
 	
 	return search
-}
\ No newline at end of file
+}
@@ -3,11 +3,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/amaumene/gostremiofr/internal/constants"
@@ -22,7 +24,11 @@ func setupRouter() *gin.Engine {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	r := gin.Default()
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(middleware.RequestID())
+	r.Use(middleware.Tracing())
+	r.Use(middleware.Logger(logger))
 	r.Use(middleware.Gzip())
 	r.Use(middleware.CORS())
 
@@ -45,19 +51,9 @@ func startCleanupService(ctx context.Context) {
 	container.Cleanup.Start(ctx)
 }
 
-// configureCleanupRetention sets retention period from environment
+// configureCleanupRetention sets the retention period of ALLDEBRID_RETENTION_HOURS
 func configureCleanupRetention() {
-	hours := os.Getenv("ALLDEBRID_RETENTION_HOURS")
-	if hours == "" {
-		return
-	}
-
-	duration, err := time.ParseDuration(hours + "h")
-	if err != nil {
-		return
-	}
-
-	container.Cleanup.SetRetentionPeriod(duration)
+	container.Cleanup.SetRetentionPeriod(time.Duration(appConfig.RetentionHours) * time.Hour)
 }
 
 // getServerPort returns the configured server port
@@ -68,29 +64,51 @@ func getServerPort() string {
 	return constants.DefaultPort
 }
 
+// getShutdownTimeout returns how long in-flight requests get to finish on shutdown
+func getShutdownTimeout() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv("SHUTDOWN_TIMEOUT"))
+	if err != nil || timeout <= 0 {
+		return constants.ShutdownTimeout
+	}
+	return timeout
+}
+
+// newServer creates the HTTP server serving the router on port
+func newServer(r *gin.Engine, port string) *http.Server {
+	return &http.Server{
+		Addr:    ":" + port,
+		Handler: r,
+	}
+}
+
 // startHTTPSServer starts the server with TLS
-func startHTTPSServer(r *gin.Engine, port string) error {
+func startHTTPSServer(server *http.Server) error {
 	sslManager := ssl.NewLocalIPCertificate(logger)
 	if err := sslManager.Setup(); err != nil {
 		return fmt.Errorf("SSL setup failed: %w", err)
 	}
 
 	cert, key := sslManager.GetCertificatePaths()
-	return http.ListenAndServeTLS(":"+port, cert, key, r)
+	return server.ListenAndServeTLS(cert, key)
 }
 
 // startHTTPServer starts the server without TLS
-func startHTTPServer(r *gin.Engine, port string) error {
-	return http.ListenAndServe(":"+port, r)
+func startHTTPServer(server *http.Server) error {
+	return server.ListenAndServe()
 }
 
-// runServer starts the appropriate server based on configuration
-func runServer(r *gin.Engine, port string) {
-	if shouldUseSSL() {
-		runSSLServer(r, port)
-	} else {
-		log.Fatal(startHTTPServer(r, port))
-	}
+// runServer starts the appropriate server based on configuration in the background.
+// The returned channel receives the error that stopped it, http.ErrServerClosed after a shutdown.
+func runServer(server *http.Server) <-chan error {
+	errs := make(chan error, 1)
+	go func() {
+		if shouldUseSSL() {
+			errs <- runSSLServer(server)
+		} else {
+			errs <- startHTTPServer(server)
+		}
+	}()
+	return errs
 }
 
 // shouldUseSSL determines if SSL should be used
@@ -99,15 +117,64 @@ func shouldUseSSL() bool {
 }
 
 // runSSLServer attempts to start HTTPS, falls back to HTTP on failure
-func runSSLServer(r *gin.Engine, port string) {
-	if err := startHTTPSServer(r, port); err != nil {
-		log.Fatal(startHTTPServer(r, port))
+func runSSLServer(server *http.Server) error {
+	err := startHTTPSServer(server)
+	if err == nil || errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	logger.Warnf("HTTPS unavailable, falling back to HTTP: %v", err)
+	return startHTTPServer(server)
+}
+
+// shutdown stops accepting requests and waits for in-flight ones, then stops the background
+// services and closes the database. Everything shares a single deadline of timeout; when a
+// step misses it, the database is left open rather than closed under the work still running.
+func shutdown(server *http.Server, stopBackground context.CancelFunc, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Work still running after a drain step timed out may use the database
+	drained := true
+
+	logger.Infof("shutting down, waiting up to %s for in-flight requests", timeout)
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Warnf("in-flight requests did not finish in time: %v", err)
+		server.Close()
+		drained = false
+	}
+
+	if err := httpHandler.Shutdown(ctx); err != nil {
+		logger.Warnf("prefetch did not finish in time: %v", err)
+		drained = false
+	}
+
+	// Stops the cache cleanup goroutine and the cleanup loop
+	stopBackground()
+	if container != nil && container.Cleanup != nil {
+		if err := container.Cleanup.Shutdown(ctx); err != nil {
+			logger.Warnf("cleanup did not finish in time: %v", err)
+			drained = false
+		}
+	}
+
+	if !drained {
+		logger.Warnf("leaving the database open for the work still running, its lock is released on exit")
+	} else if err := db.Close(); err != nil {
+		logger.Errorf("failed to close database: %v", err)
+	} else {
+		logger.Infof("database closed")
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Warnf("failed to flush traces: %v", err)
 	}
 }
 
 func main() {
 	// Initialize application components
 	initLogger()
+	initTracing()
+	initConfig()
 	initDatabase()
 	initServices()
 
@@ -122,7 +189,24 @@ func main() {
 	// Register HTTP routes
 	httpHandler.RegisterRoutes(router)
 
-	// Start server
-	port := getServerPort()
-	runServer(router, port)
-}
\ No newline at end of file
+	// Start server until SIGINT or SIGTERM
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	server := newServer(router, getServerPort())
+	serverErrs := runServer(server)
+
+	var serverErr error
+	select {
+	case <-signals.Done():
+		logger.Infof("received shutdown signal")
+	case serverErr = <-serverErrs:
+		logger.Errorf("server stopped: %v", serverErr)
+	}
+	stopSignals()
+
+	shutdown(server, cancel, getShutdownTimeout())
+	if serverErr != nil {
+		os.Exit(1)
+	}
+}
@@ -4,8 +4,11 @@ package cache
 import (
 	"container/list"
 	"context"
+	"fmt"
 	"sync"
 	"time"
+
+	"github.com/amaumene/gostremiofr/internal/metrics"
 )
 
 const (
@@ -41,13 +44,20 @@ type LRUCache struct {
 	evictList *list.List               // Doubly linked list for LRU ordering
 	mu        sync.RWMutex             // Protects concurrent access
 	ttl       time.Duration            // Time-to-live for items
+	name      string                   // Cache label of the lookup metrics
 }
 
 // New creates a new LRU cache with the specified capacity and TTL.
 // capacity: maximum number of items to store
 // ttl: time-to-live for each item
 func New(capacity int, ttl time.Duration) *LRUCache {
+	return NewNamed("default", capacity, ttl)
+}
+
+// NewNamed creates a new LRU cache whose hits and misses are counted under the given name.
+func NewNamed(name string, capacity int, ttl time.Duration) *LRUCache {
 	return &LRUCache{
+		name:      name,
 		capacity:  capacity,
 		items:     make(map[string]*list.Element),
 		evictList: list.New(),
@@ -64,6 +74,7 @@ func (c *LRUCache) Get(key string) (interface{}, bool) {
 
 	elem, ok := c.items[key]
 	if !ok {
+		metrics.CacheLookup(c.name, false)
 		return nil, false
 	}
 
@@ -72,11 +83,13 @@ func (c *LRUCache) Get(key string) (interface{}, bool) {
 	// Check expiration
 	if time.Now().After(item.Expiration) {
 		c.removeElement(elem)
+		metrics.CacheLookup(c.name, false)
 		return nil, false
 	}
 
 	// Mark as recently used
 	c.evictList.MoveToFront(elem)
+	metrics.CacheLookup(c.name, true)
 	return item.Value, true
 }
 
@@ -150,6 +163,16 @@ func (c *LRUCache) removeElement(elem *list.Element) {
 	delete(c.items, item.Key)
 }
 
+// Ping checks that the cache can be locked, i.e. that no operation is stuck holding it.
+func (c *LRUCache) Ping() error {
+	if c == nil || c.items == nil {
+		return fmt.Errorf("cache not initialized")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return nil
+}
+
 // CleanExpired removes all expired items from the cache.
 // This is called periodically by the cleanup goroutine.
 func (c *LRUCache) CleanExpired() {
@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/amaumene/gostremiofr/internal/constants"
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch"
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/providers"
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/scoring"
 )
 
 const (
@@ -25,23 +29,66 @@ type Config struct {
 	// API Keys
 	TMDBAPIKey      string `json:"TMDB_API_KEY"`
 	APIKeyAllDebrid string `json:"API_KEY_ALLDEBRID"`
+
+	// Debrid provider selection; API_KEY_ALLDEBRID is used when DEBRID_API_KEY is empty
+	DebridProvider string `json:"DEBRID_PROVIDER"` // alldebrid, realdebrid, premiumize or torbox
+	DebridAPIKey   string `json:"DEBRID_API_KEY"`
+
+	// Further debrid accounts, in order; the pool fails over to them and shares uploads across them
+	ExtraDebridAccounts []DebridAccountConfig `json:"DEBRID_ACCOUNTS"`
 	
 	// Content filtering
 	ResToShow  []string `json:"RES_TO_SHOW"`  // Allowed resolutions
 	LangToShow []string `json:"LANG_TO_SHOW"` // Allowed languages
 
+	// Stream selection
+	MaxStreams   int             `json:"MAX_STREAMS"`   // Number of ranked streams to return
+	ScoreWeights scoring.Weights `json:"SCORE_WEIGHTS"` // Ranking weights; omitted fields keep their default
+
+	// Upload the top candidates when the availability check reports none cached, to find
+	// torrents the check misses; off by default so only cached magnets are uploaded
+	UploadUncached bool `json:"UPLOAD_UNCACHED"`
+
+	// Torrent providers; entries override the built-in providers by name
+	TorrentProviders  []torrentsearch.ProviderConfig `json:"TORRENT_PROVIDERS"`
+	DisabledProviders []string                       `json:"DISABLED_PROVIDERS"` // Provider names to turn off
+
+	// Language routing rules, evaluated before the built-in rules
+	RoutingRules []torrentsearch.RoutingRule `json:"ROUTING_RULES"`
+
+	// Next episode prefetch; PREFETCH_WORKERS bounds the background work, 0 turns it off
+	PrefetchNextEpisode bool `json:"PREFETCH_NEXT_EPISODE"`
+	PrefetchWorkers     int  `json:"PREFETCH_WORKERS"`
+
+	// Secret signing the play links of the stream list; a random one is used when empty
+	PlayTokenSecret string `json:"PLAY_TOKEN_SECRET"`
+
 	// Storage settings
 	DatabasePath string        `json:"DATABASE_PATH"`
 	CacheSize    int           `json:"CACHE_SIZE"`
 	CacheTTL     time.Duration `json:"CACHE_TTL"`
 
+	// Hours resolved streams are reused before searching again, 0 turns the stream cache off.
+	// It cannot exceed RetentionHours, after which the magnets behind the streams are deleted.
+	StreamCacheHours int `json:"STREAM_CACHE_HOURS"`
+	RetentionHours   int `json:"ALLDEBRID_RETENTION_HOURS"` // Hours uploaded magnets are kept
+
+	// Fribb/anime-lists mapping file resolving kitsu: and mal: IDs; anime IDs are refused when empty
+	AnimeMappingPath string `json:"ANIME_MAPPING_PATH"`
+
 	// Internal maps for fast lookups
 	resMap   map[string]bool
 	langMap  map[string]bool
 	mapsOnce sync.Once
 }
 
-// Load reads configuration from environment variables and optional JSON file.
+// DebridAccountConfig is a debrid account of DEBRID_ACCOUNTS.
+type DebridAccountConfig struct {
+	Provider string `json:"provider"` // alldebrid, realdebrid, premiumize or torbox
+	APIKey   string `json:"api_key"`
+}
+
+// Load reads configuration from an optional JSON file and environment variables.
 // Environment variables take precedence over file values.
 // Returns an error if the configuration is invalid.
 func Load() (*Config, error) {
@@ -49,10 +96,13 @@ func Load() (*Config, error) {
 		CacheSize:    constants.DefaultCacheSize,
 		CacheTTL:     time.Duration(constants.DefaultCacheTTL) * time.Hour,
 		DatabasePath: getEnvOrDefault("DATABASE_PATH", defaultDatabasePath),
-	}
+		ScoreWeights: scoring.DefaultWeights(),
 
-	// Load from environment variables
-	cfg.loadFromEnv()
+		PrefetchNextEpisode: true,
+		PrefetchWorkers:     constants.DefaultPrefetchWorkers,
+		StreamCacheHours:    constants.DefaultStreamCacheHours,
+		RetentionHours:      constants.DefaultRetentionHours,
+	}
 
 	// Load from config file if exists
 	configFile := getEnvOrDefault("CONFIG_FILE", defaultConfigFile)
@@ -63,6 +113,11 @@ func Load() (*Config, error) {
 		}
 	}
 
+	// Load from environment variables, overriding the file
+	if err := cfg.loadFromEnv(); err != nil {
+		return nil, fmt.Errorf("failed to load environment: %w", err)
+	}
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid config: %w", err)
@@ -75,7 +130,8 @@ func Load() (*Config, error) {
 }
 
 // loadFromEnv loads configuration from environment variables.
-func (c *Config) loadFromEnv() {
+// It fails when a variable is malformed.
+func (c *Config) loadFromEnv() error {
 	if tmdbKey := os.Getenv("TMDB_API_KEY"); tmdbKey != "" {
 		c.TMDBAPIKey = tmdbKey
 	}
@@ -83,6 +139,129 @@ func (c *Config) loadFromEnv() {
 	if adKey := os.Getenv("API_KEY_ALLDEBRID"); adKey != "" {
 		c.APIKeyAllDebrid = adKey
 	}
+
+	if provider := os.Getenv("DEBRID_PROVIDER"); provider != "" {
+		c.DebridProvider = provider
+	}
+
+	if debridKey := os.Getenv("DEBRID_API_KEY"); debridKey != "" {
+		c.DebridAPIKey = debridKey
+	}
+
+	// DEBRID_ACCOUNTS is a JSON array, e.g. [{"provider": "torbox", "api_key": "..."}]
+	if err := unmarshalEnv("DEBRID_ACCOUNTS", &c.ExtraDebridAccounts); err != nil {
+		return err
+	}
+
+	if secret := os.Getenv("PLAY_TOKEN_SECRET"); secret != "" {
+		c.PlayTokenSecret = secret
+	}
+
+	if err := parseIntEnv("MAX_STREAMS", &c.MaxStreams); err != nil {
+		return err
+	}
+
+	if err := parseBoolEnv("UPLOAD_UNCACHED", &c.UploadUncached); err != nil {
+		return err
+	}
+
+	if err := parseBoolEnv("PREFETCH_NEXT_EPISODE", &c.PrefetchNextEpisode); err != nil {
+		return err
+	}
+
+	if err := parseIntEnv("PREFETCH_WORKERS", &c.PrefetchWorkers); err != nil {
+		return err
+	}
+
+	if err := parseIntEnv("STREAM_CACHE_HOURS", &c.StreamCacheHours); err != nil {
+		return err
+	}
+
+	if err := parseIntEnv("ALLDEBRID_RETENTION_HOURS", &c.RetentionHours); err != nil {
+		return err
+	}
+
+	if mapping := os.Getenv("ANIME_MAPPING_PATH"); mapping != "" {
+		c.AnimeMappingPath = mapping
+	}
+
+	// SCORE_WEIGHTS is a JSON object, e.g. {"resolution": 40, "seeders": 5}
+	if err := unmarshalEnv("SCORE_WEIGHTS", &c.ScoreWeights); err != nil {
+		return err
+	}
+
+	return c.loadProvidersFromEnv()
+}
+
+// loadProvidersFromEnv loads torrent provider settings from environment variables.
+// TORRENT_PROVIDERS and ROUTING_RULES hold JSON arrays; TORZNAB_URL adds a Torznab indexer.
+// Providers override those of the config file with the same name, and rules are tried first.
+func (c *Config) loadProvidersFromEnv() error {
+	var configs []torrentsearch.ProviderConfig
+	if err := unmarshalEnv("TORRENT_PROVIDERS", &configs); err != nil {
+		return err
+	}
+	c.TorrentProviders = append(c.TorrentProviders, configs...)
+
+	if disabled := os.Getenv("DISABLED_PROVIDERS"); disabled != "" {
+		c.DisabledProviders = append(c.DisabledProviders, strings.Split(disabled, ",")...)
+	}
+
+	var rules []torrentsearch.RoutingRule
+	if err := unmarshalEnv("ROUTING_RULES", &rules); err != nil {
+		return err
+	}
+	c.RoutingRules = append(rules, c.RoutingRules...)
+
+	if torznabURL := os.Getenv("TORZNAB_URL"); torznabURL != "" {
+		c.TorrentProviders = append(c.TorrentProviders, torrentsearch.ProviderConfig{
+			Name:    getEnvOrDefault("TORZNAB_NAME", providers.ProviderTorznab),
+			Type:    providers.ProviderTorznab,
+			BaseURL: torznabURL,
+			APIKey:  os.Getenv("TORZNAB_API_KEY"),
+		})
+	}
+	return nil
+}
+
+// unmarshalEnv decodes the JSON value of an environment variable into v when it is set
+func unmarshalEnv(name string, v interface{}) error {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(raw), v); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
+
+// parseIntEnv sets *dst to the integer value of an environment variable when it is set
+func parseIntEnv(name string, dst *int) error {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	*dst = n
+	return nil
+}
+
+// parseBoolEnv sets *dst to the boolean value of an environment variable when it is set
+func parseBoolEnv(name string, dst *bool) error {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return nil
+	}
+	enabled, err := strconv.ParseBool(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	*dst = enabled
+	return nil
 }
 
 // loadFromFile loads configuration from a JSON file.
@@ -105,6 +284,27 @@ func (c *Config) Validate() error {
 		c.ResToShow = constants.DefaultResolutions
 	}
 
+	// Clamp the number of returned streams to a sane range
+	if c.MaxStreams < 1 {
+		c.MaxStreams = constants.DefaultMaxStreams
+	}
+	if c.MaxStreams > constants.MaxStreamsLimit {
+		c.MaxStreams = constants.MaxStreamsLimit
+	}
+
+	if c.PrefetchWorkers < 0 {
+		c.PrefetchWorkers = 0
+	}
+	if c.StreamCacheHours < 0 {
+		c.StreamCacheHours = 0
+	}
+	if c.RetentionHours <= 0 {
+		c.RetentionHours = constants.DefaultRetentionHours
+	}
+	if c.StreamCacheHours > c.RetentionHours {
+		return fmt.Errorf("STREAM_CACHE_HOURS (%d) exceeds ALLDEBRID_RETENTION_HOURS (%d), cached streams would outlive their magnets", c.StreamCacheHours, c.RetentionHours)
+	}
+
 	return nil
 }
 
@@ -115,7 +315,7 @@ func (c *Config) InitMaps() {
 		// Initialize resolution map
 		c.resMap = make(map[string]bool, len(c.ResToShow))
 		for _, res := range c.ResToShow {
-			c.resMap[strings.ToLower(res)] = true
+			c.resMap[normalizeResolution(res)] = true
 		}
 
 		// Initialize language map
@@ -127,10 +327,50 @@ func (c *Config) InitMaps() {
 }
 
 
+// IsResolutionAllowed reports whether a resolution tag (e.g. "1080p") is listed in RES_TO_SHOW.
+// An empty list allows every resolution.
+func (c *Config) IsResolutionAllowed(resolution string) bool {
+	c.InitMaps()
+	if len(c.resMap) == 0 {
+		return true
+	}
+	return c.resMap[normalizeResolution(resolution)]
+}
+
+// IsLanguageAllowed reports whether any of the language tags (e.g. "vff", "multi") is listed
+// in LANG_TO_SHOW. An empty list allows every language.
+func (c *Config) IsLanguageAllowed(languages ...string) bool {
+	c.InitMaps()
+	if len(c.langMap) == 0 {
+		return true
+	}
+	for _, lang := range languages {
+		if c.langMap[strings.ToLower(lang)] {
+			return true
+		}
+	}
+	return false
+}
+
+// HasLanguageFilter reports whether LANG_TO_SHOW restricts languages.
+func (c *Config) HasLanguageFilter() bool {
+	c.InitMaps()
+	return len(c.langMap) > 0
+}
+
+// normalizeResolution lowercases a resolution tag and treats 4K as 2160p.
+func normalizeResolution(resolution string) string {
+	resolution = strings.ToLower(strings.TrimSpace(resolution))
+	if resolution == "4k" {
+		return "2160p"
+	}
+	return resolution
+}
+
 // CreateFromUserData creates a config from user-provided data and existing config.
-// User data takes precedence over base config values.
-func CreateFromUserData(userConfig map[string]interface{}, baseConfig *Config) *Config {
-	cfg := &Config{}
+// User data takes precedence over base config values. Malformed values are rejected.
+func CreateFromUserData(userConfig map[string]interface{}, baseConfig *Config) (*Config, error) {
+	cfg := &Config{ScoreWeights: scoring.DefaultWeights(), PrefetchNextEpisode: true}
 
 	// Copy from base config if available
 	if baseConfig != nil {
@@ -138,28 +378,41 @@ func CreateFromUserData(userConfig map[string]interface{}, baseConfig *Config) *
 	}
 
 	// Apply user overrides
-	cfg.applyUserConfig(userConfig)
+	if err := cfg.applyUserConfig(userConfig); err != nil {
+		return nil, err
+	}
 
 	// Validate and initialize
 	cfg.Validate()
 	cfg.InitMaps()
 
-	return cfg
+	return cfg, nil
 }
 
 // copyFrom copies all fields from another config.
 func (c *Config) copyFrom(src *Config) {
 	c.TMDBAPIKey = src.TMDBAPIKey
 	c.APIKeyAllDebrid = src.APIKeyAllDebrid
+	c.DebridProvider = src.DebridProvider
+	c.DebridAPIKey = src.DebridAPIKey
+	c.ExtraDebridAccounts = append([]DebridAccountConfig{}, src.ExtraDebridAccounts...)
 	c.ResToShow = append([]string{}, src.ResToShow...)
 	c.LangToShow = append([]string{}, src.LangToShow...)
+	c.MaxStreams = src.MaxStreams
+	c.UploadUncached = src.UploadUncached
+	c.ScoreWeights = src.ScoreWeights
+	c.PrefetchNextEpisode = src.PrefetchNextEpisode
+	c.PrefetchWorkers = src.PrefetchWorkers
 	c.DatabasePath = src.DatabasePath
 	c.CacheSize = src.CacheSize
 	c.CacheTTL = src.CacheTTL
+	c.StreamCacheHours = src.StreamCacheHours
+	c.RetentionHours = src.RetentionHours
 }
 
 // applyUserConfig applies user-provided configuration overrides.
-func (c *Config) applyUserConfig(userConfig map[string]interface{}) {
+// It fails when a value cannot be decoded.
+func (c *Config) applyUserConfig(userConfig map[string]interface{}) error {
 	// Handle resolution list
 	if val, ok := userConfig["RES_TO_SHOW"]; ok {
 		if arr, ok := val.([]interface{}); ok {
@@ -174,6 +427,45 @@ func (c *Config) applyUserConfig(userConfig map[string]interface{}) {
 		}
 	}
 
+	// Handle number of streams; JSON numbers decode as float64
+	if val, ok := userConfig["MAX_STREAMS"]; ok {
+		n, err := userInt(val)
+		if err != nil {
+			return fmt.Errorf("invalid MAX_STREAMS: %w", err)
+		}
+		c.MaxStreams = n
+	}
+
+	// Handle ranking weights; only the given fields are overridden
+	if val, ok := userConfig["SCORE_WEIGHTS"]; ok {
+		// Decoding into a copy keeps the current weights when the value is rejected
+		weights := c.ScoreWeights
+		data, err := json.Marshal(val)
+		if err == nil {
+			err = json.Unmarshal(data, &weights)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid SCORE_WEIGHTS: %w", err)
+		}
+		c.ScoreWeights = weights
+	}
+
+	// Handle next episode prefetch; the web interface sends booleans, manual URLs may use strings
+	if val, ok := userConfig["PREFETCH_NEXT_EPISODE"]; ok {
+		var err error
+		switch v := val.(type) {
+		case bool:
+			c.PrefetchNextEpisode = v
+		case string:
+			c.PrefetchNextEpisode, err = strconv.ParseBool(strings.TrimSpace(v))
+		default:
+			err = fmt.Errorf("unexpected %T value", val)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid PREFETCH_NEXT_EPISODE: %w", err)
+		}
+	}
+
 	// Handle API keys
 	if val, ok := userConfig["TMDB_API_KEY"]; ok {
 		if str, ok := val.(string); ok {
@@ -181,11 +473,132 @@ func (c *Config) applyUserConfig(userConfig map[string]interface{}) {
 		}
 	}
 
+	// Users bringing their own debrid key do not share the server's further accounts
+	if _, ok := userConfig["DEBRID_API_KEY"]; ok {
+		c.ExtraDebridAccounts = nil
+	}
+	if _, ok := userConfig["API_KEY_ALLDEBRID"]; ok {
+		c.ExtraDebridAccounts = nil
+	}
+
 	if val, ok := userConfig["API_KEY_ALLDEBRID"]; ok {
 		if str, ok := val.(string); ok {
 			c.APIKeyAllDebrid = str
 		}
 	}
+
+	if val, ok := userConfig["DEBRID_PROVIDER"]; ok {
+		if str, ok := val.(string); ok {
+			c.DebridProvider = str
+		}
+	}
+
+	if val, ok := userConfig["DEBRID_API_KEY"]; ok {
+		if str, ok := val.(string); ok {
+			c.DebridAPIKey = str
+		}
+	}
+
+	// Handle further debrid accounts, a list of {"provider": ..., "api_key": ...}.
+	// Users bringing only their own accounts do not use the server's primary account either.
+	if val, ok := userConfig["DEBRID_ACCOUNTS"]; ok {
+		if arr, ok := val.([]interface{}); ok {
+			c.ExtraDebridAccounts = convertToDebridAccounts(arr)
+		}
+		_, ownKey := userConfig["DEBRID_API_KEY"]
+		_, ownAllDebridKey := userConfig["API_KEY_ALLDEBRID"]
+		if !ownKey && !ownAllDebridKey {
+			c.DebridProvider, c.DebridAPIKey, c.APIKeyAllDebrid = "", "", ""
+		}
+	}
+
+	return nil
+}
+
+// userInt converts a user config number, sent as a JSON number or a string, to an integer
+func userInt(val interface{}) (int, error) {
+	switch v := val.(type) {
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("%v is not an integer", v)
+		}
+		return int(v), nil
+	case string:
+		return strconv.Atoi(strings.TrimSpace(v))
+	default:
+		return 0, fmt.Errorf("unexpected %T value", val)
+	}
+}
+
+// ProviderConfigs returns the torrent providers to register: the built-in providers
+// with TORRENT_PROVIDERS applied and DISABLED_PROVIDERS turned off.
+func (c *Config) ProviderConfigs() []torrentsearch.ProviderConfig {
+	configs := torrentsearch.MergeProviderConfigs(torrentsearch.DefaultProviderConfigs(), c.TorrentProviders)
+
+	disabled := false
+	for _, name := range c.DisabledProviders {
+		if name = strings.TrimSpace(name); name != "" {
+			configs = torrentsearch.MergeProviderConfigs(configs, []torrentsearch.ProviderConfig{{Name: name, Enabled: &disabled}})
+		}
+	}
+	return configs
+}
+
+// LanguageRoutingRules returns the configured ROUTING_RULES followed by the built-in rules,
+// so custom rules take precedence and unmatched content keeps the default routing.
+func (c *Config) LanguageRoutingRules() []torrentsearch.RoutingRule {
+	return append(append([]torrentsearch.RoutingRule{}, c.RoutingRules...), torrentsearch.DefaultRoutingRules()...)
+}
+
+// DebridAccount returns the selected debrid provider identifier and its API key.
+// Configurations that only set API_KEY_ALLDEBRID select AllDebrid.
+func (c *Config) DebridAccount() (string, string) {
+	if c.DebridAPIKey != "" {
+		return c.DebridProvider, c.DebridAPIKey
+	}
+	if c.APIKeyAllDebrid != "" {
+		return constants.DebridAllDebrid, c.APIKeyAllDebrid
+	}
+	return c.DebridProvider, ""
+}
+
+// DebridAccounts returns every debrid account: the selected account first, then DEBRID_ACCOUNTS.
+// Duplicates and accounts without API key are skipped.
+func (c *Config) DebridAccounts() []DebridAccountConfig {
+	var accounts []DebridAccountConfig
+	seen := make(map[DebridAccountConfig]bool)
+	add := func(provider, apiKey string) {
+		account := DebridAccountConfig{Provider: strings.ToLower(strings.TrimSpace(provider)), APIKey: strings.TrimSpace(apiKey)}
+		if account.Provider == "" {
+			account.Provider = constants.DebridAllDebrid
+		}
+		if account.APIKey == "" || seen[account] {
+			return
+		}
+		seen[account] = true
+		accounts = append(accounts, account)
+	}
+
+	add(c.DebridAccount())
+	for _, account := range c.ExtraDebridAccounts {
+		add(account.Provider, account.APIKey)
+	}
+	return accounts
+}
+
+// convertToDebridAccounts converts decoded DEBRID_ACCOUNTS entries to account configs.
+func convertToDebridAccounts(arr []interface{}) []DebridAccountConfig {
+	result := make([]DebridAccountConfig, 0, len(arr))
+	for _, v := range arr {
+		entry, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		provider, _ := entry["provider"].(string)
+		apiKey, _ := entry["api_key"].(string)
+		result = append(result, DebridAccountConfig{Provider: provider, APIKey: apiKey})
+	}
+	return result
 }
 
 // convertToStringSlice converts interface slice to string slice.
@@ -17,12 +17,34 @@ const (
 	// Cache settings
 	DefaultCacheSize = 1000
 	DefaultCacheTTL  = 24 // hours
+	// Hours uploaded magnets are kept before the cleanup service deletes them
+	DefaultRetentionHours = 4
+	// Lifetime of resolved streams in the database, in hours; cached streams cannot
+	// outlive their magnets, so it defaults to the retention
+	DefaultStreamCacheHours = DefaultRetentionHours
+
+	// Stream selection
+	DefaultMaxStreams = 1  // single stream, first working torrent wins
+	MaxStreamsLimit   = 10 // upper bound for user-configured MAX_STREAMS
+	// RankedCandidatesPerStream is how many candidates are checked per requested stream
+	RankedCandidatesPerStream = 3
+
+	// Next episode prefetch
+	DefaultPrefetchWorkers = 2   // background workers resolving next episodes
+	PrefetchQueueSize      = 32  // pending prefetches; further requests are dropped
+	PrefetchCacheSize      = 500 // prefetched stream lists kept in memory
 
 	// Rate limiting
-	TMDBRateLimit      = 20 // requests per second
-	TMDBRateBurst      = 5  // burst capacity
-	AllDebridRateLimit = 10 // requests per second
-	AllDebridRateBurst = 2  // burst capacity
+	TMDBRateLimit       = 20 // requests per second
+	TMDBRateBurst       = 5  // burst capacity
+	AllDebridRateLimit  = 10 // requests per second
+	AllDebridRateBurst  = 2  // burst capacity
+	RealDebridRateLimit = 4  // requests per second
+	RealDebridRateBurst = 2  // burst capacity
+	PremiumizeRateLimit = 5  // requests per second
+	PremiumizeRateBurst = 2  // burst capacity
+	TorBoxRateLimit     = 5  // requests per second
+	TorBoxRateBurst     = 2  // burst capacity
 )
 
 // TMDBMovieGenres contains TMDB genre IDs for movies.
@@ -5,4 +5,12 @@ const (
 	ProviderYGG         = "ygg"
 	ProviderApiBay      = "apibay"
 	ProviderTorrentsCSV = "torrentscsv"
-)
\ No newline at end of file
+)
+
+// Debrid provider identifiers used in the user configuration (DEBRID_PROVIDER)
+const (
+	DebridAllDebrid  = "alldebrid"
+	DebridRealDebrid = "realdebrid"
+	DebridPremiumize = "premiumize"
+	DebridTorBox     = "torbox"
+)
@@ -16,6 +16,25 @@ const (
 	MagnetCheckRetryDelay = 2 * time.Second
 	MagnetReadyRetryDelay = 3 * time.Second
 
+	// Lifetime of prefetched next episode streams
+	PrefetchCacheTTL = 6 * time.Hour
+
+	// Time a debrid account that failed with an auth or quota error is tried last
+	DebridAccountCooldown = 15 * time.Minute
+
+	// Time each /ready check may take before the service is reported unready
+	ReadinessCheckTimeout = 2 * time.Second
+
+	// Time in-flight requests and background jobs get to finish on shutdown,
+	// short enough to fit the 30 seconds Kubernetes grants by default
+	ShutdownTimeout = 25 * time.Second
+
 	// Maximum retry attempts
 	MaxMagnetCheckAttempts = 2
+
+	// Maximum number of candidates whose hashes are resolved for the bulk availability check
+	MaxInstantCheckCandidates = 20
+
+	// Maximum number of torrent hash lookups running at once
+	MaxConcurrentHashLookups = 5
 )
@@ -2,12 +2,16 @@
 package database
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/amaumene/gostremiofr/bolthold"
+	bolt "go.etcd.io/bbolt"
 )
 
 const (
@@ -19,6 +23,12 @@ const (
 	defaultDBFile = "data.db"
 )
 
+var (
+	// healthBucket holds the key written by Ping
+	healthBucket = []byte("_health")
+	healthKey    = []byte("ping")
+)
+
 // TMDBCache represents cached TMDB metadata for movies and TV shows.
 type TMDBCache struct {
 	IMDBId           string
@@ -31,12 +41,13 @@ type TMDBCache struct {
 
 // Magnet represents a magnet link with associated metadata.
 type Magnet struct {
-	ID           string    // Unique identifier
-	Hash         string    // Info hash
-	Name         string    // Torrent name
-	AddedAt      time.Time // When it was added
-	AllDebridID  string    // AllDebrid magnet ID for cleanup
-	AllDebridKey string    // API key used (for cleanup)
+	ID        string    // Unique identifier
+	Hash      string    // Info hash
+	Name      string    // Torrent name
+	AddedAt   time.Time // When it was added
+	Provider  string    // Debrid provider identifier, empty for legacy AllDebrid entries
+	DebridID  string    // Debrid magnet ID for cleanup
+	DebridKey string    // API key used (for cleanup)
 }
 
 // Database defines the interface for data persistence operations.
@@ -45,7 +56,7 @@ type Database interface {
 	GetCachedTMDB(imdbId string) (*TMDBCache, error)
 	// StoreTMDBCache stores TMDB metadata
 	StoreTMDBCache(cache *TMDBCache) error
-	// StoreMagnet stores a magnet link
+	// StoreMagnet stores a magnet link, replacing the entry of the same torrent and account
 	StoreMagnet(magnet *Magnet) error
 	// GetMagnets retrieves all stored magnets
 	GetMagnets() ([]Magnet, error)
@@ -53,6 +64,18 @@ type Database interface {
 	GetOldMagnets(olderThan time.Duration) ([]Magnet, error)
 	// DeleteMagnet removes a magnet by ID
 	DeleteMagnet(id string) error
+	// GetStreamCache retrieves an unexpired stream cache entry by key
+	GetStreamCache(key string) (*StreamCache, error)
+	// StoreStreamCache stores the streams chosen for a request
+	StoreStreamCache(entry *StreamCache) error
+	// DeleteStreamCache removes a stream cache entry by key
+	DeleteStreamCache(key string) error
+	// InvalidateStreamCache removes the entries with a stream on a torrent hash of an account
+	InvalidateStreamCache(account, hash string) error
+	// DeleteExpiredStreamCache removes expired stream cache entries
+	DeleteExpiredStreamCache() error
+	// Ping checks that the database is open and writable
+	Ping() error
 	// Close closes the database connection
 	Close() error
 }
@@ -75,11 +98,15 @@ type BoltTMDBCache struct {
 // BoltMagnet is the BoltDB-specific structure for magnet storage.
 type BoltMagnet struct {
 	ID           string `boltholdKey:"ID"`
-	Hash         string `boltholdUnique:"Hash"`
+	Hash         string
+	AccountHash  string `boltholdUnique:"AccountHash"` // one entry per torrent and debrid account
 	Name         string
 	AddedAt      time.Time
-	AllDebridID  string // AllDebrid magnet ID for cleanup
+	// AllDebridID and AllDebridKey hold the debrid magnet ID and API key for every provider;
+	// the names are kept so existing databases still decode.
+	AllDebridID  string // Debrid magnet ID for cleanup
 	AllDebridKey string // API key used (for cleanup)
+	Provider     string // Debrid provider identifier
 }
 
 // NewBolt creates a new BoltDB database instance.
@@ -109,6 +136,21 @@ func (db *BoltDB) Close() error {
 	return db.store.Close()
 }
 
+// Ping checks that the database is open and writable by storing the current time.
+func (db *BoltDB) Ping() error {
+	err := db.store.Bolt().Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(healthBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(healthKey, []byte(time.Now().UTC().Format(time.RFC3339)))
+	})
+	if err != nil {
+		return fmt.Errorf("database not writable: %w", err)
+	}
+	return nil
+}
+
 // GetCachedTMDB retrieves cached TMDB data by IMDB ID.
 // Returns nil if not found, without error.
 func (db *BoltDB) GetCachedTMDB(imdbId string) (*TMDBCache, error) {
@@ -158,18 +200,31 @@ func (db *BoltDB) StoreTMDBCache(cache *TMDBCache) error {
 }
 
 // StoreMagnet stores a magnet link in the database.
-// Updates existing entries or creates new ones.
+// A torrent uploaded again to the same account updates its entry, keeping its ID, with the
+// new debrid magnet ID and upload time so that the cleanup keeps it for the full retention.
 func (db *BoltDB) StoreMagnet(magnet *Magnet) error {
 	boltMagnet := &BoltMagnet{
 		ID:           magnet.ID,
 		Hash:         magnet.Hash,
+		AccountHash:  magnetAccountHash(magnet),
 		Name:         magnet.Name,
 		AddedAt:      time.Now(),
-		AllDebridID:  magnet.AllDebridID,
-		AllDebridKey: magnet.AllDebridKey,
+		AllDebridID:  magnet.DebridID,
+		AllDebridKey: magnet.DebridKey,
+		Provider:     magnet.Provider,
 	}
 
-	err := db.store.Upsert(magnet.ID, boltMagnet)
+	err := db.store.Bolt().Update(func(tx *bolt.Tx) error {
+		var existing []BoltMagnet
+		query := bolthold.Where("AccountHash").Eq(boltMagnet.AccountHash).Index("AccountHash")
+		if err := db.store.TxFind(tx, &existing, query); err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			boltMagnet.ID = existing[0].ID
+		}
+		return db.store.TxUpsert(tx, boltMagnet.ID, boltMagnet)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to store magnet: %w", err)
 	}
@@ -177,6 +232,18 @@ func (db *BoltDB) StoreMagnet(magnet *Magnet) error {
 	return nil
 }
 
+// magnetAccountHash identifies a torrent on a debrid account, so that the same torrent
+// is tracked once per provider and API key.
+func magnetAccountHash(magnet *Magnet) string {
+	return AccountFingerprint(magnet.Provider, magnet.DebridKey) + ":" + strings.ToLower(magnet.Hash)
+}
+
+// AccountFingerprint identifies a debrid account without storing its API key.
+func AccountFingerprint(provider, apiKey string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(provider) + ":" + apiKey))
+	return hex.EncodeToString(sum[:8])
+}
+
 // GetMagnets retrieves all stored magnets from the database.
 func (db *BoltDB) GetMagnets() ([]Magnet, error) {
 	var boltMagnets []BoltMagnet
@@ -200,12 +267,13 @@ func convertToMagnets(boltMagnets []BoltMagnet) []Magnet {
 // convertToMagnet converts BoltMagnet to Magnet.
 func convertToMagnet(bolt *BoltMagnet) Magnet {
 	return Magnet{
-		ID:           bolt.ID,
-		Hash:         bolt.Hash,
-		Name:         bolt.Name,
-		AddedAt:      bolt.AddedAt,
-		AllDebridID:  bolt.AllDebridID,
-		AllDebridKey: bolt.AllDebridKey,
+		ID:        bolt.ID,
+		Hash:      bolt.Hash,
+		Name:      bolt.Name,
+		AddedAt:   bolt.AddedAt,
+		Provider:  bolt.Provider,
+		DebridID:  bolt.AllDebridID,
+		DebridKey: bolt.AllDebridKey,
 	}
 }
 
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestDB(t *testing.T) *BoltDB {
+	t.Helper()
+	db, err := NewBolt(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewBolt: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestStoreMagnetUpdatesSameTorrentAndAccount(t *testing.T) {
+	db := newTestDB(t)
+
+	uploads := []Magnet{
+		{ID: "first", Hash: "ABC", Provider: "realdebrid", DebridID: "rd1", DebridKey: "key"},
+		{ID: "second", Hash: "abc", Provider: "realdebrid", DebridID: "rd2", DebridKey: "key"},
+		{ID: "other-account", Hash: "abc", Provider: "realdebrid", DebridID: "rd3", DebridKey: "key2"},
+		{ID: "other-provider", Hash: "abc", Provider: "torbox", DebridID: "tb1", DebridKey: "key"},
+	}
+	for i := range uploads {
+		if err := db.StoreMagnet(&uploads[i]); err != nil {
+			t.Fatalf("StoreMagnet(%s): %v", uploads[i].ID, err)
+		}
+	}
+
+	magnets, err := db.GetMagnets()
+	if err != nil {
+		t.Fatalf("GetMagnets: %v", err)
+	}
+	debridIDs := make(map[string]string)
+	for _, magnet := range magnets {
+		debridIDs[magnet.ID] = magnet.DebridID
+	}
+	want := map[string]string{"first": "rd2", "other-account": "rd3", "other-provider": "tb1"}
+	if len(debridIDs) != len(want) {
+		t.Fatalf("stored %v, want %v", debridIDs, want)
+	}
+	for id, debridID := range want {
+		if debridIDs[id] != debridID {
+			t.Errorf("magnet %s has debrid ID %q, want %q", id, debridIDs[id], debridID)
+		}
+	}
+}
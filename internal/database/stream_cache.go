@@ -0,0 +1,149 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amaumene/gostremiofr/bolthold"
+)
+
+// StreamCache records the debrid files chosen for a stream request, so that the
+// request can be answered again without searching.
+type StreamCache struct {
+	Key       string         // Content ID, season, episode and user settings
+	Account   string         // Fingerprint of the requesting debrid account or pool, see AccountFingerprint
+	Streams   []CachedStream // Chosen streams in rank order
+	ExpiresAt time.Time
+
+	// Content title and original language, kept to prefetch the next episode on cache hits
+	Title            string
+	OriginalLanguage string
+}
+
+// CachedStream is a stream of a StreamCache entry with the debrid file behind it.
+type CachedStream struct {
+	Account   string // Fingerprint of the debrid account holding the magnet
+	Hash      string // Torrent info hash
+	MagnetID  string // Debrid magnet ID
+	FileIndex int    // Position of the file in the magnet links
+	FileName  string
+	Link      string // Debrid file link, unlocked when the stream is opened
+	Season    int
+	Episode   int
+	Absolute  int // Episode number anime releases use, 0 for other content
+
+	// Stream display fields
+	Name        string
+	Title       string
+	BingeGroup  string
+	VideoSize   int64
+	NotWebReady bool
+}
+
+// BoltStreamCache is the BoltDB-specific structure for stream cache storage.
+type BoltStreamCache struct {
+	Key       string   `boltholdKey:"Key"`
+	Account   string   `boltholdIndex:"Account"`
+	Magnets   []string // "account:hash" keys of the stream magnets, for invalidation
+	Streams   []CachedStream
+	ExpiresAt time.Time
+
+	Title            string
+	OriginalLanguage string
+}
+
+// GetStreamCache retrieves a stream cache entry by key.
+// Returns nil if not found or expired, without error.
+func (db *BoltDB) GetStreamCache(key string) (*StreamCache, error) {
+	var entry BoltStreamCache
+	err := db.store.Get(key, &entry)
+	if err == bolthold.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream cache: %w", err)
+	}
+
+	if time.Now().After(entry.ExpiresAt) {
+		return nil, db.DeleteStreamCache(key)
+	}
+
+	return &StreamCache{
+		Key:              entry.Key,
+		Account:          entry.Account,
+		Streams:          entry.Streams,
+		ExpiresAt:        entry.ExpiresAt,
+		Title:            entry.Title,
+		OriginalLanguage: entry.OriginalLanguage,
+	}, nil
+}
+
+// StoreStreamCache stores a stream cache entry in the database.
+// Updates existing entries or creates new ones.
+func (db *BoltDB) StoreStreamCache(entry *StreamCache) error {
+	magnets := make([]string, 0, len(entry.Streams))
+	for _, stream := range entry.Streams {
+		account := stream.Account
+		if account == "" {
+			account = entry.Account
+		}
+		magnets = append(magnets, streamMagnetKey(account, stream.Hash))
+	}
+
+	boltEntry := &BoltStreamCache{
+		Key:              entry.Key,
+		Account:          entry.Account,
+		Magnets:          magnets,
+		Streams:          entry.Streams,
+		ExpiresAt:        entry.ExpiresAt,
+		Title:            entry.Title,
+		OriginalLanguage: entry.OriginalLanguage,
+	}
+
+	if err := db.store.Upsert(entry.Key, boltEntry); err != nil {
+		return fmt.Errorf("failed to store stream cache: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteStreamCache removes a stream cache entry by key.
+// Returns nil if the entry doesn't exist.
+func (db *BoltDB) DeleteStreamCache(key string) error {
+	err := db.store.Delete(key, BoltStreamCache{})
+	if err == bolthold.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete stream cache: %w", err)
+	}
+
+	return nil
+}
+
+// InvalidateStreamCache removes the entries with a stream on the given torrent of a debrid
+// account, typically once its magnet has been deleted from the account. Entries of pools
+// are matched through the account holding the magnet.
+func (db *BoltDB) InvalidateStreamCache(account, hash string) error {
+	query := bolthold.Where("Magnets").Contains(streamMagnetKey(account, hash))
+	if err := db.store.DeleteMatching(BoltStreamCache{}, query); err != nil {
+		return fmt.Errorf("failed to invalidate stream cache: %w", err)
+	}
+
+	return nil
+}
+
+// streamMagnetKey identifies a torrent of a debrid account in stream cache entries
+func streamMagnetKey(account, hash string) string {
+	return account + ":" + strings.ToLower(hash)
+}
+
+// DeleteExpiredStreamCache removes the stream cache entries past their expiration.
+func (db *BoltDB) DeleteExpiredStreamCache() error {
+	if err := db.store.DeleteMatching(BoltStreamCache{}, bolthold.Where("ExpiresAt").Lt(time.Now())); err != nil {
+		return fmt.Errorf("failed to delete expired stream cache: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInvalidateStreamCache(t *testing.T) {
+	db := newTestDB(t)
+	expires := time.Now().Add(time.Hour)
+
+	entries := []StreamCache{
+		{Key: "single", Account: "acc1", ExpiresAt: expires, Streams: []CachedStream{{Hash: "AAA"}}},
+		{Key: "pool", Account: "acc1,acc2", ExpiresAt: expires, Streams: []CachedStream{{Account: "acc2", Hash: "aaa"}, {Account: "acc1", Hash: "bbb"}}},
+		{Key: "other-account", Account: "acc3", ExpiresAt: expires, Streams: []CachedStream{{Hash: "aaa"}}},
+		{Key: "other-torrent", Account: "acc1", ExpiresAt: expires, Streams: []CachedStream{{Hash: "ccc"}}},
+	}
+	for i := range entries {
+		if err := db.StoreStreamCache(&entries[i]); err != nil {
+			t.Fatalf("StoreStreamCache(%s): %v", entries[i].Key, err)
+		}
+	}
+
+	tests := []struct {
+		account, hash string
+		deleted       []string
+	}{
+		{"acc1", "aaa", []string{"single"}},
+		{"acc2", "AAA", []string{"pool"}},
+		{"acc1", "ddd", nil},
+	}
+
+	deleted := make(map[string]bool)
+	for _, tt := range tests {
+		if err := db.InvalidateStreamCache(tt.account, tt.hash); err != nil {
+			t.Fatalf("InvalidateStreamCache(%s, %s): %v", tt.account, tt.hash, err)
+		}
+		for _, key := range tt.deleted {
+			deleted[key] = true
+		}
+		for _, entry := range entries {
+			got, err := db.GetStreamCache(entry.Key)
+			if err != nil {
+				t.Fatalf("GetStreamCache(%s): %v", entry.Key, err)
+			}
+			if (got == nil) != deleted[entry.Key] {
+				t.Errorf("after invalidating %s:%s, entry %s found = %v", tt.account, tt.hash, entry.Key, got != nil)
+			}
+		}
+	}
+}
+
+func TestGetStreamCacheExpired(t *testing.T) {
+	db := newTestDB(t)
+
+	entry := &StreamCache{Key: "expired", Account: "acc1", ExpiresAt: time.Now().Add(-time.Minute), Title: "Show"}
+	if err := db.StoreStreamCache(entry); err != nil {
+		t.Fatalf("StoreStreamCache: %v", err)
+	}
+	if got, err := db.GetStreamCache("expired"); err != nil || got != nil {
+		t.Errorf("GetStreamCache = %+v, %v, want nil without error", got, err)
+	}
+
+	entry = &StreamCache{Key: "fresh", Account: "acc1", ExpiresAt: time.Now().Add(time.Hour), Title: "Show", OriginalLanguage: "ja"}
+	if err := db.StoreStreamCache(entry); err != nil {
+		t.Fatalf("StoreStreamCache: %v", err)
+	}
+	got, err := db.GetStreamCache("fresh")
+	if err != nil || got == nil {
+		t.Fatalf("GetStreamCache = %+v, %v, want the entry", got, err)
+	}
+	if got.Title != "Show" || got.OriginalLanguage != "ja" {
+		t.Errorf("GetStreamCache = %+v, want the title and original language", got)
+	}
+}
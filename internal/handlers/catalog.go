@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -16,12 +17,9 @@ func (h *Handler) extractTMDBAPIKey(configuration string) string {
 	return h.extractTMDBKey(userConfig)
 }
 
-func (h *Handler) updateTMDBService(tmdbAPIKey string) {
-	if tmdbAPIKey != "" && h.services.TMDB != nil {
-		if tmdb, ok := h.services.TMDB.(*services.TMDB); ok {
-			tmdb.SetAPIKey(tmdbAPIKey)
-		}
-	}
+// tmdbForConfiguration returns the TMDB service scoped to the user's API key
+func (h *Handler) tmdbForConfiguration(configuration string) services.TMDBService {
+	return h.services.TMDB.WithAPIKey(h.extractTMDBAPIKey(configuration))
 }
 
 func (h *Handler) handleCatalog(c *gin.Context) {
@@ -34,43 +32,43 @@ func (h *Handler) handleCatalog(c *gin.Context) {
 	skipInt, _ := strconv.Atoi(c.DefaultQuery("skip", "0"))
 	page := (skipInt / 20) + 1
 
-	tmdbAPIKey := h.extractTMDBAPIKey(configuration)
-	h.updateTMDBService(tmdbAPIKey)
+	tmdb := h.tmdbForConfiguration(configuration)
 
-	h.services.Logger.Debugf("catalog request: %s/%s (page %d)", catalogType, catalogID, page)
+	h.log(c.Request.Context()).Debugf("catalog request: %s/%s (page %d)", catalogType, catalogID, page)
 
-	metas, err := h.fetchCatalogMetas(catalogType, catalogID, search, genre, page)
+	ctx := c.Request.Context()
+	metas, err := h.fetchCatalogMetas(ctx, tmdb, catalogType, catalogID, search, genre, page)
 	if err != nil {
-		h.services.Logger.Errorf("catalog fetch failed: %v", err)
+		h.log(ctx).Errorf("catalog fetch failed: %v", err)
 		c.JSON(http.StatusOK, models.CatalogResponse{Metas: []models.Meta{}})
 		return
 	}
 
 	metas = h.filterMetasByType(metas, catalogID, catalogType)
-	h.services.Logger.Debugf("returning %d items for %s/%s", len(metas), catalogType, catalogID)
+	h.log(ctx).Debugf("returning %d items for %s/%s", len(metas), catalogType, catalogID)
 	c.JSON(http.StatusOK, models.CatalogResponse{Metas: metas})
 }
 
-func (h *Handler) fetchCatalogMetas(catalogType, catalogID, search, genre string, page int) ([]models.Meta, error) {
+func (h *Handler) fetchCatalogMetas(ctx context.Context, tmdb services.TMDBService, catalogType, catalogID, search, genre string, page int) ([]models.Meta, error) {
 	if catalogID == "search" && search != "" {
-		return h.services.TMDB.SearchMulti(search, page)
+		return tmdb.SearchMulti(ctx, search, page)
 	}
 
 	switch catalogID {
 	case "popular":
 		if catalogType == "movie" {
-			return h.services.TMDB.GetPopularMovies(page, genre)
+			return tmdb.GetPopularMovies(ctx, page, genre)
 		}
-		return h.services.TMDB.GetPopularSeries(page, genre)
+		return tmdb.GetPopularSeries(ctx, page, genre)
 
 	case "trending":
-		return h.services.TMDB.GetTrending(catalogType, "week", page)
+		return tmdb.GetTrending(ctx, catalogType, "week", page)
 
 	case "top_rated":
 		if catalogType == "movie" {
-			return h.services.TMDB.GetPopularMovies(page, genre)
+			return tmdb.GetPopularMovies(ctx, page, genre)
 		}
-		return h.services.TMDB.GetPopularSeries(page, genre)
+		return tmdb.GetPopularSeries(ctx, page, genre)
 
 	default:
 		return []models.Meta{}, nil
@@ -91,12 +89,12 @@ func (h *Handler) filterMetasByType(metas []models.Meta, catalogID, catalogType
 	return filtered
 }
 
-func (h *Handler) fetchTMDBMeta(metaType, tmdbID string) (*models.Meta, error) {
-	return h.services.TMDB.GetMetadata(metaType, tmdbID)
+func (h *Handler) fetchTMDBMeta(ctx context.Context, tmdb services.TMDBService, metaType, tmdbID string) (*models.Meta, error) {
+	return tmdb.GetMetadata(ctx, metaType, tmdbID)
 }
 
-func (h *Handler) fetchIMDBMeta(metaID string) (*models.Meta, error) {
-	mediaType, title, _, _, _, err := h.services.TMDB.GetIMDBInfo(metaID)
+func (h *Handler) fetchIMDBMeta(ctx context.Context, tmdb services.TMDBService, metaID string) (*models.Meta, error) {
+	mediaType, title, _, _, _, err := tmdb.GetIMDBInfo(ctx, metaID)
 	if err != nil {
 		return nil, err
 	}
@@ -113,12 +111,12 @@ func (h *Handler) handleMeta(c *gin.Context) {
 	metaType := c.Param("type")
 	metaID := c.Param("id")
 
-	tmdbAPIKey := h.extractTMDBAPIKey(configuration)
-	h.updateTMDBService(tmdbAPIKey)
+	tmdb := h.tmdbForConfiguration(configuration)
 
-	h.services.Logger.Debugf("fetching metadata: %s/%s", metaType, metaID)
+	h.log(c.Request.Context()).Debugf("fetching metadata: %s/%s", metaType, metaID)
 
-	meta, err := h.fetchMeta(metaType, metaID)
+	ctx := c.Request.Context()
+	meta, err := h.fetchMeta(ctx, tmdb, metaType, metaID)
 	if err != nil {
 		h.handleMetaError(c, err)
 		return
@@ -127,12 +125,12 @@ func (h *Handler) handleMeta(c *gin.Context) {
 	c.JSON(http.StatusOK, models.MetaResponse{Meta: *meta})
 }
 
-func (h *Handler) fetchMeta(metaType, metaID string) (*models.Meta, error) {
+func (h *Handler) fetchMeta(ctx context.Context, tmdb services.TMDBService, metaType, metaID string) (*models.Meta, error) {
 	if strings.HasPrefix(metaID, "tmdb:") {
 		tmdbID := strings.TrimPrefix(metaID, "tmdb:")
-		return h.fetchTMDBMeta(metaType, tmdbID)
+		return h.fetchTMDBMeta(ctx, tmdb, metaType, tmdbID)
 	} else if strings.HasPrefix(metaID, "tt") {
-		return h.fetchIMDBMeta(metaID)
+		return h.fetchIMDBMeta(ctx, tmdb, metaID)
 	}
 	return nil, fmt.Errorf("Invalid meta ID format")
 }
@@ -141,7 +139,7 @@ func (h *Handler) handleMetaError(c *gin.Context, err error) {
 	if err.Error() == "Invalid meta ID format" {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 	} else {
-		h.services.Logger.Errorf("metadata fetch failed: %v", err)
+		h.log(c.Request.Context()).Errorf("metadata fetch failed: %v", err)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Meta not found"})
 	}
 }
@@ -48,7 +48,7 @@ const configPageHTML = `<!DOCTYPE html>
       color: var(--primary-color);
     }
     label { font-weight: 500; margin-top: 15px; display: block; }
-    input {
+    input, select, textarea {
       width: 100%;
       padding: 10px;
       border: 1px solid var(--input-border);
@@ -56,7 +56,7 @@ const configPageHTML = `<!DOCTYPE html>
       margin-top: 5px;
       font-size: 1rem;
     }
-    input:focus {
+    input:focus, select:focus, textarea:focus {
       outline: none;
       border-color: var(--input-focus);
       box-shadow: 0 0 5px rgba(74, 144, 226, 0.5);
@@ -99,7 +99,13 @@ const configPageHTML = `<!DOCTYPE html>
 
           document.getElementById('tmdb').value = decodedConfig.TMDB_API_KEY || "";
           document.getElementById('res').value = (decodedConfig.RES_TO_SHOW || []).join(",");
-          document.getElementById('alldebrid').value = decodedConfig.API_KEY_ALLDEBRID || "";
+          document.getElementById('lang').value = (decodedConfig.LANG_TO_SHOW || []).join(",");
+          document.getElementById('debrid').value = decodedConfig.DEBRID_PROVIDER || "alldebrid";
+          document.getElementById('debridkey').value = decodedConfig.DEBRID_API_KEY || decodedConfig.API_KEY_ALLDEBRID || "";
+          document.getElementById('backupaccounts').value = (decodedConfig.DEBRID_ACCOUNTS || [])
+            .map(a => a.provider + ":" + a.api_key).join("\n");
+          document.getElementById('maxstreams').value = decodedConfig.MAX_STREAMS || 1;
+          document.getElementById('prefetch').value = decodedConfig.PREFETCH_NEXT_EPISODE === false ? "false" : "true";
           
         } catch (error) {
           console.error("Error decoding configuration:", error);
@@ -111,7 +117,13 @@ const configPageHTML = `<!DOCTYPE html>
       const config = {
         TMDB_API_KEY: document.getElementById('tmdb').value,
         RES_TO_SHOW: document.getElementById('res').value.split(',').map(s => s.trim()).filter(s => s),
-        API_KEY_ALLDEBRID: document.getElementById('alldebrid').value
+        LANG_TO_SHOW: document.getElementById('lang').value.split(',').map(s => s.trim().toLowerCase()).filter(s => s),
+        DEBRID_PROVIDER: document.getElementById('debrid').value,
+        DEBRID_API_KEY: document.getElementById('debridkey').value,
+        DEBRID_ACCOUNTS: document.getElementById('backupaccounts').value.split('\n').map(s => s.trim()).filter(s => s.includes(':'))
+          .map(s => ({ provider: s.slice(0, s.indexOf(':')).trim().toLowerCase(), api_key: s.slice(s.indexOf(':') + 1).trim() })),
+        MAX_STREAMS: parseInt(document.getElementById('maxstreams').value, 10) || 1,
+        PREFETCH_NEXT_EPISODE: document.getElementById('prefetch').value === "true"
       };
       const encodedConfig = btoa(JSON.stringify(config));
       
@@ -155,10 +167,32 @@ const configPageHTML = `<!DOCTYPE html>
     <label for="res">Résolutions (séparées par une virgule)</label>
     <input type="text" id="res" value="2160p,1080p,720p,480p" placeholder="Ex: 2160p,1080p,720p">
     
+    <label for="lang">Langues (séparées par une virgule, vide = toutes)</label>
+    <input type="text" id="lang" placeholder="Ex: multi,vff,vfq,french,vostfr">
     
-    <label for="alldebrid">Clé API AllDebrid</label>
-    <input type="text" id="alldebrid" placeholder="Entrez votre clé API AllDebrid">
     
+    <label for="debrid">Service debrid</label>
+    <select id="debrid">
+      <option value="alldebrid">AllDebrid</option>
+      <option value="realdebrid">Real-Debrid</option>
+      <option value="premiumize">Premiumize</option>
+      <option value="torbox">TorBox</option>
+    </select>
+    
+    <label for="debridkey">Clé API debrid</label>
+    <input type="text" id="debridkey" placeholder="Entrez votre clé API du service debrid">
+    
+    <label for="backupaccounts">Comptes debrid supplémentaires (un par ligne, service:clé)</label>
+    <textarea id="backupaccounts" rows="3" placeholder="Ex: torbox:votre-clé"></textarea>
+    
+    <label for="maxstreams">Nombre de streams (1 = premier stream disponible)</label>
+    <input type="number" id="maxstreams" value="1" min="1" max="10">
+    
+    <label for="prefetch">Préparer l'épisode suivant en arrière-plan</label>
+    <select id="prefetch">
+      <option value="true">Oui</option>
+      <option value="false">Non</option>
+    </select>
     
     <button onclick="generateConfig()">Générer la configuration</button>
     <div id="result" class="result"></div>
@@ -2,25 +2,63 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"strings"
 
 	"github.com/amaumene/gostremiofr/internal/config"
+	"github.com/amaumene/gostremiofr/internal/metrics"
 	"github.com/amaumene/gostremiofr/internal/services"
+	"github.com/amaumene/gostremiofr/pkg/logger"
+	"github.com/amaumene/gostremiofr/pkg/security"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
 )
 
+// tracer creates the spans of the stream resolution pipeline
+var tracer = otel.Tracer("github.com/amaumene/gostremiofr/internal/handlers")
+
 // Handler handles HTTP requests for the Stremio addon.
 type Handler struct {
 	services *services.Container
 	config   *config.Config
+	signer   *security.TokenSigner // Signs the play tokens of stream URLs
+	prefetch *prefetcher           // Resolves next episodes in the background, nil when disabled
 }
 
 // New creates a new Handler with the provided services and configuration.
+// Without PLAY_TOKEN_SECRET, play links are signed with a random key and expire on restart.
 func New(services *services.Container, config *config.Config) *Handler {
-	return &Handler{
+	h := &Handler{
 		services: services,
 		config:   config,
 	}
+
+	if config != nil && config.PlayTokenSecret != "" {
+		h.signer = security.NewTokenSigner([]byte(config.PlayTokenSecret))
+	} else if signer, err := security.NewRandomTokenSigner(); err == nil {
+		services.Logger.Warnf("PLAY_TOKEN_SECRET not set, stream links will stop working after a restart")
+		h.signer = signer
+	} else {
+		panic(fmt.Sprintf("failed to create play token signer: %v", err))
+	}
+
+	if config != nil {
+		h.prefetch = newPrefetcher(config.PrefetchWorkers, h.runPrefetch)
+	}
+
+	return h
+}
+
+// Shutdown stops scheduling prefetches and waits for the running ones until ctx is done.
+// It must be called once the HTTP server no longer serves requests.
+func (h *Handler) Shutdown(ctx context.Context) error {
+	return h.prefetch.close(ctx)
+}
+
+// log returns the logger of the request served with ctx, tagging messages with its request ID.
+func (h *Handler) log(ctx context.Context) logger.Logger {
+	return logger.FromContext(ctx, h.services.Logger)
 }
 
 // RegisterRoutes registers all HTTP routes for the Stremio addon.
@@ -28,6 +66,14 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 	// Home route
 	r.GET("/", h.handleHome)
 
+	// Liveness, readiness and upstream status probes
+	r.GET("/health", h.handleHealth)
+	r.GET("/ready", h.handleReady)
+	r.GET("/status", h.handleStatus)
+
+	// Prometheus metrics
+	r.GET("/metrics", gin.WrapH(metrics.Registry.Handler()))
+
 	// Configuration routes
 	r.GET("/config", h.handleConfig)
 	r.GET("/configure", h.handleConfig) // Alias for compatibility
@@ -46,6 +92,9 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 
 	// Stream routes - handle both with and without .json in the handler
 	r.GET("/:configuration/stream/:type/:id", h.handleStreamWrapper)
+
+	// Playback route - stream URLs point here and the debrid link is unlocked on click
+	r.GET("/:configuration/play/:token", h.handlePlay)
 }
 
 // HandleStream is an exported wrapper for the internal handleStream method.
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/amaumene/gostremiofr/internal/constants"
+	"github.com/amaumene/gostremiofr/internal/health"
+	"github.com/amaumene/gostremiofr/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// statusResponse is the body of /status
+type statusResponse struct {
+	Status    string                                      `json:"status"`
+	StartedAt time.Time                                   `json:"started_at"`
+	Uptime    string                                      `json:"uptime"`
+	Upstreams map[string]map[string]health.UpstreamStatus `json:"upstreams"`
+	Cleanup   *services.CleanupStatus                     `json:"cleanup,omitempty"`
+}
+
+// handleHealth reports that the process is alive.
+func (h *Handler) handleHealth(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// handleReady reports whether the database is open and writable and the cache responds.
+// It answers 503 when a check fails so that orchestrators stop routing requests.
+func (h *Handler) handleReady(c *gin.Context) {
+	checks := map[string]func() error{
+		"database": func() error {
+			if h.services.DB == nil {
+				return fmt.Errorf("database not configured")
+			}
+			return h.services.DB.Ping()
+		},
+		"cache": func() error {
+			return h.services.Cache.Ping()
+		},
+	}
+
+	ready := true
+	results := make(map[string]string, len(checks))
+	for name, check := range checks {
+		if err := runCheck(check, constants.ReadinessCheckTimeout); err != nil {
+			h.log(c.Request.Context()).Warnf("[ready] %s check failed: %v", name, err)
+			results[name] = err.Error()
+			ready = false
+			continue
+		}
+		results[name] = "ok"
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": results})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": results})
+}
+
+// runCheck runs a readiness check, failing when it does not return within timeout
+func runCheck(check func() error, timeout time.Duration) error {
+	done := make(chan error, 1)
+	go func() { done <- check() }()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+// handleStatus reports the last success and failure of each upstream service, the cleanup
+// service state and the uptime. The status is "degraded" when the last call to an upstream failed.
+func (h *Handler) handleStatus(c *gin.Context) {
+	response := statusResponse{
+		Status:    "ok",
+		StartedAt: health.StartedAt().UTC(),
+		Uptime:    health.Uptime().Round(time.Second).String(),
+		Upstreams: health.Upstreams.Snapshot(),
+	}
+	for _, upstreams := range response.Upstreams {
+		for _, upstream := range upstreams {
+			if !upstream.Healthy {
+				response.Status = "degraded"
+			}
+		}
+	}
+	if h.services.Cleanup != nil {
+		cleanup := h.services.Cleanup.Status()
+		response.Cleanup = &cleanup
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -19,6 +19,11 @@ func (h *Handler) handleManifestWithConfig(c *gin.Context) {
 }
 
 func (h *Handler) createManifest() models.Manifest {
+	idPrefixes := []string{"tt", "tmdb:"} // IMDB (tt) and TMDB IDs
+	if h.services.Anime != nil {
+		idPrefixes = append(idPrefixes, "kitsu:", "mal:")
+	}
+
 	return models.Manifest{
 		ID:          constants.AddonID,
 		Version:     constants.AddonVersion,
@@ -31,7 +36,7 @@ func (h *Handler) createManifest() models.Manifest {
 			Configurable:    true,
 			ConfigurationRequired: true,  // Require configuration since we need API keys
 		},
-		IDPrefixes: []string{"tt", "tmdb:"},  // Only accept IMDB (tt) and TMDB IDs
+		IDPrefixes: idPrefixes,
 		Background: constants.AddonBackground,
 		Logo:       constants.AddonLogo,
 	}
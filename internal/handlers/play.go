@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/amaumene/gostremiofr/internal/config"
+	"github.com/amaumene/gostremiofr/internal/constants"
+	"github.com/amaumene/gostremiofr/internal/models"
+	"github.com/amaumene/gostremiofr/internal/services"
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/filematch"
+	"github.com/gin-gonic/gin"
+)
+
+// playPathPrefix marks stream URLs that still need the request base URL and configuration
+const playPathPrefix = "play/"
+
+// playToken identifies the file to unlock when a stream is opened. It is signed so that
+// clients cannot make the addon unlock arbitrary magnets.
+type playToken struct {
+	Hash     string `json:"h"`
+	File     int    `json:"f"`           // Index of the file in the magnet links
+	Season   int    `json:"s,omitempty"` // Target episode, used if the file list changed
+	Episode  int    `json:"e,omitempty"`
+	Absolute int    `json:"a,omitempty"` // Absolute number of anime episodes
+	Magnet   string `json:"m,omitempty"` // Debrid magnet ID the stream was built from
+}
+
+// playPath returns the play route path of a token, relative to the user configuration
+func (h *Handler) playPath(token playToken) string {
+	payload, _ := json.Marshal(token)
+	return playPathPrefix + h.signer.Sign(payload)
+}
+
+// resolvePlayURLs turns the play paths of the streams into absolute URLs for the requesting client
+func (h *Handler) resolvePlayURLs(c *gin.Context, streams []models.Stream) {
+	base := requestBaseURL(c) + "/" + url.PathEscape(c.Param("configuration")) + "/"
+	for i := range streams {
+		if strings.HasPrefix(streams[i].URL, playPathPrefix) {
+			streams[i].URL = base + streams[i].URL
+		}
+	}
+}
+
+// requestBaseURL returns the scheme and host the client used to reach the addon, honouring reverse proxies
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+
+	host := c.Request.Host
+	if forwarded := c.GetHeader("X-Forwarded-Host"); forwarded != "" {
+		host = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	return scheme + "://" + host
+}
+
+// handlePlay unlocks the file of a play token and redirects the player to the direct link
+func (h *Handler) handlePlay(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), constants.RequestTimeout)
+	defer cancel()
+
+	token, err := h.parsePlayToken(c.Param("token"))
+	if err != nil {
+		h.log(ctx).Warnf("[play] rejected token: %v", err)
+		c.String(http.StatusForbidden, "invalid play token")
+		return
+	}
+
+	userConfig, err := config.CreateFromUserData(decodeUserConfig(c.Param("configuration")), h.config)
+	if err != nil {
+		h.log(ctx).Warnf("[play] rejected configuration: %v", err)
+		c.String(http.StatusBadRequest, "invalid configuration")
+		return
+	}
+	account, err := h.resolveDebridAccount(c.Request.Context(), userConfig)
+	if err != nil {
+		c.String(http.StatusUnauthorized, "debrid account not configured")
+		return
+	}
+
+	directURL, err := h.unlockPlayToken(ctx, token, account)
+	if err != nil {
+		h.log(ctx).Errorf("[%s] failed to resolve play link for %s: %v", account.Provider.Name(), token.Hash, err)
+		c.String(http.StatusBadGateway, "stream is not available")
+		return
+	}
+
+	c.Redirect(http.StatusFound, directURL)
+}
+
+// parsePlayToken verifies the signature of a play token and decodes it
+func (h *Handler) parsePlayToken(raw string) (playToken, error) {
+	var token playToken
+	payload, err := h.signer.Verify(raw)
+	if err != nil {
+		return token, err
+	}
+	if err := json.Unmarshal(payload, &token); err != nil {
+		return token, fmt.Errorf("failed to decode play token: %w", err)
+	}
+	if token.Hash == "" {
+		return token, fmt.Errorf("play token without hash")
+	}
+	return token, nil
+}
+
+// unlockPlayToken picks the file of the token in its magnet and unlocks it
+func (h *Handler) unlockPlayToken(ctx context.Context, token playToken, account *services.DebridAccount) (string, error) {
+	magnet, err := h.playMagnet(ctx, token, account)
+	if err != nil {
+		return "", err
+	}
+
+	file := h.selectPlayFile(magnet.Links, token)
+	link, ok := file["link"].(string)
+	if !ok {
+		return "", fmt.Errorf("no playable file in magnet")
+	}
+
+	h.log(ctx).Infof("[%s] unlocking %v for playback", account.Provider.Name(), file["filename"])
+	return account.Provider.UnlockLink(ctx, link, account.APIKey)
+}
+
+// playMagnet returns the ready magnet of a play token. The magnet the stream was built from
+// is reused while the debrid account still lists it, otherwise the torrent is uploaded again;
+// readiness is then polled as during stream resolution.
+func (h *Handler) playMagnet(ctx context.Context, token playToken, account *services.DebridAccount) (*models.ProcessedMagnet, error) {
+	magnetID := token.Magnet
+	if magnetID != "" {
+		magnetInfo := models.MagnetInfo{Hash: token.Hash, Title: token.Hash, ID: magnetID}
+		magnets, err := account.Provider.CheckMagnets(ctx, []models.MagnetInfo{magnetInfo}, account.APIKey)
+		switch {
+		case err != nil:
+			h.log(ctx).Warnf("[%s] failed to check magnet %s, uploading it again: %v", account.Provider.Name(), magnetID, err)
+			magnetID = ""
+		case len(magnets) == 0:
+			h.log(ctx).Infof("[%s] magnet %s is gone, uploading it again", account.Provider.Name(), magnetID)
+			magnetID = ""
+		case h.isMagnetReady(magnets):
+			return &magnets[0], nil
+		}
+	}
+
+	if magnetID == "" {
+		id, err := h.uploadTorrent(ctx, token.Hash, token.Hash, account)
+		if err != nil {
+			return nil, err
+		}
+		magnetID = id
+	}
+
+	torrent := models.TorrentInfo{Hash: token.Hash, Title: token.Hash}
+	if magnet := h.waitForMagnetReady(ctx, token.Hash, magnetID, torrent, account); magnet != nil {
+		return magnet, nil
+	}
+	return nil, fmt.Errorf("magnet is not cached")
+}
+
+// selectPlayFile returns the file at the token index, falling back to the target episode
+// when the debrid provider now lists the files differently. Movie tokens fall back to the
+// largest file; episode tokens get no file rather than a guessed one.
+func (h *Handler) selectPlayFile(links []interface{}, token playToken) map[string]interface{} {
+	file := fileAt(links, token.File)
+	if _, ok := file["filename"].(string); ok {
+		if token.Episode == 0 {
+			return file
+		}
+		if episode, ok := filematch.Parse(linkFile(file).Path); ok && episode.Matches(episodeTarget(token.Season, token.Episode, token.Absolute)) {
+			return file
+		}
+	}
+
+	if token.Episode > 0 {
+		if index, found := findEpisodeFile(links, episodeTarget(token.Season, token.Episode, token.Absolute)); found {
+			return fileAt(links, index)
+		}
+		return nil
+	}
+	index, _ := findLargestFile(links)
+	return fileAt(links, index)
+}
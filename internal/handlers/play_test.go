@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/amaumene/gostremiofr/internal/models"
+	"github.com/amaumene/gostremiofr/internal/services"
+	"github.com/amaumene/gostremiofr/pkg/logger"
+	"github.com/amaumene/gostremiofr/pkg/security"
+)
+
+// fakeDebrid is a debrid provider whose magnets live in memory, keyed by magnet ID
+type fakeDebrid struct {
+	mu      sync.Mutex
+	magnets map[string]models.ProcessedMagnet
+	links   []interface{} // files of the magnets uploaded to it
+	uploads []string      // hashes uploaded to it
+	checks  int           // CheckMagnets calls
+	err     error         // returned by CheckMagnets when set
+}
+
+func (f *fakeDebrid) Name() string { return "Fake" }
+
+func (f *fakeDebrid) CheckInstantAvailability(ctx context.Context, hashes []string, apiKey string) (map[string]bool, error) {
+	return map[string]bool{}, nil
+}
+
+func (f *fakeDebrid) UploadMagnet(ctx context.Context, hash, title, apiKey string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.uploads = append(f.uploads, hash)
+	id := "uploaded-" + hash
+	if f.magnets == nil {
+		f.magnets = make(map[string]models.ProcessedMagnet)
+	}
+	f.magnets[id] = models.ProcessedMagnet{ID: id, Hash: hash, Ready: true, Links: f.links}
+	return id, nil
+}
+
+func (f *fakeDebrid) CheckMagnets(ctx context.Context, magnets []models.MagnetInfo, apiKey string) ([]models.ProcessedMagnet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.checks++
+	if f.err != nil {
+		return nil, f.err
+	}
+	var processed []models.ProcessedMagnet
+	for _, magnet := range magnets {
+		if found, ok := f.magnets[magnet.ID]; ok {
+			processed = append(processed, found)
+		}
+	}
+	return processed, nil
+}
+
+func (f *fakeDebrid) UnlockLink(ctx context.Context, link, apiKey string) (string, error) {
+	return "https://unlocked/" + link, nil
+}
+
+func (f *fakeDebrid) DeleteMagnet(ctx context.Context, magnetID, apiKey string) error {
+	return nil
+}
+
+func newTestHandler() *Handler {
+	return &Handler{
+		services: &services.Container{Logger: logger.New()},
+		signer:   security.NewTokenSigner([]byte("test secret")),
+	}
+}
+
+func episodeLinks(names ...string) []interface{} {
+	links := make([]interface{}, len(names))
+	for i, name := range names {
+		links[i] = map[string]interface{}{"filename": name, "link": "link-" + name, "size": float64(i + 1)}
+	}
+	return links
+}
+
+func TestPlayTokenRoundTrip(t *testing.T) {
+	h := newTestHandler()
+	token := playToken{Hash: "abc", File: 2, Season: 1, Episode: 5, Absolute: 30, Magnet: "m1"}
+
+	path := h.playPath(token)
+	if !strings.HasPrefix(path, playPathPrefix) {
+		t.Fatalf("playPath = %q, want the %q prefix", path, playPathPrefix)
+	}
+	raw := strings.TrimPrefix(path, playPathPrefix)
+	got, err := h.parsePlayToken(raw)
+	if err != nil {
+		t.Fatalf("parsePlayToken: %v", err)
+	}
+	if got != token {
+		t.Errorf("parsePlayToken = %+v, want %+v", got, token)
+	}
+
+	if _, err := newTestHandler().parsePlayToken(raw); err != nil {
+		t.Errorf("token rejected by a handler sharing the secret: %v", err)
+	}
+	other := newTestHandler()
+	other.signer = security.NewTokenSigner([]byte("other secret"))
+	if _, err := other.parsePlayToken(raw); err == nil {
+		t.Error("token accepted by a handler with another secret")
+	}
+	if _, err := h.parsePlayToken(raw[:len(raw)-2]); err == nil {
+		t.Error("truncated token accepted")
+	}
+	if _, err := h.parsePlayToken(strings.TrimPrefix(h.playPath(playToken{File: 1}), playPathPrefix)); err == nil {
+		t.Error("token without hash accepted")
+	}
+}
+
+func TestUnlockPlayToken(t *testing.T) {
+	links := episodeLinks("Show.S01E01.mkv", "Show.S01E02.mkv", "Show.S01E03.mkv")
+	tests := []struct {
+		name        string
+		token       playToken
+		magnets     map[string]models.ProcessedMagnet
+		wantURL     string
+		wantUploads int
+	}{
+		{
+			name:    "magnet reused",
+			token:   playToken{Hash: "abc", File: 1, Season: 1, Episode: 2, Magnet: "m1"},
+			magnets: map[string]models.ProcessedMagnet{"m1": {ID: "m1", Hash: "abc", Ready: true, Links: links}},
+			wantURL: "https://unlocked/link-Show.S01E02.mkv",
+		},
+		{
+			name:        "magnet gone",
+			token:       playToken{Hash: "abc", File: 1, Season: 1, Episode: 2, Magnet: "m1"},
+			wantURL:     "https://unlocked/link-Show.S01E02.mkv",
+			wantUploads: 1,
+		},
+		{
+			name:        "token without magnet",
+			token:       playToken{Hash: "abc", File: 0},
+			wantURL:     "https://unlocked/link-Show.S01E01.mkv",
+			wantUploads: 1,
+		},
+		{
+			name:    "file list changed",
+			token:   playToken{Hash: "abc", File: 0, Season: 1, Episode: 3, Magnet: "m1"},
+			magnets: map[string]models.ProcessedMagnet{"m1": {ID: "m1", Hash: "abc", Ready: true, Links: links}},
+			wantURL: "https://unlocked/link-Show.S01E03.mkv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeDebrid{magnets: tt.magnets, links: links}
+			account := &services.DebridAccount{ProviderID: "fake", Provider: provider, APIKey: "key"}
+
+			url, err := newTestHandler().unlockPlayToken(context.Background(), tt.token, account)
+			if err != nil {
+				t.Fatalf("unlockPlayToken: %v", err)
+			}
+			if url != tt.wantURL {
+				t.Errorf("unlockPlayToken = %q, want %q", url, tt.wantURL)
+			}
+			if len(provider.uploads) != tt.wantUploads {
+				t.Errorf("uploads = %v, want %d", provider.uploads, tt.wantUploads)
+			}
+		})
+	}
+}
+
+func TestSelectPlayFileRejectsOtherEpisodes(t *testing.T) {
+	links := episodeLinks("Show.S01E01.mkv", "Show.S01E02.mkv")
+	token := playToken{Hash: "abc", File: 1, Season: 1, Episode: 7}
+	if file := newTestHandler().selectPlayFile(links, token); file != nil {
+		t.Errorf("selectPlayFile = %v, want no file", file)
+	}
+
+	movie := playToken{Hash: "abc", File: 5}
+	if file := newTestHandler().selectPlayFile(links, movie); file["filename"] != "Show.S01E02.mkv" {
+		t.Errorf("selectPlayFile = %v, want the largest file", file)
+	}
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/amaumene/gostremiofr/internal/cache"
+	"github.com/amaumene/gostremiofr/internal/config"
+	"github.com/amaumene/gostremiofr/internal/constants"
+	"github.com/amaumene/gostremiofr/internal/models"
+	"github.com/amaumene/gostremiofr/internal/services"
+	"github.com/amaumene/gostremiofr/pkg/logger"
+)
+
+// prefetchJob describes a next episode to resolve in the background
+type prefetchJob struct {
+	key              string // stream cache key of the episode
+	title            string
+	id               string
+	season           int
+	episode          int
+	absoluteEpisode  int // episode number anime releases use, 0 for other content
+	account          *services.DebridAccount
+	userConfig       *config.Config
+	originalLanguage string
+	packs            []string // hashes of the season packs the previous episode was served from
+	requestID        string   // ID of the request that scheduled the job, kept in its logs
+}
+
+// seasonPack is a ready season pack magnet, kept so the next episode can be picked without a new search
+type seasonPack struct {
+	magnetID string
+	links    []interface{}
+	torrent  models.TorrentInfo
+}
+
+// prefetcher resolves next episodes on a bounded pool of workers and keeps their streams in memory.
+// A nil prefetcher is valid and disables prefetching.
+type prefetcher struct {
+	jobs    chan prefetchJob
+	streams *cache.LRUCache // stream lists by stream cache key
+	packs   *cache.LRUCache // season packs by account fingerprint and lowercase hash
+	mu      sync.Mutex
+	pending map[string]bool
+	closed  bool // no more jobs are accepted once the queue is closed
+	workers sync.WaitGroup
+}
+
+// newPrefetcher starts workers goroutines running resolve for each scheduled job.
+// It returns nil when workers is 0.
+func newPrefetcher(workers int, resolve func(prefetchJob)) *prefetcher {
+	if workers <= 0 {
+		return nil
+	}
+
+	p := &prefetcher{
+		jobs:    make(chan prefetchJob, constants.PrefetchQueueSize),
+		streams: cache.NewNamed("prefetch_streams", constants.PrefetchCacheSize, constants.PrefetchCacheTTL),
+		packs:   cache.NewNamed("prefetch_packs", constants.PrefetchCacheSize, constants.PrefetchCacheTTL),
+		pending: make(map[string]bool),
+	}
+	p.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work(resolve)
+	}
+	return p
+}
+
+// work runs scheduled jobs until the queue is closed. Jobs still queued at that point are dropped.
+func (p *prefetcher) work(resolve func(prefetchJob)) {
+	defer p.workers.Done()
+	for job := range p.jobs {
+		p.mu.Lock()
+		closed := p.closed
+		p.mu.Unlock()
+		if !closed {
+			resolve(job)
+		}
+
+		p.mu.Lock()
+		delete(p.pending, job.key)
+		p.mu.Unlock()
+	}
+}
+
+// schedule queues a job unless the episode is already prefetched or pending.
+// It reports false when the job was dropped because the queue is full.
+func (p *prefetcher) schedule(job prefetchJob) bool {
+	if p == nil {
+		return true
+	}
+	if _, found := p.streams.Get(job.key); found {
+		return true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || p.pending[job.key] {
+		return true
+	}
+
+	select {
+	case p.jobs <- job:
+		p.pending[job.key] = true
+		return true
+	default:
+		return false
+	}
+}
+
+// close stops accepting jobs, drops the queued ones and waits for the running ones to finish,
+// so that no magnet upload is cut off halfway. It gives up when ctx is done.
+func (p *prefetcher) close(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("prefetch workers still running: %w", ctx.Err())
+	}
+}
+
+// lookup returns a copy of the prefetched streams of an episode
+func (p *prefetcher) lookup(key string) ([]models.Stream, bool) {
+	if p == nil {
+		return nil, false
+	}
+	cached, found := p.streams.Get(key)
+	if !found {
+		return nil, false
+	}
+	streams, ok := cached.([]models.Stream)
+	if !ok {
+		return nil, false
+	}
+	return append([]models.Stream(nil), streams...), true
+}
+
+// store keeps the streams resolved for an episode
+func (p *prefetcher) store(key string, streams []models.Stream) {
+	if p != nil {
+		p.streams.Set(key, streams)
+	}
+}
+
+// rememberSeasonPack keeps the files of a ready season pack of an account for the next episode
+func (p *prefetcher) rememberSeasonPack(account *services.DebridAccount, magnet *models.ProcessedMagnet, torrent models.TorrentInfo) {
+	if p != nil && magnet.Hash != "" {
+		p.packs.Set(seasonPackKey(account, magnet.Hash), &seasonPack{magnetID: magnet.ID, links: magnet.Links, torrent: torrent})
+	}
+}
+
+// seasonPack returns the remembered season pack of an account with the given hash
+func (p *prefetcher) seasonPack(account *services.DebridAccount, hash string) (*seasonPack, bool) {
+	if p == nil {
+		return nil, false
+	}
+	cached, found := p.packs.Get(seasonPackKey(account, hash))
+	if !found {
+		return nil, false
+	}
+	pack, ok := cached.(*seasonPack)
+	return pack, ok
+}
+
+// seasonPackKey scopes a season pack to the debrid account whose magnet ID and links it holds
+func seasonPackKey(account *services.DebridAccount, hash string) string {
+	return account.Fingerprint() + ":" + strings.ToLower(hash)
+}
+
+// streamCacheKey identifies the streams of an episode for a user. Settings changing the
+// returned streams are part of the key so that users never share a stream list.
+func streamCacheKey(id string, season, episode int, userConfig *config.Config) string {
+	fingerprint, _ := json.Marshal([]interface{}{
+		userConfig.DebridAccounts(), userConfig.ResToShow, userConfig.LangToShow, userConfig.MaxStreams, userConfig.ScoreWeights,
+	})
+	sum := sha256.Sum256(fingerprint)
+	return fmt.Sprintf("streams:%s:%d:%d:%s", id, season, episode, hex.EncodeToString(sum[:8]))
+}
+
+// schedulePrefetch queues the episode following the one just served, passing along
+// the season packs it came from
+func (h *Handler) schedulePrefetch(ctx context.Context, title string, season, episode int, account *services.DebridAccount, id string, userConfig *config.Config, originalLanguage string, streams []models.Stream) {
+	if h.prefetch == nil || !userConfig.PrefetchNextEpisode || season <= 0 || episode <= 0 {
+		return
+	}
+
+	job := prefetchJob{
+		key:              streamCacheKey(id, season, episode+1, userConfig),
+		title:            title,
+		id:               id,
+		season:           season,
+		episode:          episode + 1,
+		absoluteEpisode:  nextAbsoluteEpisode(ctx),
+		account:          account,
+		userConfig:       userConfig,
+		originalLanguage: originalLanguage,
+		packs:            streamHashes(streams),
+		requestID:        logger.RequestID(ctx),
+	}
+	if !h.prefetch.schedule(job) {
+		h.log(ctx).Debugf("[prefetch] queue full, dropping s%02de%02d of %s", job.season, job.episode, title)
+	}
+}
+
+// nextAbsoluteEpisode returns the absolute number of the episode following the one served with ctx, 0 when unknown
+func nextAbsoluteEpisode(ctx context.Context) int {
+	if episode := absoluteEpisodeFrom(ctx); episode > 0 {
+		return episode + 1
+	}
+	return 0
+}
+
+// runPrefetch resolves the streams of a prefetch job, from a known season pack when possible
+func (h *Handler) runPrefetch(job prefetchJob) {
+	ctx, cancel := context.WithTimeout(logger.WithRequestID(context.Background(), job.requestID), constants.RequestTimeout)
+	defer cancel()
+	ctx = withAbsoluteEpisode(ctx, job.absoluteEpisode)
+
+	streams := h.streamsFromSeasonPacks(job)
+	if len(streams) > 0 {
+		h.log(ctx).Infof("[prefetch] s%02de%02d of %s found in season pack", job.season, job.episode, job.title)
+	} else {
+		h.log(ctx).Infof("[prefetch] resolving s%02de%02d of %s", job.season, job.episode, job.title)
+		streams = h.resolveSeriesStreams(ctx, job.title, job.season, job.episode, job.account, job.id, job.userConfig, job.originalLanguage)
+	}
+
+	if len(streams) == 0 {
+		h.log(ctx).Debugf("[prefetch] no stream for s%02de%02d of %s", job.season, job.episode, job.title)
+		return
+	}
+	h.prefetch.store(job.key, streams)
+}
+
+// streamsFromSeasonPacks builds the streams of the job episode from the season packs of the previous episode
+func (h *Handler) streamsFromSeasonPacks(job prefetchJob) []models.Stream {
+	var streams []models.Stream
+	for _, hash := range job.packs {
+		if len(streams) >= job.userConfig.MaxStreams {
+			break
+		}
+		pack, found := h.prefetch.seasonPack(job.account, hash)
+		if !found {
+			continue
+		}
+		index, found := findEpisodeFile(pack.links, episodeTarget(job.season, job.episode, job.absoluteEpisode))
+		if !found {
+			continue
+		}
+		file := fileAt(pack.links, index)
+		if filename, _ := file["filename"].(string); h.isBDMVFile(filename) {
+			continue
+		}
+		token := playToken{Hash: hash, File: index, Season: job.season, Episode: job.episode, Absolute: job.absoluteEpisode}
+		if stream := h.createStreamFromFile(file, token, pack.magnetID, pack.torrent); stream != nil {
+			streams = append(streams, *stream)
+		}
+	}
+	return streams
+}
+
+// streamHashes returns the torrent hashes of the streams
+func streamHashes(streams []models.Stream) []string {
+	var hashes []string
+	for _, stream := range streams {
+		if stream.File != nil {
+			hashes = append(hashes, stream.File.Hash)
+		}
+	}
+	return hashes
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amaumene/gostremiofr/internal/models"
+)
+
+// blockingResolver resolves prefetch jobs once released, reporting each job it starts
+type blockingResolver struct {
+	started chan string
+	release chan struct{}
+}
+
+func newBlockingResolver() *blockingResolver {
+	return &blockingResolver{started: make(chan string, 10), release: make(chan struct{})}
+}
+
+func (r *blockingResolver) resolve(job prefetchJob) {
+	r.started <- job.key
+	<-r.release
+}
+
+func (r *blockingResolver) waitStarted(t *testing.T, want string) {
+	t.Helper()
+	select {
+	case key := <-r.started:
+		if key != want {
+			t.Fatalf("started %q, want %q", key, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("job %q did not start", want)
+	}
+}
+
+func (r *blockingResolver) assertIdle(t *testing.T) {
+	t.Helper()
+	select {
+	case key := <-r.started:
+		t.Fatalf("unexpected job %q started", key)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPrefetcherDeduplicatesJobs(t *testing.T) {
+	resolver := newBlockingResolver()
+	p := newPrefetcher(1, resolver.resolve)
+	defer p.close(context.Background())
+
+	if !p.schedule(prefetchJob{key: "e2"}) {
+		t.Fatal("job dropped")
+	}
+	resolver.waitStarted(t, "e2")
+
+	// Already running, then already queued
+	p.schedule(prefetchJob{key: "e2"})
+	p.schedule(prefetchJob{key: "e3"})
+	p.schedule(prefetchJob{key: "e3"})
+	resolver.release <- struct{}{}
+	resolver.waitStarted(t, "e3")
+	resolver.release <- struct{}{}
+	resolver.assertIdle(t)
+
+	// Prefetched episodes are not resolved again
+	p.store("e2", []models.Stream{{Name: "stream"}})
+	p.schedule(prefetchJob{key: "e2"})
+	resolver.assertIdle(t)
+
+	// Finished jobs that stored nothing can be scheduled again
+	p.schedule(prefetchJob{key: "e3"})
+	resolver.waitStarted(t, "e3")
+	resolver.release <- struct{}{}
+}
+
+func TestPrefetcherLookupReturnsCopy(t *testing.T) {
+	p := newPrefetcher(1, func(prefetchJob) {})
+	defer p.close(context.Background())
+
+	p.store("e2", []models.Stream{{Name: "first"}})
+	streams, found := p.lookup("e2")
+	if !found || len(streams) != 1 {
+		t.Fatalf("lookup = %v, %v, want the stored streams", streams, found)
+	}
+	streams[0].Name = "changed"
+	if again, _ := p.lookup("e2"); again[0].Name != "first" {
+		t.Errorf("lookup returned the stored slice, got %q", again[0].Name)
+	}
+	if _, found := p.lookup("e3"); found {
+		t.Error("lookup found an episode that was never stored")
+	}
+}
+
+func TestPrefetcherCloseWaitsForRunningJobs(t *testing.T) {
+	resolver := newBlockingResolver()
+	p := newPrefetcher(1, resolver.resolve)
+	p.schedule(prefetchJob{key: "e2"})
+	resolver.waitStarted(t, "e2")
+	p.schedule(prefetchJob{key: "e3"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.close(ctx); err == nil {
+		t.Fatal("close returned while a job was running")
+	}
+
+	// The running job finishes, the queued one is dropped and new ones are ignored
+	p.schedule(prefetchJob{key: "e4"})
+	resolver.release <- struct{}{}
+	if err := p.close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	resolver.assertIdle(t)
+}
+
+func TestNilPrefetcher(t *testing.T) {
+	if p := newPrefetcher(0, func(prefetchJob) {}); p != nil {
+		t.Fatal("prefetcher created without workers")
+	}
+
+	var p *prefetcher
+	if !p.schedule(prefetchJob{key: "e2"}) {
+		t.Error("nil prefetcher dropped a job")
+	}
+	if _, found := p.lookup("e2"); found {
+		t.Error("nil prefetcher found streams")
+	}
+	if err := p.close(context.Background()); err != nil {
+		t.Errorf("close: %v", err)
+	}
+}
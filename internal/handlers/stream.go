@@ -6,19 +6,25 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
-	"sort"
-	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/amaumene/gostremiofr/internal/config"
 	"github.com/amaumene/gostremiofr/internal/constants"
 	"github.com/amaumene/gostremiofr/internal/errors"
+	"github.com/amaumene/gostremiofr/internal/health"
+	"github.com/amaumene/gostremiofr/internal/metrics"
 	"github.com/amaumene/gostremiofr/internal/models"
 	"github.com/amaumene/gostremiofr/internal/services"
+	"github.com/amaumene/gostremiofr/internal/tracing"
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch"
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/filematch"
+	tsutils "github.com/amaumene/gostremiofr/pkg/torrentsearch/utils"
 	"github.com/cehbz/torrentname"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
 )
 
 var (
@@ -26,16 +32,19 @@ var (
 	episodeRegex     = regexp.MustCompile(`^tt\d+:(\d+):(\d+)$`)
 	tmdbIDRegex      = regexp.MustCompile(`^tmdb:\d+$`)
 	tmdbEpisodeRegex = regexp.MustCompile(`^tmdb:(\d+):(\d+):(\d+)$`)
+	animeIDRegex     = regexp.MustCompile(`^(kitsu|mal):(\d+)(?::(\d+))?$`)
 )
 
 type SearchParams struct {
-	Query       string
-	MediaType   string
-	Season      int
-	Episode     int
-	Year        int
-	ID          string
-	EpisodeOnly bool
+	Query          string
+	MediaType      string
+	Season         int
+	Episode        int
+	Year           int
+	ID             string
+	EpisodeOnly    bool
+	CompleteSeries bool   // search complete-series packs instead of the season
+	TMDBAPIKey     string // user's TMDB key, scoped to this request
 }
 
 type TorrentService interface {
@@ -53,17 +62,36 @@ func (h *Handler) handleStream(c *gin.Context) {
 		c.JSON(http.StatusOK, models.StreamResponse{Streams: []models.Stream{}})
 		return
 	}
+	ctx = withAbsoluteEpisode(ctx, req.absoluteEpisode)
 
-	streams := h.searchStreams(req.mediaType, req.title, req.year, req.season, req.episode, 
-		req.apiKey, req.id, req.config, req.originalLanguage)
+	streams, found := h.cachedStreams(ctx, req)
+	if found {
+		metrics.StreamResolutions.Inc(c.Param("type"), "cache_hit")
+		// Binge-watching from the cache still prefetches the next episode; entries
+		// stored before the title was recorded cannot be searched
+		if req.title != "" {
+			h.schedulePrefetch(ctx, req.title, req.season, req.episode, req.account, req.id, req.config, req.originalLanguage, streams)
+		}
+	} else {
+		if err := h.loadMediaInfo(ctx, c, req); err != nil {
+			c.JSON(http.StatusOK, models.StreamResponse{Streams: []models.Stream{}})
+			return
+		}
+		streams = h.searchStreams(ctx, req.mediaType, req.title, req.year, req.season, req.episode, 
+			req.account, req.id, req.config, req.originalLanguage)
+		h.storeStreamCache(ctx, req, streams)
+	}
+	h.resolvePlayURLs(c, streams)
 	c.JSON(http.StatusOK, models.StreamResponse{Streams: streams})
 }
 
 type streamRequest struct {
 	id               string
+	lookupID         string // ID looked up on TMDB, the mapped show for anime IDs
 	season           int
 	episode          int
-	apiKey           string
+	absoluteEpisode  int // episode number anime releases use, 0 for other content
+	account          *services.DebridAccount
 	mediaType        string
 	title            string
 	year             int
@@ -73,46 +101,65 @@ type streamRequest struct {
 
 func (h *Handler) validateStreamRequest(c *gin.Context) (*streamRequest, error) {
 	userConfig := decodeUserConfig(c.Param("configuration"))
-	apiKey := h.extractAllDebridKey(userConfig)
-	if apiKey == "" {
-		h.services.Logger.Warnf("missing AllDebrid API key")
-		return nil, errors.NewAPIKeyMissingError("AllDebrid")
+	userConfigStruct, err := config.CreateFromUserData(userConfig, h.config)
+	if err != nil {
+		h.log(c.Request.Context()).Warnf("rejected configuration: %v", err)
+		return nil, err
 	}
 
-	h.configureTMDBService(userConfig)
+	account, err := h.resolveDebridAccount(c.Request.Context(), userConfigStruct)
+	if err != nil {
+		return nil, err
+	}
 
 	id, season, episode := extractMediaIdentifiers(c.Param("id"))
 	if id == "" {
-		h.services.Logger.Errorf("invalid stream ID: %s", c.Param("id"))
+		h.log(c.Request.Context()).Errorf("invalid stream ID: %s", c.Param("id"))
 		return nil, errors.NewInvalidIDError(c.Param("id"))
 	}
 
-	userConfigStruct := config.CreateFromUserData(userConfig, h.config)
-	h.configureTorrentServices(userConfigStruct)
-
-	return h.buildStreamRequest(c, id, season, episode, apiKey, userConfigStruct)
+	req := &streamRequest{
+		id:       id,
+		lookupID: id,
+		season:   season,
+		episode:  episode,
+		account:  account,
+		config:   userConfigStruct,
+	}
+	if isAnimeID(id) {
+		mapping, found := h.services.Anime.Lookup(id)
+		if !found {
+			h.log(c.Request.Context()).Warnf("[anime] no mapping for %s", id)
+			return nil, errors.NewInvalidIDError(c.Param("id"))
+		}
+		req.lookupID = mapping.MediaID()
+		if episode > 0 {
+			// Kitsu and MyAnimeList number the episodes of each entry from 1, like fansub releases
+			req.season = mapping.Season
+			req.episode = mapping.Episode(episode)
+			req.absoluteEpisode = episode
+		}
+		h.log(c.Request.Context()).Debugf("[anime] %s mapped to %s s%02de%02d", id, req.lookupID, req.season, req.episode)
+	}
+	return req, nil
 }
 
-func (h *Handler) buildStreamRequest(c *gin.Context, id string, season, episode int, apiKey string, userConfig *config.Config) (*streamRequest, error) {
-	mediaType, title, year, originalLanguage, err := h.getMediaInfo(id, c.Param("type"))
+// loadMediaInfo completes the request with the TMDB title, year and original language
+func (h *Handler) loadMediaInfo(ctx context.Context, c *gin.Context, req *streamRequest) error {
+	tmdb := h.services.TMDB.WithAPIKey(req.config.TMDBAPIKey)
+	mediaType, title, year, originalLanguage, err := h.getMediaInfo(ctx, tmdb, req.lookupID, c.Param("type"))
 	if err != nil {
-		h.services.Logger.Debugf("TMDB lookup failed: %v", err)
-		return nil, err
+		h.log(ctx).Debugf("TMDB lookup failed: %v", err)
+		return err
 	}
 
-	h.services.Logger.Infof("[request] processing %s: %s", mediaType, title)
+	h.log(ctx).Infof("[request] processing %s: %s", mediaType, title)
 
-	return &streamRequest{
-		id:               id,
-		season:           season,
-		episode:          episode,
-		apiKey:           apiKey,
-		mediaType:        mediaType,
-		title:            title, // Keep for logging, but we'll use id for searching
-		year:             year,
-		originalLanguage: originalLanguage,
-		config:           userConfig,
-	}, nil
+	req.mediaType = mediaType
+	req.title = title // Keep for logging, but we'll use id for searching
+	req.year = year
+	req.originalLanguage = originalLanguage
+	return nil
 }
 
 func (h *Handler) monitorTimeout(ctx context.Context, id string) {
@@ -120,21 +167,30 @@ func (h *Handler) monitorTimeout(ctx context.Context, id string) {
 		<-ctx.Done()
 		if ctx.Err() == context.DeadlineExceeded {
 			timeoutErr := errors.NewTimeoutError(fmt.Sprintf("request processing for ID: %s", id))
-			h.services.Logger.Errorf("request timeout: %v", timeoutErr)
+			h.log(ctx).Errorf("request timeout: %v", timeoutErr)
 		}
 	}()
 }
 
-func (h *Handler) extractAllDebridKey(userConfig map[string]interface{}) string {
-	if val, ok := userConfig["API_KEY_ALLDEBRID"]; ok {
-		if key, ok := val.(string); ok && key != "" {
-			return key
-		}
+// resolveDebridAccount selects the user's debrid accounts; several accounts are combined
+// into a pool failing over between them
+func (h *Handler) resolveDebridAccount(ctx context.Context, userConfig *config.Config) (*services.DebridAccount, error) {
+	configured := userConfig.DebridAccounts()
+	if len(configured) == 0 {
+		h.log(ctx).Warnf("missing debrid API key")
+		return nil, errors.NewAPIKeyMissingError("debrid")
 	}
-	if h.config != nil {
-		return h.config.APIKeyAllDebrid
+
+	accounts := make([]*services.DebridAccount, 0, len(configured))
+	for _, entry := range configured {
+		account, err := services.ResolveDebridAccount(h.services.Debrid, entry.Provider, entry.APIKey)
+		if err != nil {
+			h.log(ctx).Warnf("%v", err)
+			return nil, errors.NewConfigurationError("invalid debrid provider", err)
+		}
+		accounts = append(accounts, account)
 	}
-	return ""
+	return services.NewDebridPool(accounts, h.services.DebridHealth), nil
 }
 
 func (h *Handler) extractTMDBKey(userConfig map[string]interface{}) string {
@@ -149,60 +205,43 @@ func (h *Handler) extractTMDBKey(userConfig map[string]interface{}) string {
 	return ""
 }
 
-func (h *Handler) configureTMDBService(userConfig map[string]interface{}) {
-	tmdbAPIKey := h.extractTMDBKey(userConfig)
-	if tmdbAPIKey == "" {
-		return
-	}
-	if tmdb, ok := h.services.TMDB.(*services.TMDB); ok {
-		tmdb.SetAPIKey(tmdbAPIKey)
-	}
-}
-
-func (h *Handler) configureTorrentServices(userConfig *config.Config) {
-	if h.services.TorrentSorter != nil {
-		h.services.TorrentSorter = services.NewTorrentSorter(userConfig)
-	}
-	
-	// Configure TorrentSearch with TMDB API key from client
-	if h.services.TorrentSearch != nil {
-		if userConfig.TMDBAPIKey != "" {
-			h.services.TorrentSearch.SetTMDBAPIKey(userConfig.TMDBAPIKey)
-		}
-	}
-}
+func (h *Handler) getMediaInfo(ctx context.Context, tmdb services.TMDBService, id, urlMediaType string) (mediaType, title string, year int, originalLanguage string, err error) {
+	ctx, span := tracer.Start(ctx, "stream.getMediaInfo", trace.WithAttributes(attribute.String("media.id", id)))
+	defer func() {
+		span.SetAttributes(attribute.String("media.type", mediaType), attribute.String("media.title", title))
+		tracing.End(span, err)
+	}()
 
-func (h *Handler) getMediaInfo(id, urlMediaType string) (string, string, int, string, error) {
 	if strings.HasPrefix(id, "tmdb:") {
-		return h.getTMDBInfo(id, urlMediaType)
+		return h.getTMDBInfo(ctx, tmdb, id, urlMediaType)
 	} else {
-		mediaType, title, _, year, originalLanguage, err := h.services.TMDB.GetIMDBInfo(id)
+		mediaType, title, _, year, originalLanguage, err = tmdb.GetIMDBInfo(ctx, id)
 		return mediaType, title, year, originalLanguage, err
 	}
 }
 
 
-func (h *Handler) getTMDBInfo(tmdbID, urlMediaType string) (string, string, int, string, error) {
+func (h *Handler) getTMDBInfo(ctx context.Context, tmdb services.TMDBService, tmdbID, urlMediaType string) (string, string, int, string, error) {
 	// Convert URL media type to TMDB format
 	tmdbMediaType := urlMediaType
 	if urlMediaType == "series" {
 		tmdbMediaType = "tv"
 	}
 
-	mediaType, title, _, year, originalLanguage, err := h.services.TMDB.GetTMDBInfoWithType(tmdbID, tmdbMediaType)
+	mediaType, title, _, year, originalLanguage, err := tmdb.GetTMDBInfoWithType(ctx, tmdbID, tmdbMediaType)
 	return mediaType, title, year, originalLanguage, err
 }
 
-func (h *Handler) searchStreams(mediaType, title string, year, season, episode int, apiKey, id string, userConfig *config.Config, originalLanguage string) []models.Stream {
+func (h *Handler) searchStreams(ctx context.Context, mediaType, title string, year, season, episode int, account *services.DebridAccount, id string, userConfig *config.Config, originalLanguage string) []models.Stream {
 	if mediaType == "movie" {
-		return h.searchMovieStreams(title, year, apiKey, id, userConfig, originalLanguage)
+		return h.searchMovieStreams(ctx, title, year, account, id, userConfig, originalLanguage)
 	} else if mediaType == "series" {
-		return h.searchSeriesStreams(title, season, episode, apiKey, id, userConfig, originalLanguage)
+		return h.searchSeriesStreams(ctx, title, season, episode, account, id, userConfig, originalLanguage)
 	}
 	return []models.Stream{}
 }
 
-func (h *Handler) searchMovieStreams(title string, year int, apiKey string, id string, userConfig *config.Config, originalLanguage string) []models.Stream {
+func (h *Handler) searchMovieStreams(ctx context.Context, title string, year int, account *services.DebridAccount, id string, userConfig *config.Config, originalLanguage string) []models.Stream {
 	// For movies, append year to query so BuildSearchQuery can extract and use it
 	query := title
 	if year > 0 {
@@ -210,131 +249,179 @@ func (h *Handler) searchMovieStreams(title string, year int, apiKey string, id s
 	}
 	
 	params := SearchParams{
-		Query:     query, // Use title+year for movie searches
-		MediaType: "movie",
-		Year:      year,
-		ID:        id,
+		Query:      query, // Use title+year for movie searches
+		MediaType:  "movie",
+		Year:       year,
+		ID:         id,
+		TMDBAPIKey: userConfig.TMDBAPIKey,
 	}
-	results := h.performLanguageBasedSearch(params, originalLanguage)
+	results := h.performLanguageBasedSearch(ctx, params, originalLanguage)
 
-	h.services.Logger.Debugf("[search] found %d movie torrents", len(results.MovieTorrents))
+	h.log(ctx).Debugf("[search] found %d movie torrents", len(results.MovieTorrents))
 
-	return h.processResults(results, apiKey, userConfig, year, 0, 0)
+	return h.processResults(ctx, results, account, userConfig, year, 0, 0)
 }
 
-// Two-phase search: season packs first, then specific episodes
-func (h *Handler) searchSeriesStreams(title string, season, episode int, apiKey, id string, userConfig *config.Config, originalLanguage string) []models.Stream {
-	h.services.Logger.Debugf("[search] searching for season %d", season)
+// searchSeriesStreams serves an episode, from the prefetched streams when available,
+// and schedules the prefetch of the next episode
+func (h *Handler) searchSeriesStreams(ctx context.Context, title string, season, episode int, account *services.DebridAccount, id string, userConfig *config.Config, originalLanguage string) []models.Stream {
+	streams, found := h.prefetch.lookup(streamCacheKey(id, season, episode, userConfig))
+	if found {
+		h.log(ctx).Infof("[prefetch] serving s%02de%02d of %s from prefetched streams", season, episode, title)
+		metrics.StreamResolutions.Inc("series", "prefetch_hit")
+	} else {
+		streams = h.resolveSeriesStreams(ctx, title, season, episode, account, id, userConfig, originalLanguage)
+	}
+
+	h.schedulePrefetch(ctx, title, season, episode, account, id, userConfig, originalLanguage, streams)
+	return streams
+}
+
+// Three-phase search: season packs first, then specific episodes, then complete-series packs
+func (h *Handler) resolveSeriesStreams(ctx context.Context, title string, season, episode int, account *services.DebridAccount, id string, userConfig *config.Config, originalLanguage string) []models.Stream {
+	h.log(ctx).Debugf("[search] searching for season %d", season)
 
 	params := SearchParams{
-		Query:     title, // Use title for torrent provider searches
-		MediaType: "series",
-		Season:    season,
-		Episode:   episode,
-		ID:        id,
+		Query:      title, // Use title for torrent provider searches
+		MediaType:  "series",
+		Season:     season,
+		Episode:    episode,
+		ID:         id,
+		TMDBAPIKey: userConfig.TMDBAPIKey,
 	}
 
 	// Phase 1: Season pack search
-	results := h.performLanguageBasedSearch(params, originalLanguage)
-	streams := h.processResults(results, apiKey, userConfig, 0, season, episode)
+	results := h.performLanguageBasedSearch(ctx, params, originalLanguage)
+	streams := h.processResults(ctx, results, account, userConfig, 0, season, episode)
 
 	if len(streams) > 0 {
 		return streams
 	}
 
 	// Phase 2: Episode-specific search if needed
-	if season > 0 && episode > 0 {
-		return h.searchSpecificEpisode(params, apiKey, userConfig, originalLanguage, season, episode)
+	if season > 0 && episode > 0 && ctx.Err() == nil {
+		streams = h.searchSpecificEpisode(ctx, params, account, userConfig, originalLanguage, season, episode)
+	}
+
+	// Phase 3: Complete-series packs holding every season
+	if len(streams) == 0 && season > 0 && ctx.Err() == nil {
+		streams = h.searchCompleteSeries(ctx, params, account, userConfig, originalLanguage, season, episode)
 	}
 
 	return streams
 }
 
-func (h *Handler) searchSpecificEpisode(params SearchParams, apiKey string, userConfig *config.Config, originalLanguage string, season, episode int) []models.Stream {
-	h.services.Logger.Debugf("[search] trying episode-specific search: s%02de%02d", season, episode)
+func (h *Handler) searchCompleteSeries(ctx context.Context, params SearchParams, account *services.DebridAccount, userConfig *config.Config, originalLanguage string, season, episode int) []models.Stream {
+	h.log(ctx).Debugf("[search] trying complete-series search for season %d", season)
+
+	params.CompleteSeries = true
+	results := h.performLanguageBasedSearch(ctx, params, originalLanguage)
+	return h.processResults(ctx, results, account, userConfig, 0, season, episode)
+}
+
+func (h *Handler) searchSpecificEpisode(ctx context.Context, params SearchParams, account *services.DebridAccount, userConfig *config.Config, originalLanguage string, season, episode int) []models.Stream {
+	h.log(ctx).Debugf("[search] trying episode-specific search: s%02de%02d", season, episode)
 
 	params.EpisodeOnly = true
-	episodeResults := h.performLanguageBasedSearch(params, originalLanguage)
-	streams := h.processResults(episodeResults, apiKey, userConfig, 0, season, episode)
+	episodeResults := h.performLanguageBasedSearch(ctx, params, originalLanguage)
+	streams := h.processResults(ctx, episodeResults, account, userConfig, 0, season, episode)
 
 	// Removed the 3rd fallback (ID-only search) as requested
 	
 	return streams
 }
 
-func (h *Handler) performLanguageBasedSearch(params SearchParams, originalLanguage string) *models.CombinedTorrentResults {
+func (h *Handler) performLanguageBasedSearch(ctx context.Context, params SearchParams, originalLanguage string) *models.CombinedTorrentResults {
 	// Use the new smart torrentsearch with the original user query, not the resolved title
-	h.services.Logger.Debugf("[search] using smart torrentsearch - language: %s, query: %s", originalLanguage, params.Query)
+	h.log(ctx).Debugf("[search] using smart torrentsearch - language: %s, query: %s", originalLanguage, params.Query)
 	
-	results, metadata, err := h.services.TorrentSearch.SearchSmart(
-		params.Query,
-		params.MediaType,
-		params.Season,
-		params.Episode,
-		params.EpisodeOnly,
-	)
+	result, err := h.services.TorrentSearch.SearchSmart(ctx, torrentsearch.SearchRequest{
+		TMDBAPIKey:      params.TMDBAPIKey,
+		Query:           params.Query,
+		MediaType:       params.MediaType,
+		Season:          params.Season,
+		Episode:         params.Episode,
+		SpecificEpisode: params.EpisodeOnly,
+		CompleteSeries:  params.CompleteSeries,
+		AbsoluteEpisode: absoluteEpisode(ctx, params),
+		Logger:          h.log(ctx),
+	})
 	
 	if err != nil {
-		h.services.Logger.Errorf("[search] smart search failed: %v", err)
+		h.log(ctx).Errorf("[search] smart search failed: %v", err)
 		return &models.CombinedTorrentResults{}
 	}
 	
-	// Log provider URLs in debug mode
-	if providerURLs := h.services.TorrentSearch.GetProviderURLs(); len(providerURLs) > 0 {
-		for provider, apiURL := range providerURLs {
-			h.services.Logger.Debugf("[%s] API URL: %s", provider, apiURL)
+	// Provider URLs, timings and errors are logged by the search, record their metrics
+	for provider, diagnostics := range result.Diagnostics {
+		metrics.ProviderSearchDuration.Observe(diagnostics.Duration.Seconds(), provider)
+		metrics.ProviderTorrents.Add(float64(diagnostics.Count), provider)
+		health.Upstreams.Record(health.KindProvider, provider, diagnostics.Err)
+		if diagnostics.Err != nil {
+			metrics.ProviderSearchErrors.Inc(provider)
 		}
 	}
 	
-	// Log any provider errors
-	if providerErrors := h.services.TorrentSearch.GetProviderErrors(); len(providerErrors) > 0 {
-		for provider, err := range providerErrors {
-			h.services.Logger.Warnf("[%s] search error: %v", provider, err)
-		}
-	}
-	
-	if metadata != nil {
-		h.services.Logger.Debugf("[search] metadata: original_lang=%s, english='%s', french='%s'",
+	if metadata := result.Metadata; metadata != nil {
+		h.log(ctx).Debugf("[search] metadata: original_lang=%s, english='%s', french='%s'",
 			metadata.OriginalLanguage, metadata.EnglishTitle, metadata.FrenchTitle)
 	}
 	
 	// Convert results from torrentsearch format to internal format
-	return h.convertTorrentSearchResults(results)
+	return h.convertTorrentSearchResults(ctx, result.Results)
 }
 
-func (h *Handler) aggregateSearchResults(results *models.TorrentResults, combinedResults *models.CombinedTorrentResults, mu *sync.Mutex, episodeOnly bool) {
-	if results == nil {
-		return
-	}
-
-	if episodeOnly {
-		mu.Lock()
-		combinedResults.EpisodeTorrents = append(combinedResults.EpisodeTorrents, results.EpisodeTorrents...)
-		mu.Unlock()
-	} else {
-		aggregateResults(results, combinedResults, mu)
+// absoluteEpisode returns the episode number anime releases use, 0 for other content.
+func absoluteEpisode(ctx context.Context, params SearchParams) int {
+	if params.MediaType != "series" || !isAnimeID(params.ID) {
+		return 0
 	}
+	return absoluteEpisodeFrom(ctx)
 }
 
-func (h *Handler) logSearchResults(results *models.CombinedTorrentResults) {
-	h.services.Logger.Debugf("[search] found %d torrents total", 
-		len(results.MovieTorrents) + len(results.CompleteSeriesTorrents) + 
-		len(results.CompleteSeasonTorrents) + len(results.EpisodeTorrents))
-
-}
+func (h *Handler) processResults(ctx context.Context, results *models.CombinedTorrentResults, account *services.DebridAccount, userConfig *config.Config, year int, targetSeason, targetEpisode int) []models.Stream {
+	ctx, span := tracer.Start(ctx, "stream.processResults", trace.WithAttributes(attribute.String("debrid.provider", account.Provider.Name())))
+	defer span.End()
 
-func (h *Handler) processResults(results *models.CombinedTorrentResults, apiKey string, userConfig *config.Config, year int, targetSeason, targetEpisode int) []models.Stream {
-	h.services.Logger.Debugf("[processing] %d results", h.countResults(results))
+	h.log(ctx).Debugf("[processing] %d results", h.countResults(results))
 
 	if year > 0 && len(results.MovieTorrents) > 0 {
-		results.MovieTorrents = h.filterMoviesByYear(results.MovieTorrents, year)
+		results.MovieTorrents = h.filterMoviesByYear(ctx, results.MovieTorrents, year)
 	}
 
 	allTorrents := h.prioritizeTorrents(results, targetSeason, targetEpisode)
-	h.services.Logger.Infof("[processing] %d torrents in priority order", len(allTorrents))
+	h.log(ctx).Infof("[processing] %d torrents in priority order", len(allTorrents))
+
+	sorter := services.NewTorrentSorter(userConfig)
+	allTorrents = h.sortTorrents(ctx, allTorrents, sorter, targetSeason, targetEpisode)
+	allTorrents = h.filterByUserPreferences(ctx, allTorrents, userConfig)
+
+	var streams []models.Stream
+	if userConfig != nil && userConfig.MaxStreams > 1 {
+		streams = h.processRankedTorrents(ctx, allTorrents, account, userConfig.MaxStreams, userConfig.UploadUncached, targetSeason, targetEpisode)
+	} else {
+		streams = h.processSequentialTorrents(ctx, allTorrents, account, userConfig, targetSeason, targetEpisode)
+	}
+	recordResolution(targetSeason, targetEpisode, len(allTorrents), len(streams))
+	span.SetAttributes(attribute.Int("stream.torrents", len(allTorrents)), attribute.Int("stream.streams", len(streams)))
+	return streams
+}
+
+// recordResolution counts the outcome of a processed search
+func recordResolution(targetSeason, targetEpisode, torrents, streams int) {
+	mediaType := "movie"
+	if targetSeason > 0 || targetEpisode > 0 {
+		mediaType = "series"
+	}
 
-	allTorrents = h.sortTorrents(allTorrents, targetSeason, targetEpisode)
-	return h.processSequentialTorrents(allTorrents, apiKey, userConfig, targetSeason, targetEpisode)
+	switch {
+	case streams > 0:
+		metrics.StreamResolutions.Inc(mediaType, "resolved")
+	case torrents > 0:
+		metrics.StreamResolutions.Inc(mediaType, "not_cached")
+	default:
+		metrics.StreamResolutions.Inc(mediaType, "no_results")
+	}
 }
 
 func (h *Handler) countResults(results *models.CombinedTorrentResults) int {
@@ -342,19 +429,53 @@ func (h *Handler) countResults(results *models.CombinedTorrentResults) int {
 		len(results.CompleteSeasonTorrents) + len(results.CompleteSeriesTorrents)
 }
 
-func (h *Handler) filterMoviesByYear(movies []models.TorrentInfo, year int) []models.TorrentInfo {
+func (h *Handler) filterMoviesByYear(ctx context.Context, movies []models.TorrentInfo, year int) []models.TorrentInfo {
 	var filteredMovies []models.TorrentInfo
 	for _, torrent := range movies {
 		if h.matchesYear(torrent.Title, year) {
 			filteredMovies = append(filteredMovies, torrent)
 		} else {
-			h.services.Logger.Debugf("[filtering] torrent filtered by year - title: %s (expected: %d)", torrent.Title, year)
+			h.log(ctx).Debugf("[filtering] torrent filtered by year - title: %s (expected: %d)", torrent.Title, year)
 		}
 	}
-	h.services.Logger.Infof("[filtering] year: %d -> %d movie torrents", len(movies), len(filteredMovies))
+	h.log(ctx).Infof("[filtering] year: %d -> %d movie torrents", len(movies), len(filteredMovies))
 	return filteredMovies
 }
 
+// filterByUserPreferences applies RES_TO_SHOW and LANG_TO_SHOW to the sorted torrents.
+// Torrents tagged with an excluded resolution or language are dropped; torrents without
+// a resolution or language tag are kept after the ones that match.
+func (h *Handler) filterByUserPreferences(ctx context.Context, torrents []models.TorrentInfo, userConfig *config.Config) []models.TorrentInfo {
+	if userConfig == nil {
+		return torrents
+	}
+
+	var matching, untagged []models.TorrentInfo
+	for _, torrent := range torrents {
+		resolution := torrentResolution(torrent.Title)
+		if resolution != "unknown" && !userConfig.IsResolutionAllowed(resolution) {
+			h.log(ctx).Debugf("[filtering] torrent filtered by resolution %s: %s", resolution, torrent.Title)
+			continue
+		}
+
+		languages := torrentLanguages(torrent.Title)
+		if len(languages) > 0 && !userConfig.IsLanguageAllowed(languages...) {
+			h.log(ctx).Debugf("[filtering] torrent filtered by language %v: %s", languages, torrent.Title)
+			continue
+		}
+
+		if resolution == "unknown" || (len(languages) == 0 && userConfig.HasLanguageFilter()) {
+			untagged = append(untagged, torrent)
+			continue
+		}
+		matching = append(matching, torrent)
+	}
+
+	h.log(ctx).Infof("[filtering] user preferences: %d -> %d torrents (%d without tags moved last)",
+		len(torrents), len(matching)+len(untagged), len(untagged))
+	return append(matching, untagged...)
+}
+
 func (h *Handler) prioritizeTorrents(results *models.CombinedTorrentResults, targetSeason, targetEpisode int) []models.TorrentInfo {
 	var allTorrents []models.TorrentInfo
 
@@ -376,87 +497,92 @@ func (h *Handler) prioritizeTorrents(results *models.CombinedTorrentResults, tar
 	return allTorrents
 }
 
-func (h *Handler) sortTorrents(torrents []models.TorrentInfo, targetSeason, targetEpisode int) []models.TorrentInfo {
+func (h *Handler) sortTorrents(ctx context.Context, torrents []models.TorrentInfo, sorter *services.TorrentSorter, targetSeason, targetEpisode int) []models.TorrentInfo {
 	// First, validate torrents using torrent name parsing
 	var validatedTorrents []models.TorrentInfo
 	for _, t := range torrents {
-		if h.validateTorrentForEpisode(t.Title, targetSeason, targetEpisode) {
+		if h.validateTorrentForEpisode(t.Title, episodeTarget(targetSeason, targetEpisode, absoluteEpisodeFrom(ctx))) {
 			validatedTorrents = append(validatedTorrents, t)
 		} else {
-			h.services.Logger.Debugf("[validation] torrent filtered by name: %s (s%02de%02d)", t.Title, targetSeason, targetEpisode)
+			h.log(ctx).Debugf("[validation] torrent filtered by name: %s (s%02de%02d)", t.Title, targetSeason, targetEpisode)
 		}
 	}
 	
 	if len(validatedTorrents) == 0 {
-		h.services.Logger.Infof("[validation] no torrents passed name validation, keeping all %d torrents", len(torrents))
+		h.log(ctx).Infof("[validation] no torrents passed name validation, keeping all %d torrents", len(torrents))
 		validatedTorrents = torrents
 	} else {
-		h.services.Logger.Infof("[validation] name validation: %d -> %d torrents", len(torrents), len(validatedTorrents))
+		h.log(ctx).Infof("[validation] name validation: %d -> %d torrents", len(torrents), len(validatedTorrents))
 	}
 	
-	// Check if any torrents have confidence scores
+	filteredTorrents := h.filterByConfidence(ctx, validatedTorrents, sorter.MinConfidence())
+
+	// Rank by weighted score: quality tags, French audio, swarm health, size and provider weight
+	sorter.SortTorrents(filteredTorrents)
+	if len(filteredTorrents) > 0 {
+		h.log(ctx).Infof("[sorting] by score: %.1f to %.1f",
+			sorter.Score(filteredTorrents[0]).Total, sorter.Score(filteredTorrents[len(filteredTorrents)-1]).Total)
+	}
+
+	// Log the score breakdown of the top torrents in debug mode
+	for i, t := range filteredTorrents {
+		if i >= 5 {
+			break
+		}
+		h.log(ctx).Debugf("[%s] torrent %d: %.0f%% confidence, %.2f GB, %d seeders, %s - %s",
+			t.Source, i+1, t.ConfidenceScore, float64(t.Size)/(1024*1024*1024), t.Seeders, sorter.Score(t), t.Title)
+	}
+
+	return filteredTorrents
+}
+
+// filterByConfidence drops torrents whose parser confidence is below minConfidence.
+// Torrents are kept as they are when none has a confidence score or none reaches the minimum.
+func (h *Handler) filterByConfidence(ctx context.Context, torrents []models.TorrentInfo, minConfidence float64) []models.TorrentInfo {
 	hasConfidenceScores := false
-	for _, t := range validatedTorrents {
+	for _, t := range torrents {
 		if t.ConfidenceScore > 0 {
 			hasConfidenceScores = true
 			break
 		}
 	}
-	
-	if hasConfidenceScores {
-		// Filter torrents with confidence >= 75%
-		var filteredTorrents []models.TorrentInfo
-		for _, t := range validatedTorrents {
-			if t.ConfidenceScore >= 75.0 {
-				filteredTorrents = append(filteredTorrents, t)
-			}
-		}
-		
-		if len(filteredTorrents) == 0 {
-			h.services.Logger.Infof("[filtering] no torrents with confidence >= 75%%, keeping all %d torrents", len(validatedTorrents))
-			filteredTorrents = validatedTorrents
-		} else {
-			h.services.Logger.Infof("[filtering] confidence score: %d -> %d torrents (>= 75%%)", 
-				len(validatedTorrents), len(filteredTorrents))
-		}
-		
-		// Sort by size (descending - largest first)
-		sort.Slice(filteredTorrents, func(i, j int) bool {
-			return filteredTorrents[i].Size > filteredTorrents[j].Size
-		})
-		
-		if len(filteredTorrents) > 0 {
-			h.services.Logger.Infof("[sorting] by size: %.2f GB to %.2f GB", 
-				float64(filteredTorrents[0].Size)/(1024*1024*1024), 
-				float64(filteredTorrents[len(filteredTorrents)-1].Size)/(1024*1024*1024))
-		}
-		
-		// Log top torrents in debug mode
-		for i, t := range filteredTorrents {
-			if i < 5 { // Show top 5
-				h.services.Logger.Debugf("[%s] torrent %d: %.0f%% confidence, %.2f GB - %s", 
-					t.Source, i+1, t.ConfidenceScore, float64(t.Size)/(1024*1024*1024), t.Title)
-			}
-		}
-		
-		return filteredTorrents
-	} else {
-		// Fall back to old resolution/size-based sorting
-		sorter := h.services.TorrentSorter
-		if sorter != nil {
-			sorter.SortTorrents(validatedTorrents)
-			h.services.Logger.Infof("[sorting] by priority (resolution, size)")
+	if !hasConfidenceScores || minConfidence <= 0 {
+		return torrents
+	}
+
+	var filtered []models.TorrentInfo
+	for _, t := range torrents {
+		if t.ConfidenceScore >= minConfidence {
+			filtered = append(filtered, t)
 		}
-		return validatedTorrents
 	}
+
+	if len(filtered) == 0 {
+		h.log(ctx).Infof("[filtering] no torrents with confidence >= %.0f%%, keeping all %d torrents", minConfidence, len(torrents))
+		return torrents
+	}
+	h.log(ctx).Infof("[filtering] confidence score: %d -> %d torrents (>= %.0f%%)", len(torrents), len(filtered), minConfidence)
+	return filtered
 }
 
-func (h *Handler) validateTorrentForEpisode(torrentTitle string, targetSeason, targetEpisode int) bool {
+func (h *Handler) validateTorrentForEpisode(torrentTitle string, target filematch.Target) bool {
+	targetSeason, targetEpisode := target.Season, target.Episode
+
 	// Skip validation if no specific episode requested
 	if targetSeason == 0 && targetEpisode == 0 {
 		return true
 	}
 	
+	// Accept complete-series packs covering the season, whatever their first season is
+	if targetSeason > 0 && tsutils.MatchesCompleteSeries(torrentTitle, targetSeason) {
+		return true
+	}
+
+	// Accept anime releases numbered "[Group] Title - 05"
+	if episode, ok := filematch.Parse(torrentTitle); ok && targetEpisode > 0 && episode.Matches(target) {
+		return true
+	}
+
 	parsed := torrentname.Parse(torrentTitle)
 	if parsed == nil {
 		// If parsing fails, keep the torrent
@@ -497,109 +623,366 @@ func (h *Handler) validateTorrentForEpisode(torrentTitle string, targetSeason, t
 }
 
 // processSequentialTorrents processes torrents one by one until a working stream is found
-func (h *Handler) processSequentialTorrents(torrents []models.TorrentInfo, apiKey string, userConfig *config.Config, targetSeason, targetEpisode int) []models.Stream {
+func (h *Handler) processSequentialTorrents(ctx context.Context, torrents []models.TorrentInfo, account *services.DebridAccount, userConfig *config.Config, targetSeason, targetEpisode int) []models.Stream {
 	if len(torrents) == 0 {
-		h.services.Logger.Infof("[processing] no torrents to process")
+		h.log(ctx).Infof("[processing] no torrents to process")
 		return []models.Stream{}
 	}
 
-	h.services.Logger.Infof("[processing] %d torrents sequentially", len(torrents))
+	cached, candidates, err := h.findCachedCandidates(ctx, torrents, account)
+	if err != nil {
+		h.log(ctx).Warnf("[%s] instant availability check failed, falling back to upload checks: %v", account.Provider.Name(), err)
+		cached = candidates
+	} else if len(cached) == 0 {
+		if userConfig == nil || !userConfig.UploadUncached {
+			h.log(ctx).Infof("[%s] no candidate cached", account.Provider.Name())
+			return []models.Stream{}
+		}
+		h.log(ctx).Infof("[%s] no candidate cached, falling back to upload checks", account.Provider.Name())
+		cached = candidates
+	}
 
-	for i, torrent := range torrents {
-		stream := h.processSingleTorrent(torrent, i+1, len(torrents), apiKey, targetSeason, targetEpisode)
+	h.log(ctx).Infof("[processing] %d candidate torrents sequentially", len(cached))
+
+	for i, candidate := range cached {
+		if ctx.Err() != nil {
+			h.log(ctx).Infof("[processing] request cancelled, stopping after %d/%d torrents", i, len(cached))
+			return []models.Stream{}
+		}
+		stream := h.processCandidate(ctx, candidate, i+1, len(cached), account, targetSeason, targetEpisode)
 		if stream != nil {
-			h.services.Logger.Infof("[%s] successfully created stream from torrent: %s", torrent.Source, torrent.Title)
+			h.log(ctx).Infof("[%s] successfully created stream from torrent: %s", candidate.torrent.Source, candidate.torrent.Title)
 			return []models.Stream{*stream}
 		}
 	}
 
-	h.services.Logger.Infof("[processing] no working torrents found")
+	h.log(ctx).Infof("[processing] no working torrents found")
 	return []models.Stream{}
 }
 
-func (h *Handler) processSingleTorrent(torrent models.TorrentInfo, current, total int, apiKey string, targetSeason, targetEpisode int) *models.Stream {
-	h.services.Logger.Infof("[%s] trying torrent %d/%d: %s", torrent.Source, current, total, torrent.Title)
+// torrentCandidate is a ranked torrent whose info hash is known
+type torrentCandidate struct {
+	torrent  models.TorrentInfo
+	hash     string
+	magnetID string // debrid magnet ID, set once uploaded
+}
 
-	hash, err := h.getTorrentHash(torrent)
+// resolveCandidates fetches the hashes of the given torrents concurrently, skipping failures
+// and duplicates and keeping rank order
+func (h *Handler) resolveCandidates(ctx context.Context, torrents []models.TorrentInfo) []torrentCandidate {
+	hashes := make([]string, len(torrents))
+	var group errgroup.Group
+	group.SetLimit(constants.MaxConcurrentHashLookups)
+	for i, torrent := range torrents {
+		group.Go(func() error {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if hash, err := h.getTorrentHash(ctx, torrent); err == nil {
+				hashes[i] = hash
+			}
+			return nil
+		})
+	}
+	group.Wait()
+
+	var candidates []torrentCandidate
+	seen := make(map[string]bool)
+	for i, torrent := range torrents {
+		hash := hashes[i]
+		if hash == "" {
+			continue
+		}
+		key := strings.ToLower(hash)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		candidates = append(candidates, torrentCandidate{torrent: torrent, hash: hash})
+	}
+
+	return candidates
+}
+
+// findCachedCandidates checks the top ranked torrents against the debrid provider in one call
+// and returns those already cached along with every candidate whose hash is known, keeping rank order
+func (h *Handler) findCachedCandidates(ctx context.Context, torrents []models.TorrentInfo, account *services.DebridAccount) (cached, candidates []torrentCandidate, err error) {
+	if len(torrents) > constants.MaxInstantCheckCandidates {
+		torrents = torrents[:constants.MaxInstantCheckCandidates]
+	}
+
+	candidates = h.resolveCandidates(ctx, torrents)
+	hashes := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		hashes = append(hashes, candidate.hash)
+	}
+
+	availability, err := account.Provider.CheckInstantAvailability(ctx, hashes, account.APIKey)
 	if err != nil {
-		return nil
+		return nil, candidates, err
 	}
 
-	if hash == "" {
-		h.services.Logger.Warnf("[%s] skipping torrent without hash: %s", torrent.Source, torrent.Title)
-		return nil
+	for _, candidate := range candidates {
+		if availability[strings.ToLower(candidate.hash)] {
+			cached = append(cached, candidate)
+		} else {
+			h.log(ctx).Debugf("[%s] not cached, skipping upload: %s", account.Provider.Name(), candidate.torrent.Title)
+		}
+	}
+
+	h.log(ctx).Infof("[%s] instant availability: %d/%d candidates cached", account.Provider.Name(), len(cached), len(candidates))
+	return cached, candidates, nil
+}
+
+// processRankedTorrents uploads the top cached candidates, checks them against the debrid provider in a single
+// status call and returns up to maxStreams streams, preferring one stream per resolution
+func (h *Handler) processRankedTorrents(ctx context.Context, torrents []models.TorrentInfo, account *services.DebridAccount, maxStreams int, uploadUncached bool, targetSeason, targetEpisode int) []models.Stream {
+	ctx, span := tracer.Start(ctx, "stream.processRankedTorrents", trace.WithAttributes(attribute.Int("stream.max_streams", maxStreams)))
+	defer span.End()
+
+	if len(torrents) == 0 {
+		h.log(ctx).Infof("[processing] no torrents to process")
+		return []models.Stream{}
+	}
+
+	limit := maxStreams * constants.RankedCandidatesPerStream
+
+	cached, resolved, err := h.findCachedCandidates(ctx, torrents, account)
+	if err != nil {
+		h.log(ctx).Warnf("[%s] instant availability check failed, uploading top candidates: %v", account.Provider.Name(), err)
+		cached = resolved
+	} else if len(cached) == 0 {
+		if !uploadUncached {
+			h.log(ctx).Infof("[%s] no candidate cached", account.Provider.Name())
+			return []models.Stream{}
+		}
+		h.log(ctx).Infof("[%s] no candidate cached, uploading top candidates", account.Provider.Name())
+		cached = resolved
+	}
+	if len(cached) > limit {
+		cached = cached[:limit]
+	}
+
+	h.log(ctx).Infof("[processing] checking %d torrents for up to %d streams", len(cached), maxStreams)
+
+	candidates := h.uploadCandidates(ctx, cached, account)
+	if len(candidates) == 0 {
+		h.log(ctx).Infof("[processing] no torrents could be uploaded")
+		return []models.Stream{}
 	}
 
-	if err := h.uploadTorrent(hash, torrent.Title, apiKey); err != nil {
+	magnetInfos := make([]models.MagnetInfo, 0, len(candidates))
+	for _, candidate := range candidates {
+		magnetInfos = append(magnetInfos, models.MagnetInfo{Hash: candidate.hash, Title: candidate.torrent.Title, Source: candidate.torrent.Source, ID: candidate.magnetID})
+	}
+
+	processedMagnets, err := account.Provider.CheckMagnets(ctx, magnetInfos, account.APIKey)
+	if err != nil {
+		h.log(ctx).Errorf("[%s] CheckMagnets failed: %v", account.Provider.Name(), err)
+		return []models.Stream{}
+	}
+
+	magnetsByHash := make(map[string]*models.ProcessedMagnet, len(processedMagnets))
+	for i := range processedMagnets {
+		magnetsByHash[strings.ToLower(processedMagnets[i].Hash)] = &processedMagnets[i]
+	}
+
+	var ready []torrentCandidate
+	for _, candidate := range candidates {
+		magnet, ok := magnetsByHash[strings.ToLower(candidate.hash)]
+		if ok && magnet.Ready && len(magnet.Links) > 0 {
+			ready = append(ready, candidate)
+			continue
+		}
+		h.discardUncachedMagnet(ctx, magnet, candidate, account)
+	}
+
+	h.log(ctx).Infof("[%s] %d/%d candidate magnets are cached", account.Provider.Name(), len(ready), len(candidates))
+
+	var streams []models.Stream
+	for _, candidate := range orderByResolutionDiversity(ready) {
+		if len(streams) >= maxStreams {
+			break
+		}
+		magnet := magnetsByHash[strings.ToLower(candidate.hash)]
+		if stream := h.processSingleReadyMagnet(ctx, magnet, candidate.torrent, account, targetSeason, targetEpisode); stream != nil {
+			streams = append(streams, *stream)
+		}
+	}
+
+	h.log(ctx).Infof("[processing] returning %d ranked streams", len(streams))
+	if streams == nil {
+		return []models.Stream{}
+	}
+	return streams
+}
+
+// uploadCandidates uploads the magnets of the given candidates, keeping rank order
+func (h *Handler) uploadCandidates(ctx context.Context, candidates []torrentCandidate, account *services.DebridAccount) []torrentCandidate {
+	var uploaded []torrentCandidate
+	for _, candidate := range candidates {
+		if ctx.Err() != nil {
+			break
+		}
+		magnetID, err := h.uploadTorrent(ctx, candidate.hash, candidate.torrent.Title, account)
+		if err != nil {
+			continue
+		}
+		candidate.magnetID = magnetID
+		uploaded = append(uploaded, candidate)
+	}
+	return uploaded
+}
+
+// discardUncachedMagnet removes a magnet that the debrid provider does not have cached
+func (h *Handler) discardUncachedMagnet(ctx context.Context, magnet *models.ProcessedMagnet, candidate torrentCandidate, account *services.DebridAccount) {
+	magnetID := candidate.magnetID
+	if magnet != nil && magnet.ID != "" {
+		magnetID = magnet.ID
+	}
+	if magnetID == "" {
+		h.log(ctx).Debugf("[%s] magnet NOT CACHED - skipping torrent: %s", account.Provider.Name(), candidate.torrent.Title)
+		return
+	}
+
+	h.log(ctx).Debugf("[%s] magnet NOT CACHED - deleting torrent: %s", account.Provider.Name(), candidate.torrent.Title)
+	if err := account.Provider.DeleteMagnet(ctx, magnetID, account.APIKey); err != nil {
+		h.log(ctx).Errorf("[%s] failed to delete non-cached magnet %s: %v", account.Provider.Name(), magnetID, err)
+	}
+}
+
+// orderByResolutionDiversity moves the best candidate of each resolution to the front,
+// followed by the remaining candidates, both groups keeping their rank order
+func orderByResolutionDiversity(candidates []torrentCandidate) []torrentCandidate {
+	ordered := make([]torrentCandidate, 0, len(candidates))
+	var rest []torrentCandidate
+	seenResolutions := make(map[string]bool)
+
+	for _, candidate := range candidates {
+		resolution := torrentResolution(candidate.torrent.Title)
+		if seenResolutions[resolution] {
+			rest = append(rest, candidate)
+			continue
+		}
+		seenResolutions[resolution] = true
+		ordered = append(ordered, candidate)
+	}
+
+	return append(ordered, rest...)
+}
+
+// torrentResolution returns the normalized resolution tag of a torrent title
+func torrentResolution(title string) string {
+	parsed := torrentname.Parse(title)
+	if parsed == nil || parsed.Resolution == "" {
+		return "unknown"
+	}
+	resolution := strings.ToLower(parsed.Resolution)
+	if resolution == "4k" {
+		return "2160p"
+	}
+	return resolution
+}
+
+// processCandidate uploads a torrent with a known hash and builds a stream once its magnet is ready
+func (h *Handler) processCandidate(ctx context.Context, candidate torrentCandidate, current, total int, account *services.DebridAccount, targetSeason, targetEpisode int) (stream *models.Stream) {
+	torrent := candidate.torrent
+	ctx, span := tracer.Start(ctx, "stream.processCandidate", torrentSpanAttributes(torrent, current))
+	defer func() {
+		span.SetAttributes(attribute.Bool("stream.found", stream != nil))
+		span.End()
+	}()
+
+	h.log(ctx).Infof("[%s] trying torrent %d/%d: %s", torrent.Source, current, total, torrent.Title)
+
+	magnetID, err := h.uploadTorrent(ctx, candidate.hash, torrent.Title, account)
+	if err != nil {
 		return nil
 	}
 
-	readyMagnet := h.waitForMagnetReady(hash, torrent, apiKey)
+	readyMagnet := h.waitForMagnetReady(ctx, candidate.hash, magnetID, torrent, account)
 	if readyMagnet == nil {
 		return nil
 	}
 
-	stream := h.processSingleReadyMagnet(readyMagnet, torrent, targetSeason, targetEpisode, apiKey)
+	stream = h.processSingleReadyMagnet(ctx, readyMagnet, torrent, account, targetSeason, targetEpisode)
 	if stream == nil {
-		h.services.Logger.Warnf("[%s] failed to create stream from ready magnet: %s", torrent.Source, torrent.Title)
+		h.log(ctx).Warnf("[%s] failed to create stream from ready magnet: %s", torrent.Source, torrent.Title)
 	}
 	return stream
 }
 
-func (h *Handler) getTorrentHash(torrent models.TorrentInfo) (string, error) {
+// torrentSpanAttributes describes the torrent processed by a span
+func torrentSpanAttributes(torrent models.TorrentInfo, position int) trace.SpanStartEventOption {
+	return trace.WithAttributes(
+		attribute.String("torrent.source", torrent.Source),
+		attribute.String("torrent.title", torrent.Title),
+		attribute.Int("torrent.position", position),
+	)
+}
+
+func (h *Handler) getTorrentHash(ctx context.Context, torrent models.TorrentInfo) (string, error) {
 	hash := torrent.Hash
 	if hash != "" {
-		h.services.Logger.Debugf("[%s] torrent already has hash: %s", torrent.Source, hash)
+		h.log(ctx).Debugf("[%s] torrent already has hash: %s", torrent.Source, hash)
 		return hash, nil
 	}
 
-	h.services.Logger.Debugf("[%s] torrent needs hash fetch - ID: %s, Source: %s", torrent.Source, torrent.ID, torrent.Source)
+	h.log(ctx).Debugf("[%s] torrent needs hash fetch - ID: %s, Source: %s", torrent.Source, torrent.ID, torrent.Source)
 	
 	// For torrents without hash, try to fetch from provider
 	if torrent.Source == constants.ProviderYGG && h.services.TorrentSearch != nil {
-		h.services.Logger.Infof("[YGG] fetching hash for torrent: %s (ID: %s)", torrent.Title, torrent.ID)
-		fetchedHash, err := h.services.TorrentSearch.GetProviderHash(torrent.Source, torrent.ID)
+		h.log(ctx).Infof("[YGG] fetching hash for torrent: %s (ID: %s)", torrent.Title, torrent.ID)
+		hashCtx, span := tracer.Start(ctx, "stream.fetchTorrentHash", trace.WithAttributes(attribute.String("torrent.source", torrent.Source)))
+		fetchedHash, err := h.services.TorrentSearch.GetProviderHash(hashCtx, torrent.Source, torrent.ID)
+		tracing.End(span, err)
 		if err != nil {
-			h.services.Logger.Errorf("[YGG] failed to fetch hash for torrent %s: %v", torrent.Title, err)
+			h.log(ctx).Errorf("[YGG] failed to fetch hash for torrent %s: %v", torrent.Title, err)
 			return "", err
 		}
 		if fetchedHash == "" {
-			h.services.Logger.Warnf("[YGG] torrent %s returned empty hash", torrent.Title)
+			h.log(ctx).Warnf("[YGG] torrent %s returned empty hash", torrent.Title)
 			return "", fmt.Errorf("empty hash")
 		}
-		h.services.Logger.Infof("[YGG] successfully fetched hash: %s", fetchedHash)
+		h.log(ctx).Infof("[YGG] successfully fetched hash: %s", fetchedHash)
 		return fetchedHash, nil
 	}
 
-	h.services.Logger.Warnf("[%s] no hash available for non-YGG torrent or TorrentSearch not available", torrent.Source)
+	h.log(ctx).Warnf("[%s] no hash available for non-YGG torrent or TorrentSearch not available", torrent.Source)
 	return "", fmt.Errorf("no hash available for torrent")
 }
 
-func (h *Handler) uploadTorrent(hash, title, apiKey string) error {
-	h.services.Logger.Infof("[AllDebrid] uploading magnet: %s", title)
-	err := h.services.AllDebrid.UploadMagnet(hash, title, apiKey)
+func (h *Handler) uploadTorrent(ctx context.Context, hash, title string, account *services.DebridAccount) (string, error) {
+	h.log(ctx).Infof("[%s] uploading magnet: %s", account.Provider.Name(), title)
+	magnetID, err := account.Provider.UploadMagnet(ctx, hash, title, account.APIKey)
 	if err != nil {
-		h.services.Logger.Errorf("[AllDebrid] failed to upload magnet %s: %v", title, err)
+		h.log(ctx).Errorf("[%s] failed to upload magnet %s: %v", account.Provider.Name(), title, err)
 	}
-	return err
+	return magnetID, err
 }
 
 func (h *Handler) isMagnetReady(magnets []models.ProcessedMagnet) bool {
 	return len(magnets) > 0 && magnets[0].Ready && len(magnets[0].Links) > 0
 }
 
-func (h *Handler) waitForMagnetReady(hash string, torrent models.TorrentInfo, apiKey string) *models.ProcessedMagnet {
+func (h *Handler) waitForMagnetReady(ctx context.Context, hash, magnetID string, torrent models.TorrentInfo, account *services.DebridAccount) (ready *models.ProcessedMagnet) {
+	ctx, span := tracer.Start(ctx, "stream.waitForMagnetReady")
+	defer func() {
+		span.SetAttributes(attribute.Bool("magnet.cached", ready != nil))
+		span.End()
+	}()
+
 	var lastProcessedMagnet *models.ProcessedMagnet
 	
 	for attempt := 1; attempt <= constants.MaxMagnetCheckAttempts; attempt++ {
-		h.services.Logger.Infof("[AllDebrid] checking magnet status - attempt %d/2", attempt)
+		span.SetAttributes(attribute.Int("magnet.attempts", attempt))
+		h.log(ctx).Infof("[%s] checking magnet status - attempt %d/2", account.Provider.Name(), attempt)
 
-		magnetInfo := models.MagnetInfo{Hash: hash, Title: torrent.Title, Source: torrent.Source}
-		processedMagnets, err := h.services.AllDebrid.CheckMagnets([]models.MagnetInfo{magnetInfo}, apiKey)
+		magnetInfo := models.MagnetInfo{Hash: hash, Title: torrent.Title, Source: torrent.Source, ID: magnetID}
+		processedMagnets, err := account.Provider.CheckMagnets(ctx, []models.MagnetInfo{magnetInfo}, account.APIKey)
 		if err != nil {
-			h.services.Logger.Errorf("[AllDebrid] CheckMagnets failed: %v", err)
-			if attempt < constants.MaxMagnetCheckAttempts {
-				time.Sleep(constants.MagnetCheckRetryDelay)
+			h.log(ctx).Errorf("[%s] CheckMagnets failed: %v", account.Provider.Name(), err)
+			if attempt < constants.MaxMagnetCheckAttempts && sleepContext(ctx, constants.MagnetCheckRetryDelay) {
 				continue
 			}
 			break
@@ -609,93 +992,118 @@ func (h *Handler) waitForMagnetReady(hash string, torrent models.TorrentInfo, ap
 			lastProcessedMagnet = &processedMagnets[0]
 			
 			if h.isMagnetReady(processedMagnets) {
-				h.services.Logger.Infof("[AllDebrid] magnet IS CACHED - ready with %d links: %s", len(processedMagnets[0].Links), torrent.Title)
+				h.log(ctx).Infof("[%s] magnet IS CACHED - ready with %d links: %s", account.Provider.Name(), len(processedMagnets[0].Links), torrent.Title)
 				return lastProcessedMagnet
 			}
 		}
 
 		if attempt < constants.MaxMagnetCheckAttempts {
-			h.services.Logger.Infof("[AllDebrid] magnet not ready yet, waiting before retry")
-			time.Sleep(constants.MagnetReadyRetryDelay)
+			h.log(ctx).Infof("[%s] magnet not ready yet, waiting before retry", account.Provider.Name())
+			if !sleepContext(ctx, constants.MagnetReadyRetryDelay) {
+				break
+			}
 		}
 	}
 
-	// If magnet is not cached, delete it from AllDebrid
-	if lastProcessedMagnet != nil && lastProcessedMagnet.ID > 0 {
-		h.services.Logger.Warnf("[AllDebrid] magnet NOT CACHED - deleting and skipping torrent: %s (hash: %s)", torrent.Title, hash[:12])
-		magnetID := fmt.Sprintf("%d", lastProcessedMagnet.ID)
-		if err := h.services.AllDebrid.DeleteMagnet(magnetID, apiKey); err != nil {
-			h.services.Logger.Errorf("[AllDebrid] failed to delete non-cached magnet %s: %v", magnetID, err)
+	// If magnet is not cached, delete it from the debrid account
+	if lastProcessedMagnet != nil && lastProcessedMagnet.ID != "" {
+		h.log(ctx).Warnf("[%s] magnet NOT CACHED - deleting and skipping torrent: %s (hash: %s)", account.Provider.Name(), torrent.Title, hash[:12])
+		if err := account.Provider.DeleteMagnet(ctx, lastProcessedMagnet.ID, account.APIKey); err != nil {
+			h.log(ctx).Errorf("[%s] failed to delete non-cached magnet %s: %v", account.Provider.Name(), lastProcessedMagnet.ID, err)
 		}
 	} else {
-		h.services.Logger.Warnf("[AllDebrid] magnet NOT CACHED - skipping torrent: %s (hash: %s)", torrent.Title, hash[:12])
+		h.log(ctx).Warnf("[%s] magnet NOT CACHED - skipping torrent: %s (hash: %s)", account.Provider.Name(), torrent.Title, hash[:12])
 	}
 	
 	return nil
 }
 
-func (h *Handler) processSingleReadyMagnet(magnet *models.ProcessedMagnet, torrent models.TorrentInfo, targetSeason, targetEpisode int, apiKey string) *models.Stream {
+// sleepContext waits for the given delay and reports false if ctx is done first
+func sleepContext(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// processSingleReadyMagnet picks the file to play from a ready magnet and builds its stream
+func (h *Handler) processSingleReadyMagnet(ctx context.Context, magnet *models.ProcessedMagnet, torrent models.TorrentInfo, account *services.DebridAccount, targetSeason, targetEpisode int) *models.Stream {
 	isSeasonPack := h.isSeasonPack(torrent.Title)
 
-	var stream *models.Stream
+	var index int
+	var found bool
 	if targetSeason > 0 && targetEpisode > 0 {
-		stream = h.processEpisodeFromMagnet(magnet, torrent, targetSeason, targetEpisode, isSeasonPack, apiKey)
+		index, found = h.selectEpisodeFile(ctx, magnet, torrent, targetSeason, targetEpisode, isSeasonPack)
 	} else {
-		stream = h.processLargestFile(magnet, torrent, targetSeason, targetEpisode, isSeasonPack, apiKey)
+		index, found = h.selectLargestFile(ctx, magnet, torrent, targetSeason, targetEpisode, isSeasonPack)
 	}
-	
-	// Check if the largest file is a BDMV file
-	if stream != nil && h.isBDMVFile(stream.URL) {
-		h.services.Logger.Infof("[%s] largest file is BDMV (%s), skipping entire torrent: %s", torrent.Source, stream.URL, torrent.Title)
+	if !found {
+		return nil
+	}
+
+	// Check if the selected file is a BDMV file
+	file := fileAt(magnet.Links, index)
+	if filename, _ := file["filename"].(string); h.isBDMVFile(filename) {
+		h.log(ctx).Infof("[%s] largest file is BDMV (%s), skipping entire torrent: %s", torrent.Source, filename, torrent.Title)
 		return nil // This will cause the sequential processor to try the next torrent
 	}
-	
-	return stream
+
+	if isSeasonPack && targetEpisode > 0 {
+		h.prefetch.rememberSeasonPack(account, magnet, torrent)
+	}
+
+	token := playToken{Hash: magnet.Hash, File: index, Season: targetSeason, Episode: targetEpisode, Absolute: absoluteEpisodeFrom(ctx)}
+	return h.createStreamFromFile(file, token, magnet.ID, torrent)
 }
 
-func (h *Handler) processEpisodeFromMagnet(magnet *models.ProcessedMagnet, torrent models.TorrentInfo, targetSeason, targetEpisode int, isSeasonPack bool, apiKey string) *models.Stream {
+func (h *Handler) selectEpisodeFile(ctx context.Context, magnet *models.ProcessedMagnet, torrent models.TorrentInfo, targetSeason, targetEpisode int, isSeasonPack bool) (int, bool) {
 	if isSeasonPack {
-		h.services.Logger.Infof("[%s] processing season pack for specific episode s%02de%02d", torrent.Source, targetSeason, targetEpisode)
+		h.log(ctx).Infof("[%s] processing season pack for specific episode s%02de%02d", torrent.Source, targetSeason, targetEpisode)
 	} else {
-		h.services.Logger.Infof("[%s] processing episode torrent for s%02de%02d", torrent.Source, targetSeason, targetEpisode)
+		h.log(ctx).Infof("[%s] processing episode torrent for s%02de%02d", torrent.Source, targetSeason, targetEpisode)
 	}
 
-	if file, found := h.findEpisodeFile(magnet.Links, targetSeason, targetEpisode); found {
-		h.services.Logger.Infof("[%s] found target episode file", torrent.Source)
-		return h.createStreamFromFile(file, torrent, apiKey)
+	if index, found := findEpisodeFile(magnet.Links, episodeTarget(targetSeason, targetEpisode, absoluteEpisodeFrom(ctx))); found {
+		h.log(ctx).Infof("[%s] found target episode file", torrent.Source)
+		return index, true
 	}
 
 	if isSeasonPack {
-		h.services.Logger.Warnf("[%s] target episode s%02de%02d not found in season pack, using largest file", torrent.Source, targetSeason, targetEpisode)
-		return h.processLargestFile(magnet, torrent, targetSeason, targetEpisode, isSeasonPack, apiKey)
+		h.log(ctx).Warnf("[%s] target episode s%02de%02d not found in season pack, skipping torrent", torrent.Source, targetSeason, targetEpisode)
+	} else {
+		h.log(ctx).Warnf("[%s] target episode s%02de%02d not found in episode torrent", torrent.Source, targetSeason, targetEpisode)
 	}
-
-	h.services.Logger.Warnf("[%s] target episode s%02de%02d not found in episode torrent", torrent.Source, targetSeason, targetEpisode)
-	return nil
+	return 0, false
 }
 
-func (h *Handler) processLargestFile(magnet *models.ProcessedMagnet, torrent models.TorrentInfo, targetSeason, targetEpisode int, isSeasonPack bool, apiKey string) *models.Stream {
+func (h *Handler) selectLargestFile(ctx context.Context, magnet *models.ProcessedMagnet, torrent models.TorrentInfo, targetSeason, targetEpisode int, isSeasonPack bool) (int, bool) {
 	if targetSeason > 0 && targetEpisode == 0 && isSeasonPack {
-		h.services.Logger.Infof("[%s] processing complete season pack for season %d", torrent.Source, targetSeason)
+		h.log(ctx).Infof("[%s] processing complete season pack for season %d", torrent.Source, targetSeason)
 	} else if targetSeason == 0 && targetEpisode == 0 {
-		h.services.Logger.Infof("[%s] processing movie torrent, finding largest file", torrent.Source)
+		h.log(ctx).Infof("[%s] processing movie torrent, finding largest file", torrent.Source)
 	} else {
-		h.services.Logger.Infof("[%s] using largest file as fallback", torrent.Source)
+		h.log(ctx).Infof("[%s] using largest file as fallback", torrent.Source)
 	}
 
-	if file, found := findLargestFile(magnet.Links); found {
-		return h.createStreamFromFile(file, torrent, apiKey)
+	if index, found := findLargestFile(magnet.Links); found {
+		return index, true
 	}
 
-	h.services.Logger.Warnf("[%s] no valid files found in magnet", torrent.Source)
-	return nil
+	h.log(ctx).Warnf("[%s] no valid files found in magnet", torrent.Source)
+	return 0, false
 }
 
 func (h *Handler) isSeasonPack(title string) bool {
 	titleLower := strings.ToLower(title)
 	return strings.Contains(titleLower, "complete") ||
 		strings.Contains(titleLower, "season") ||
-		strings.Contains(titleLower, "saison")
+		strings.Contains(titleLower, "saison") ||
+		tsutils.MatchesCompleteSeries(title, 0)
 }
 
 // isBDMVFile checks if a URL points to a Blu-ray disc structure file
@@ -736,6 +1144,19 @@ func formatFileInfoString(linkObj map[string]interface{}) string {
 	return info
 }
 
+// formatSwarmInfoString describes the swarm health and age of a torrent, e.g. "👤 142 • ⬇️ 12 • 📅 2024-03-14".
+// Torrents from providers reporting neither seeders nor an upload date yield an empty string.
+func formatSwarmInfoString(torrent models.TorrentInfo) string {
+	var parts []string
+	if torrent.Seeders > 0 || torrent.Leechers > 0 {
+		parts = append(parts, fmt.Sprintf("👤 %d", torrent.Seeders), fmt.Sprintf("⬇️ %d", torrent.Leechers))
+	}
+	if !torrent.UploadDate.IsZero() {
+		parts = append(parts, "📅 "+torrent.UploadDate.Format("2006-01-02"))
+	}
+	return strings.Join(parts, " • ")
+}
+
 func extractMediaIdentifiers(id string) (string, int, int) {
 	id = strings.TrimSuffix(id, ".json")
 
@@ -749,6 +1170,11 @@ func extractMediaIdentifiers(id string) (string, int, int) {
 		return mediaID, season, episode
 	}
 
+	// Try Kitsu and MyAnimeList formats; the season comes from the anime mapping
+	if mediaID, episode, ok := parseAnimeFormat(id); ok {
+		return mediaID, 0, episode
+	}
+
 	// Check if it's a movie format
 	if isMovieFormat(id) {
 		return id, 0, 0
@@ -766,119 +1192,110 @@ func (h *Handler) matchesYear(title string, year int) bool {
 	return strings.Contains(title, yearStr)
 }
 
-func (h *Handler) extractSeasonEpisodeFromFilename(filename string) (int, int) {
-	patterns := []string{
-		`[sS](\d{1,2})[eE](\d{1,2})`,
-		`[sS](\d{1,2})\.?[eE](\d{1,2})`,
-		`(\d{1,2})x(\d{1,2})`,
-		`[sS]eason\s*(\d{1,2})\s*[eE]pisode\s*(\d{1,2})`,
-	}
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		if matches := re.FindStringSubmatch(filename); len(matches) >= 3 {
-			season, err1 := strconv.Atoi(matches[1])
-			episode, err2 := strconv.Atoi(matches[2])
-			if err1 == nil && err2 == nil {
-				return season, episode
-			}
-		}
-	}
-
-	return 0, 0
-}
-
-func aggregateResults(results *models.TorrentResults, combined *models.CombinedTorrentResults, mu *sync.Mutex) {
-	if results == nil {
-		return
+// fileAt returns the file of the magnet links at index, or nil
+func fileAt(links []interface{}, index int) map[string]interface{} {
+	if index < 0 || index >= len(links) {
+		return nil
 	}
-
-	mu.Lock()
-	defer mu.Unlock()
-	
-	combined.MovieTorrents = append(combined.MovieTorrents, results.MovieTorrents...)
-	combined.CompleteSeriesTorrents = append(combined.CompleteSeriesTorrents, results.CompleteSeriesTorrents...)
-	combined.CompleteSeasonTorrents = append(combined.CompleteSeasonTorrents, results.CompleteSeasonTorrents...)
-	combined.EpisodeTorrents = append(combined.EpisodeTorrents, results.EpisodeTorrents...)
+	file, _ := links[index].(map[string]interface{})
+	return file
 }
 
-func findLargestFile(links []interface{}) (map[string]interface{}, bool) {
-	var largestFile map[string]interface{}
+// findLargestFile returns the index of the largest file of the magnet links
+func findLargestFile(links []interface{}) (int, bool) {
+	largestIndex := -1
 	var largestSize float64
 
-	for _, link := range links {
-		if linkObj, ok := link.(map[string]interface{}); ok {
-			if size, ok := linkObj["size"].(float64); ok {
-				if size > largestSize {
-					largestSize = size
-					largestFile = linkObj
-				}
+	for i := range links {
+		if size, ok := fileAt(links, i)["size"].(float64); ok {
+			if size > largestSize {
+				largestSize = size
+				largestIndex = i
 			}
 		}
 	}
 
-	return largestFile, largestFile != nil
+	return largestIndex, largestIndex >= 0
 }
 
-func (h *Handler) findEpisodeFile(links []interface{}, targetSeason, targetEpisode int) (map[string]interface{}, bool) {
-	matchingFiles := h.findMatchingEpisodeFiles(links, targetSeason, targetEpisode)
-	if len(matchingFiles) == 0 {
-		return nil, false
-	}
+// findEpisodeFile returns the index of the file holding the target episode, refusing to guess
+func findEpisodeFile(links []interface{}, target filematch.Target) (int, bool) {
+	return filematch.Match(linkFiles(links), target)
+}
 
-	return h.selectLargestMatchingFile(matchingFiles)
+// episodeTarget returns the episode to match. Files numbered absolutely match the absolute
+// episode of anime requests, or the first season episodes of other content.
+func episodeTarget(season, episode, absolute int) filematch.Target {
+	target := filematch.Target{Season: season, Episode: episode, Absolute: absolute}
+	if absolute == 0 && season == 1 {
+		target.Absolute = episode
+	}
+	return target
 }
 
-func (h *Handler) findMatchingEpisodeFiles(links []interface{}, targetSeason, targetEpisode int) []map[string]interface{} {
-	var matchingFiles []map[string]interface{}
+// absoluteEpisodeKey is the context key of the absolute episode number of an anime request
+type absoluteEpisodeKey struct{}
 
-	for _, link := range links {
-		if linkObj, ok := link.(map[string]interface{}); ok {
-			if filename, ok := linkObj["filename"].(string); ok {
-				season, episode := h.extractSeasonEpisodeFromFilename(filename)
-				if season == targetSeason && episode == targetEpisode {
-					matchingFiles = append(matchingFiles, linkObj)
-				}
-			}
-		}
+// withAbsoluteEpisode returns a context carrying the episode number anime releases use
+func withAbsoluteEpisode(ctx context.Context, episode int) context.Context {
+	if episode <= 0 {
+		return ctx
 	}
-
-	return matchingFiles
+	return context.WithValue(ctx, absoluteEpisodeKey{}, episode)
 }
 
-func (h *Handler) selectLargestMatchingFile(matchingFiles []map[string]interface{}) (map[string]interface{}, bool) {
-	var largestFile map[string]interface{}
-	var largestSize float64
+// absoluteEpisodeFrom returns the absolute episode number of the request served with ctx, 0 when unknown
+func absoluteEpisodeFrom(ctx context.Context) int {
+	episode, _ := ctx.Value(absoluteEpisodeKey{}).(int)
+	return episode
+}
 
-	for _, file := range matchingFiles {
-		if size, ok := file["size"].(float64); ok {
-			if size > largestSize {
-				largestSize = size
-				largestFile = file
-			}
-		}
+// linkFiles converts magnet links to files for matching, keeping their indexes
+func linkFiles(links []interface{}) []filematch.File {
+	files := make([]filematch.File, len(links))
+	for i := range links {
+		files[i] = linkFile(fileAt(links, i))
 	}
-
-	return largestFile, largestFile != nil
+	return files
 }
 
-func (h *Handler) createStreamFromFile(file map[string]interface{}, torrent models.TorrentInfo, apiKey string) *models.Stream {
-	linkStr, ok := file["link"].(string)
-	if !ok {
-		return nil
+// linkFile returns the path inside the torrent and the size of a magnet link
+func linkFile(file map[string]interface{}) filematch.File {
+	filePath, _ := file["path"].(string)
+	if filePath == "" {
+		filePath, _ = file["filename"].(string)
 	}
+	size, _ := file["size"].(float64)
+	return filematch.File{Path: filePath, Size: int64(size)}
+}
 
-	directURL, err := h.services.AllDebrid.UnlockLink(linkStr, apiKey)
-	if err != nil {
-		h.services.Logger.Errorf("[AllDebrid] failed to unlock link: %v", err)
+// createStreamFromFile builds a stream pointing at the play route, which unlocks the file only when it is opened
+func (h *Handler) createStreamFromFile(file map[string]interface{}, token playToken, magnetID string, torrent models.TorrentInfo) *models.Stream {
+	link, ok := file["link"].(string)
+	if !ok {
 		return nil
 	}
+	filename, _ := file["filename"].(string)
 
+	token.Magnet = magnetID
 	streamTitle := fmt.Sprintf("%s\n%s", torrent.Title, formatFileInfoString(file))
+	if swarm := formatSwarmInfoString(torrent); swarm != "" {
+		streamTitle += "\n" + swarm
+	}
 	return &models.Stream{
-		Name:  torrent.Source,
-		Title: streamTitle,
-		URL:   directURL,
+		Name:          torrent.Source,
+		Title:         streamTitle,
+		URL:           h.playPath(token),
+		BehaviorHints: streamBehaviorHints(file, torrent),
+		File: &models.StreamFile{
+			Hash:     token.Hash,
+			MagnetID: magnetID,
+			Index:    token.File,
+			Name:     filename,
+			Link:     link,
+			Season:   token.Season,
+			Episode:  token.Episode,
+			Absolute: token.Absolute,
+		},
 	}
 }
-
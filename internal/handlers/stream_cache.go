@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/amaumene/gostremiofr/internal/database"
+	"github.com/amaumene/gostremiofr/internal/models"
+)
+
+// streamCacheTTL returns how long resolved streams are kept, 0 when the stream cache is off
+func (h *Handler) streamCacheTTL() time.Duration {
+	if h.services.DB == nil || h.config == nil {
+		return 0
+	}
+	return time.Duration(h.config.StreamCacheHours) * time.Hour
+}
+
+// cachedStreams answers a stream request from the stream cache. A single status call checks
+// that the debrid account still has the cached files; streams whose file is gone are dropped.
+func (h *Handler) cachedStreams(ctx context.Context, req *streamRequest) ([]models.Stream, bool) {
+	if h.streamCacheTTL() == 0 {
+		return nil, false
+	}
+
+	key := streamCacheKey(req.id, req.season, req.episode, req.config)
+	entry, err := h.services.DB.GetStreamCache(key)
+	if err != nil {
+		h.log(ctx).Warnf("[cache] failed to read stream cache: %v", err)
+		return nil, false
+	}
+	if entry == nil || len(entry.Streams) == 0 {
+		return nil, false
+	}
+
+	magnets, err := req.account.Provider.CheckMagnets(ctx, cachedMagnetInfos(entry.Streams), req.account.APIKey)
+	if err != nil {
+		h.log(ctx).Warnf("[%s] failed to revalidate cached streams: %v", req.account.Provider.Name(), err)
+		return nil, false
+	}
+
+	magnetsByHash := make(map[string]*models.ProcessedMagnet, len(magnets))
+	for i := range magnets {
+		magnetsByHash[strings.ToLower(magnets[i].Hash)] = &magnets[i]
+	}
+
+	var streams []models.Stream
+	for _, cached := range entry.Streams {
+		magnet, ok := magnetsByHash[strings.ToLower(cached.Hash)]
+		if !ok || !magnet.Ready {
+			continue
+		}
+		file := fileAt(magnet.Links, cached.FileIndex)
+		link, ok := file["link"].(string)
+		if filename, _ := file["filename"].(string); !ok || filename != cached.FileName {
+			continue
+		}
+		streams = append(streams, h.streamFromCache(cached, link))
+	}
+
+	if len(streams) == 0 {
+		h.log(ctx).Infof("[cache] cached streams of %s are no longer available", req.id)
+		if err := h.services.DB.DeleteStreamCache(key); err != nil {
+			h.log(ctx).Warnf("[cache] %v", err)
+		}
+		return nil, false
+	}
+
+	h.log(ctx).Infof("[cache] serving %d cached streams for %s", len(streams), req.id)
+	req.title, req.originalLanguage = entry.Title, entry.OriginalLanguage
+	return streams, true
+}
+
+// storeStreamCache records the files behind the streams returned for a request
+func (h *Handler) storeStreamCache(ctx context.Context, req *streamRequest, streams []models.Stream) {
+	ttl := h.streamCacheTTL()
+	if ttl == 0 || len(streams) == 0 {
+		return
+	}
+
+	entry := &database.StreamCache{
+		Key:              streamCacheKey(req.id, req.season, req.episode, req.config),
+		Account:          req.account.Fingerprint(),
+		ExpiresAt:        time.Now().Add(ttl),
+		Title:            req.title,
+		OriginalLanguage: req.originalLanguage,
+	}
+	for _, stream := range streams {
+		if stream.File != nil {
+			cached := cachedStream(stream)
+			cached.Account = req.account.MagnetOwner(stream.File.MagnetID)
+			entry.Streams = append(entry.Streams, cached)
+		}
+	}
+	if len(entry.Streams) == 0 {
+		return
+	}
+
+	if err := h.services.DB.StoreStreamCache(entry); err != nil {
+		h.log(ctx).Warnf("[cache] %v", err)
+	}
+}
+
+// streamFromCache rebuilds a stream from its cache entry and the current debrid file link
+func (h *Handler) streamFromCache(cached database.CachedStream, link string) models.Stream {
+	token := playToken{Hash: cached.Hash, File: cached.FileIndex, Season: cached.Season, Episode: cached.Episode, Absolute: cached.Absolute, Magnet: cached.MagnetID}
+	return models.Stream{
+		Name:  cached.Name,
+		Title: cached.Title,
+		URL:   h.playPath(token),
+		BehaviorHints: &models.StreamBehaviorHints{
+			BingeGroup:  cached.BingeGroup,
+			Filename:    cached.FileName,
+			VideoSize:   cached.VideoSize,
+			NotWebReady: cached.NotWebReady,
+		},
+		File: &models.StreamFile{
+			Hash:     cached.Hash,
+			MagnetID: cached.MagnetID,
+			Index:    cached.FileIndex,
+			Name:     cached.FileName,
+			Link:     link,
+			Season:   cached.Season,
+			Episode:  cached.Episode,
+			Absolute: cached.Absolute,
+		},
+	}
+}
+
+// cachedStream converts a stream with a known debrid file to its cache representation
+func cachedStream(stream models.Stream) database.CachedStream {
+	cached := database.CachedStream{
+		Hash:      stream.File.Hash,
+		MagnetID:  stream.File.MagnetID,
+		FileIndex: stream.File.Index,
+		FileName:  stream.File.Name,
+		Link:      stream.File.Link,
+		Season:    stream.File.Season,
+		Episode:   stream.File.Episode,
+		Absolute:  stream.File.Absolute,
+		Name:      stream.Name,
+		Title:     stream.Title,
+	}
+	if hints := stream.BehaviorHints; hints != nil {
+		cached.BingeGroup = hints.BingeGroup
+		cached.VideoSize = hints.VideoSize
+		cached.NotWebReady = hints.NotWebReady
+	}
+	return cached
+}
+
+// cachedMagnetInfos returns the magnets to revalidate, once per hash
+func cachedMagnetInfos(streams []database.CachedStream) []models.MagnetInfo {
+	var infos []models.MagnetInfo
+	seen := make(map[string]bool, len(streams))
+	for _, cached := range streams {
+		hash := strings.ToLower(cached.Hash)
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		infos = append(infos, models.MagnetInfo{Hash: cached.Hash, Title: cached.FileName, ID: cached.MagnetID})
+	}
+	return infos
+}
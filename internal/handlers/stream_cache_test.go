@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amaumene/gostremiofr/internal/config"
+	"github.com/amaumene/gostremiofr/internal/database"
+	"github.com/amaumene/gostremiofr/internal/models"
+	"github.com/amaumene/gostremiofr/internal/services"
+)
+
+// newCachingHandler returns a test handler with a stream cache and a request served by provider
+func newCachingHandler(t *testing.T, provider *fakeDebrid) (*Handler, *streamRequest) {
+	t.Helper()
+	db, err := database.NewBolt(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewBolt: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := newTestHandler()
+	h.services.DB = db
+	h.config = &config.Config{StreamCacheHours: 4, MaxStreams: 5}
+	req := &streamRequest{
+		id:      "tt0000001",
+		season:  1,
+		episode: 2,
+		account: &services.DebridAccount{ProviderID: "fake", Provider: provider, APIKey: "key"},
+		config:  h.config,
+	}
+	return h, req
+}
+
+// cacheTestStreams stores a stream for each file of the magnet m1 of hash abc
+func cacheTestStreams(h *Handler, req *streamRequest, links []interface{}) {
+	var streams []models.Stream
+	for i := range links {
+		file := fileAt(links, i)
+		name := file["filename"].(string)
+		streams = append(streams, models.Stream{
+			Name:          "Fake",
+			Title:         name,
+			URL:           "play/stale",
+			BehaviorHints: &models.StreamBehaviorHints{Filename: name},
+			File:          &models.StreamFile{Hash: "abc", MagnetID: "m1", Index: i, Name: name, Link: file["link"].(string), Season: 1, Episode: 2},
+		})
+	}
+	req.title, req.originalLanguage = "Show", "en"
+	h.storeStreamCache(context.Background(), req, streams)
+	req.title, req.originalLanguage = "", ""
+}
+
+func TestCachedStreamsRevalidation(t *testing.T) {
+	links := episodeLinks("Show.S01E02.1080p.mkv", "Show.S01E02.720p.mkv")
+	tests := []struct {
+		name      string
+		current   []interface{} // files the debrid account lists now, nil when the magnet is gone
+		ready     bool
+		err       error
+		want      []string // filenames of the served streams
+		wantEntry bool     // entry still cached afterwards
+	}{
+		{name: "unchanged", current: links, ready: true, want: []string{"Show.S01E02.1080p.mkv", "Show.S01E02.720p.mkv"}, wantEntry: true},
+		{name: "file gone", current: episodeLinks("Show.S01E02.1080p.mkv"), ready: true, want: []string{"Show.S01E02.1080p.mkv"}, wantEntry: true},
+		{name: "files reordered", current: episodeLinks("Show.S01E02.720p.mkv", "Show.S01E02.1080p.mkv"), ready: true, wantEntry: false},
+		{name: "magnet not ready", current: links, ready: false, wantEntry: false},
+		{name: "magnet gone", wantEntry: false},
+		{name: "provider error", current: links, ready: true, err: errors.New("timeout"), wantEntry: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeDebrid{}
+			h, req := newCachingHandler(t, provider)
+			cacheTestStreams(h, req, links)
+
+			if tt.current != nil {
+				provider.magnets = map[string]models.ProcessedMagnet{"m1": {ID: "m1", Hash: "ABC", Ready: tt.ready, Links: tt.current}}
+			}
+			provider.err = tt.err
+
+			streams, found := h.cachedStreams(context.Background(), req)
+			if found != (len(tt.want) > 0) {
+				t.Fatalf("cachedStreams found = %v, want %v", found, len(tt.want) > 0)
+			}
+			var names []string
+			for _, stream := range streams {
+				names = append(names, stream.BehaviorHints.Filename)
+				if !strings.HasPrefix(stream.URL, playPathPrefix) || stream.URL == "play/stale" {
+					t.Errorf("stream URL %q is not a fresh play path", stream.URL)
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("served %v, want %v", names, tt.want)
+			}
+			if provider.checks != 1 {
+				t.Errorf("CheckMagnets called %d times, want 1", provider.checks)
+			}
+			if found && (req.title != "Show" || req.originalLanguage != "en") {
+				t.Errorf("request title %q and language %q not restored from the cache", req.title, req.originalLanguage)
+			}
+
+			entry, err := h.services.DB.GetStreamCache(streamCacheKey(req.id, req.season, req.episode, req.config))
+			if err != nil {
+				t.Fatalf("GetStreamCache: %v", err)
+			}
+			if (entry != nil) != tt.wantEntry {
+				t.Errorf("entry cached = %v, want %v", entry != nil, tt.wantEntry)
+			}
+		})
+	}
+}
+
+func TestCachedStreamsTokenReusesMagnet(t *testing.T) {
+	links := episodeLinks("Show.S01E02.mkv")
+	provider := &fakeDebrid{}
+	h, req := newCachingHandler(t, provider)
+	cacheTestStreams(h, req, links)
+	provider.magnets = map[string]models.ProcessedMagnet{"m1": {ID: "m1", Hash: "abc", Ready: true, Links: links}}
+
+	streams, found := h.cachedStreams(context.Background(), req)
+	if !found {
+		t.Fatal("cached streams not found")
+	}
+	token, err := h.parsePlayToken(strings.TrimPrefix(streams[0].URL, playPathPrefix))
+	if err != nil {
+		t.Fatalf("parsePlayToken: %v", err)
+	}
+	if want := (playToken{Hash: "abc", File: 0, Season: 1, Episode: 2, Magnet: "m1"}); token != want {
+		t.Errorf("token = %+v, want %+v", token, want)
+	}
+}
+
+func TestStreamCacheDisabled(t *testing.T) {
+	provider := &fakeDebrid{}
+	h, req := newCachingHandler(t, provider)
+	h.config.StreamCacheHours = 0
+	cacheTestStreams(h, req, episodeLinks("Show.S01E02.mkv"))
+
+	if _, found := h.cachedStreams(context.Background(), req); found {
+		t.Error("streams served with the stream cache disabled")
+	}
+	if provider.checks != 0 {
+		t.Errorf("CheckMagnets called %d times, want 0", provider.checks)
+	}
+}
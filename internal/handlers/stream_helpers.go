@@ -2,10 +2,40 @@ package handlers
 
 import (
 	"fmt"
+	"path"
+	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/amaumene/gostremiofr/internal/constants"
+	"github.com/amaumene/gostremiofr/internal/models"
+	"github.com/cehbz/torrentname"
 )
 
+// languageTagRegex matches the French release tags that torrentname does not recognise
+var languageTagRegex = regexp.MustCompile(`(?i)\b(MULTI|TRUEFRENCH|VFF|VFQ|VF2|VFI|VF|FRENCH|VOSTFR|SUBFRENCH)\b`)
+
+// languageTags maps release tags to the values accepted in LANG_TO_SHOW
+var languageTags = map[string][]string{
+	"multi":      {"multi"},
+	"truefrench": {"vff"},
+	"vff":        {"vff"},
+	"vfq":        {"vfq"},
+	"vf2":        {"vff", "vfq"},
+	"vfi":        {"french"},
+	"vf":         {"french"},
+	"french":     {"french"},
+	"vostfr":     {"vostfr"},
+	"subfrench":  {"vostfr"},
+}
+
+// webReadyExtensions are the containers the Stremio web player can play without transcoding
+var webReadyExtensions = map[string]bool{
+	".mp4":  true,
+	".m4v":  true,
+	".webm": true,
+}
+
 func parseIMDBEpisodeFormat(id string) (string, int, int, bool) {
 	if !episodeRegex.MatchString(id) {
 		return "", 0, 0, false
@@ -38,6 +68,70 @@ func parseTMDBEpisodeFormat(id string) (string, int, int, bool) {
 	return tmdbID, season, episode, true
 }
 
+// parseAnimeFormat parses "kitsu:<id>[:<episode>]" and "mal:<id>[:<episode>]" IDs
+func parseAnimeFormat(id string) (string, int, bool) {
+	matches := animeIDRegex.FindStringSubmatch(id)
+	if matches == nil {
+		return "", 0, false
+	}
+
+	episode, _ := strconv.Atoi(matches[3])
+	return matches[1] + ":" + matches[2], episode, true
+}
+
+// isAnimeID reports whether the ID is a Kitsu or MyAnimeList ID
+func isAnimeID(id string) bool {
+	return strings.HasPrefix(id, "kitsu:") || strings.HasPrefix(id, "mal:")
+}
+
 func isMovieFormat(id string) bool {
 	return imdbIDRegex.MatchString(id) || tmdbIDRegex.MatchString(id)
-}
\ No newline at end of file
+}
+
+// torrentLanguages returns the normalized language tags of a torrent title
+// (multi, vff, vfq, french, vostfr, or the language detected by torrentname)
+func torrentLanguages(title string) []string {
+	seen := make(map[string]bool)
+	var languages []string
+	add := func(lang string) {
+		if !seen[lang] {
+			seen[lang] = true
+			languages = append(languages, lang)
+		}
+	}
+
+	for _, match := range languageTagRegex.FindAllString(title, -1) {
+		for _, lang := range languageTags[strings.ToLower(match)] {
+			add(lang)
+		}
+	}
+
+	if parsed := torrentname.Parse(title); parsed != nil && parsed.Language != "" {
+		add(strings.ToLower(parsed.Language))
+	}
+
+	return languages
+}
+
+// streamBehaviorHints builds the Stremio behavior hints of a stream from the debrid file metadata.
+func streamBehaviorHints(file map[string]interface{}, torrent models.TorrentInfo) *models.StreamBehaviorHints {
+	hints := &models.StreamBehaviorHints{BingeGroup: bingeGroup(torrent)}
+	if filename, ok := file["filename"].(string); ok && filename != "" {
+		hints.Filename = filename
+		hints.NotWebReady = !webReadyExtensions[strings.ToLower(path.Ext(filename))]
+	}
+	if size, ok := file["size"].(float64); ok {
+		hints.VideoSize = int64(size)
+	}
+	return hints
+}
+
+// bingeGroup identifies the release of a torrent by provider, resolution and release group,
+// so that Stremio autoplays the next episode from the same release.
+func bingeGroup(torrent models.TorrentInfo) string {
+	releaseGroup := "unknown"
+	if parsed := torrentname.Parse(torrent.Title); parsed != nil && parsed.ReleaseGroup != "" {
+		releaseGroup = strings.ToLower(parsed.ReleaseGroup)
+	}
+	return strings.Join([]string{constants.AddonID, strings.ToLower(torrent.Source), torrentResolution(torrent.Title), releaseGroup}, "|")
+}
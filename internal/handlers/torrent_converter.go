@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	
 	"github.com/amaumene/gostremiofr/internal/models"
@@ -8,26 +9,27 @@ import (
 )
 
 // convertTorrentSearchResults converts torrentsearch results to internal format
-func (h *Handler) convertTorrentSearchResults(results *tsmodels.CombinedSearchResults) *models.CombinedTorrentResults {
+func (h *Handler) convertTorrentSearchResults(ctx context.Context, results *tsmodels.CombinedSearchResults) *models.CombinedTorrentResults {
 	combined := &models.CombinedTorrentResults{}
 	
 	// Convert results from all providers
 	for provider, providerResults := range results.Results {
-		h.services.Logger.Debugf("[%s] converting %d results", provider,
+		h.log(ctx).Debugf("[%s] converting %d results", provider,
 			len(providerResults.MovieTorrents)+len(providerResults.CompleteSeriesTorrents)+
 			len(providerResults.CompleteSeasonTorrents)+len(providerResults.EpisodeTorrents))
 		
 		// Combine all results into the main categories
-		combined.MovieTorrents = append(combined.MovieTorrents, h.convertTorrentInfoList(providerResults.MovieTorrents, provider)...)
-		combined.CompleteSeriesTorrents = append(combined.CompleteSeriesTorrents, h.convertTorrentInfoList(providerResults.CompleteSeriesTorrents, provider)...)
-		combined.CompleteSeasonTorrents = append(combined.CompleteSeasonTorrents, h.convertTorrentInfoList(providerResults.CompleteSeasonTorrents, provider)...)
-		combined.EpisodeTorrents = append(combined.EpisodeTorrents, h.convertTorrentInfoList(providerResults.EpisodeTorrents, provider)...)
+		weight := results.Weights[provider]
+		combined.MovieTorrents = append(combined.MovieTorrents, h.convertTorrentInfoList(ctx, providerResults.MovieTorrents, provider, weight)...)
+		combined.CompleteSeriesTorrents = append(combined.CompleteSeriesTorrents, h.convertTorrentInfoList(ctx, providerResults.CompleteSeriesTorrents, provider, weight)...)
+		combined.CompleteSeasonTorrents = append(combined.CompleteSeasonTorrents, h.convertTorrentInfoList(ctx, providerResults.CompleteSeasonTorrents, provider, weight)...)
+		combined.EpisodeTorrents = append(combined.EpisodeTorrents, h.convertTorrentInfoList(ctx, providerResults.EpisodeTorrents, provider, weight)...)
 	}
 	
 	return combined
 }
 
-func (h *Handler) convertTorrentInfoList(torrents []tsmodels.TorrentInfo, provider string) []models.TorrentInfo {
+func (h *Handler) convertTorrentInfoList(ctx context.Context, torrents []tsmodels.TorrentInfo, provider string, weight float64) []models.TorrentInfo {
 	var result []models.TorrentInfo
 	for i, t := range torrents {
 		// Log confidence score in debug mode
@@ -41,10 +43,10 @@ func (h *Handler) convertTorrentInfoList(torrents []tsmodels.TorrentInfo, provid
 					t.ParsedInfo.Source,
 					t.ParsedInfo.Codec)
 			}
-			h.services.Logger.Debugf("[%s] torrent %d: %.0f%% confidence - %s%s", 
+			h.log(ctx).Debugf("[%s] torrent %d: %.0f%% confidence - %s%s", 
 				provider, i+1, t.ConfidenceScore, t.Title, details)
 		} else {
-			h.services.Logger.Debugf("[%s] torrent %d: no confidence score - %s", provider, i+1, t.Title)
+			h.log(ctx).Debugf("[%s] torrent %d: no confidence score - %s", provider, i+1, t.Title)
 		}
 		
 		result = append(result, models.TorrentInfo{
@@ -53,7 +55,11 @@ func (h *Handler) convertTorrentInfoList(torrents []tsmodels.TorrentInfo, provid
 			Hash:            t.Hash,
 			Source:          provider,
 			Size:            t.Size,
+			Seeders:         t.Seeders,
+			Leechers:        t.Leechers,
+			UploadDate:      t.UploadDate,
 			ConfidenceScore: t.ConfidenceScore,
+			Weight:          weight,
 		})
 	}
 	return result
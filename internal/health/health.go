@@ -0,0 +1,101 @@
+// Package health tracks the state of upstream services reported on /status.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/amaumene/gostremiofr/pkg/logger"
+)
+
+// Upstream kinds reported on /status
+const (
+	KindProvider = "providers"
+	KindTMDB     = "tmdb"
+	KindDebrid   = "debrid"
+)
+
+// started is the process start time reported as uptime
+var started = time.Now()
+
+// Upstreams records the outcome of every upstream call.
+var Upstreams = NewTracker()
+
+// UpstreamStatus is the last known state of an upstream service.
+type UpstreamStatus struct {
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastFailure *time.Time `json:"last_failure,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	Healthy     bool       `json:"healthy"` // the last call succeeded
+}
+
+// Tracker keeps the last success and failure of upstream services by kind and name.
+type Tracker struct {
+	mu        sync.RWMutex
+	upstreams map[string]map[string]*UpstreamStatus
+}
+
+// NewTracker creates an empty tracker.
+func NewTracker() *Tracker {
+	return &Tracker{upstreams: make(map[string]map[string]*UpstreamStatus)}
+}
+
+// Record stores the outcome of a call to an upstream. Cancelled calls say nothing about
+// the upstream and are ignored; error messages are stored with their secrets redacted.
+func (t *Tracker) Record(kind, name string, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	now := time.Now().UTC()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	byName, ok := t.upstreams[kind]
+	if !ok {
+		byName = make(map[string]*UpstreamStatus)
+		t.upstreams[kind] = byName
+	}
+	status, ok := byName[name]
+	if !ok {
+		status = &UpstreamStatus{}
+		byName[name] = status
+	}
+
+	if err != nil {
+		status.LastFailure = &now
+		status.LastError = logger.Redact(err.Error())
+		status.Healthy = false
+		return
+	}
+	status.LastSuccess = &now
+	status.Healthy = true
+}
+
+// Snapshot returns a copy of the upstream states by kind and name.
+func (t *Tracker) Snapshot() map[string]map[string]UpstreamStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	snapshot := make(map[string]map[string]UpstreamStatus, len(t.upstreams))
+	for kind, byName := range t.upstreams {
+		copied := make(map[string]UpstreamStatus, len(byName))
+		for name, status := range byName {
+			copied[name] = *status
+		}
+		snapshot[kind] = copied
+	}
+	return snapshot
+}
+
+// StartedAt returns the process start time.
+func StartedAt() time.Time {
+	return started
+}
+
+// Uptime returns the time elapsed since the process started.
+func Uptime() time.Duration {
+	return time.Since(started)
+}
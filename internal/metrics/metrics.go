@@ -0,0 +1,106 @@
+// Package metrics defines the application metrics served on /metrics.
+package metrics
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/amaumene/gostremiofr/pkg/metrics"
+)
+
+// Registry holds every application metric.
+var Registry = metrics.NewRegistry()
+
+// rateLimiterBuckets cover waits from none to the request timeout
+var rateLimiterBuckets = []float64{0.001, 0.01, 0.1, 0.25, 0.5, 1, 2.5, 5, 15, 30}
+
+var (
+	// ProviderSearchDuration is the latency of torrent provider searches
+	ProviderSearchDuration = Registry.NewHistogram("gostremiofr_provider_search_duration_seconds",
+		"Duration of torrent provider searches.", nil, "provider")
+	// ProviderSearchErrors counts failed torrent provider searches
+	ProviderSearchErrors = Registry.NewCounter("gostremiofr_provider_search_errors_total",
+		"Torrent provider searches that failed.", "provider")
+	// ProviderTorrents counts the torrents returned by torrent providers
+	ProviderTorrents = Registry.NewCounter("gostremiofr_provider_torrents_total",
+		"Torrents returned by torrent provider searches.", "provider")
+
+	// TMDBRequests counts TMDB API calls by endpoint and HTTP status ("error" when no response)
+	TMDBRequests = Registry.NewCounter("gostremiofr_tmdb_requests_total",
+		"TMDB API requests by endpoint and status.", "endpoint", "status")
+	// TMDBRequestDuration is the latency of TMDB API calls
+	TMDBRequestDuration = Registry.NewHistogram("gostremiofr_tmdb_request_duration_seconds",
+		"Duration of TMDB API requests.", nil, "endpoint")
+
+	// RateLimiterWait is the time spent waiting for rate limiter tokens
+	RateLimiterWait = Registry.NewHistogram("gostremiofr_rate_limiter_wait_seconds",
+		"Time spent waiting for a rate limiter token.", rateLimiterBuckets, "limiter")
+
+	// DebridRequests counts debrid calls by operation and error code ("ok" on success)
+	DebridRequests = Registry.NewCounter("gostremiofr_debrid_requests_total",
+		"Debrid API calls by provider, operation and error code.", "provider", "operation", "code")
+	// DebridRequestDuration is the latency of debrid calls
+	DebridRequestDuration = Registry.NewHistogram("gostremiofr_debrid_request_duration_seconds",
+		"Duration of debrid API calls, rate limiting included.", nil, "provider", "operation")
+
+	// CacheLookups counts cache hits and misses; torrent provider lookups are counted
+	// by the shared cache that stores them
+	CacheLookups = Registry.NewCounter("gostremiofr_cache_lookups_total",
+		"Cache lookups by cache and result (hit or miss).", "cache", "result")
+
+	// StreamResolutions counts stream searches by outcome: cache_hit and prefetch_hit when served
+	// from stored streams, then per search phase resolved, not_cached or no_results
+	StreamResolutions = Registry.NewCounter("gostremiofr_stream_resolutions_total",
+		"Stream resolutions by media type and outcome.", "type", "outcome")
+
+	// CleanupRuns counts cleanup service runs by result
+	CleanupRuns = Registry.NewCounter("gostremiofr_cleanup_runs_total",
+		"Cleanup service runs by result.", "result")
+	// CleanupMagnets counts the magnets removed from debrid accounts by the cleanup service
+	CleanupMagnets = Registry.NewCounter("gostremiofr_cleanup_magnets_deleted_total",
+		"Magnets removed from debrid accounts by the cleanup service.", "provider")
+	// CleanupDuration is the duration of cleanup runs
+	CleanupDuration = Registry.NewHistogram("gostremiofr_cleanup_duration_seconds",
+		"Duration of cleanup service runs.", []float64{1, 5, 15, 60, 300, 900})
+)
+
+// CacheLookup records a cache hit or miss.
+func CacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	CacheLookups.Inc(cache, result)
+}
+
+// RateLimiterObserver returns a ratelimiter.TokenBucket OnWait function recording waits of a limiter.
+func RateLimiterObserver(limiter string) func(time.Duration) {
+	return func(wait time.Duration) {
+		RateLimiterWait.Observe(wait.Seconds(), limiter)
+	}
+}
+
+// TMDBEndpoint returns the endpoint of a TMDB API URL without its IDs,
+// e.g. "tv/season" for https://api.themoviedb.org/3/tv/1399/season/1.
+func TMDBEndpoint(apiURL string) string {
+	parsed, err := url.Parse(apiURL)
+	if err != nil {
+		return "unknown"
+	}
+
+	var segments []string
+	for i, segment := range strings.Split(strings.Trim(parsed.Path, "/"), "/") {
+		if i == 0 && segment == "3" {
+			continue // API version
+		}
+		if segment == "" || strings.ContainsAny(segment, "0123456789") {
+			continue // IDs
+		}
+		segments = append(segments, segment)
+	}
+	if len(segments) == 0 {
+		return "unknown"
+	}
+	return strings.Join(segments, "/")
+}
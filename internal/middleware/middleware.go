@@ -3,11 +3,19 @@ package middleware
 
 import (
 	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
 	"strings"
 	"time"
 
 	"github.com/amaumene/gostremiofr/pkg/logger"
+	"github.com/amaumene/gostremiofr/pkg/security"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // gzipResponseWriter wraps gin.ResponseWriter to provide gzip compression.
@@ -59,7 +67,8 @@ func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", RequestIDHeader)
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -70,21 +79,112 @@ func CORS() gin.HandlerFunc {
 	}
 }
 
+// RequestIDHeader is the header carrying the ID correlating the logs of a request.
+const RequestIDHeader = "X-Request-ID"
+
+// probePaths are polled by orchestrators; their successful requests are logged at debug level
+var probePaths = map[string]bool{"/health": true, "/ready": true}
+
+// maxRequestIDLength bounds the size of request IDs accepted from clients
+const maxRequestIDLength = 64
+
+// RequestID returns a middleware that assigns an ID to every request, reusing a valid
+// X-Request-ID header when the client sends one. The ID is returned in the response
+// headers and stored in the request context for logger.FromContext.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !isValidRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), id))
+
+		c.Next()
+	}
+}
+
+// isValidRequestID checks that a client request ID is short and safe to log.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates a random request ID.
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
+
+// Tracing returns a middleware that starts a server span per request, named after its
+// route so that user configurations never appear in span names. It continues traces
+// propagated with traceparent headers and tags spans with the ID set by RequestID.
+func Tracing() gin.HandlerFunc {
+	tracer := otel.Tracer("github.com/amaumene/gostremiofr/internal/middleware")
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("request_id", logger.RequestID(ctx)),
+			),
+		)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, "")
+		}
+	}
+}
+
 // Logger returns a middleware that logs HTTP requests with status-based log levels.
+// Requests are logged with the request ID set by RequestID.
 func Logger(log logger.Logger) gin.HandlerFunc {
+	validator := security.NewAPIKeyValidator()
+
 	return func(c *gin.Context) {
 		start := time.Now()
-		path := buildRequestPath(c)
 
 		c.Next()
 
-		logRequest(log, c, start, path)
+		path := buildRequestPath(c, validator)
+		logRequest(logger.FromContext(c.Request.Context(), log), c, start, path)
 	}
 }
 
 // buildRequestPath constructs the full request path with query parameters.
-func buildRequestPath(c *gin.Context) string {
+// The user configuration segment holds API keys and is masked.
+func buildRequestPath(c *gin.Context, validator *security.APIKeyValidator) string {
 	path := c.Request.URL.Path
+	if configuration := c.Param("configuration"); configuration != "" {
+		path = strings.Replace(path, configuration, validator.MaskAPIKey(configuration), 1)
+	}
 	if raw := c.Request.URL.RawQuery; raw != "" {
 		path = path + "?" + raw
 	}
@@ -94,16 +194,23 @@ func buildRequestPath(c *gin.Context) string {
 // logRequest logs the HTTP request with appropriate log level based on status.
 func logRequest(log logger.Logger, c *gin.Context, start time.Time, path string) {
 	latency := time.Since(start)
-	clientIP := c.ClientIP()
-	method := c.Request.Method
 	statusCode := c.Writer.Status()
+	log = log.With(
+		"client_ip", c.ClientIP(),
+		"method", c.Request.Method,
+		"status", statusCode,
+		"latency", latency,
+		"path", path,
+	)
 
 	switch {
 	case statusCode >= 500:
-		log.Errorf("HTTP request failed - %s %s %d %v %s", clientIP, method, statusCode, latency, path)
+		log.Errorf("HTTP request failed")
 	case statusCode >= 400:
-		log.Warnf("warning: client error - %s %s %d %v %s", clientIP, method, statusCode, latency, path)
+		log.Warnf("warning: client error")
+	case probePaths[c.Request.URL.Path]:
+		log.Debugf("HTTP request completed")
 	default:
-		log.Infof("HTTP request completed - %s %s %d %v %s", clientIP, method, statusCode, latency, path)
+		log.Infof("HTTP request completed")
 	}
 }
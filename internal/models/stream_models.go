@@ -2,9 +2,31 @@ package models
 
 // Stream represents a single playable stream in Stremio format.
 type Stream struct {
-	Name  string `json:"name,omitempty"`
-	Title string `json:"title,omitempty"`
-	URL   string `json:"url"`
+	Name          string               `json:"name,omitempty"`
+	Title         string               `json:"title,omitempty"`
+	URL           string               `json:"url"`
+	BehaviorHints *StreamBehaviorHints `json:"behaviorHints,omitempty"`
+	File          *StreamFile          `json:"-"` // Debrid file behind the stream, kept server side
+}
+
+// StreamFile identifies the debrid file a stream plays. It is not sent to Stremio.
+type StreamFile struct {
+	Hash     string // Torrent info hash
+	MagnetID string // Debrid magnet ID
+	Index    int    // Position of the file in the magnet links
+	Name     string
+	Link     string // Debrid file link, unlocked when the stream is opened
+	Season   int
+	Episode  int
+	Absolute int // Episode number anime releases use, 0 for other content
+}
+
+// StreamBehaviorHints tells Stremio how to play a stream.
+type StreamBehaviorHints struct {
+	BingeGroup  string `json:"bingeGroup,omitempty"`  // Streams sharing a group are autoplayed for the next episode
+	Filename    string `json:"filename,omitempty"`    // Name of the video file, used for subtitles matching
+	VideoSize   int64  `json:"videoSize,omitempty"`   // Size of the video file in bytes
+	NotWebReady bool   `json:"notWebReady,omitempty"` // The container cannot be played by the web player as is
 }
 
 // StreamResponse is the response format for stream endpoints.
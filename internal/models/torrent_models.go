@@ -1,12 +1,18 @@
 package models
 
+import "time"
+
 type TorrentInfo struct {
 	ID              string
 	Title           string
 	Hash            string
 	Source          string
-	Size            int64   // Size in bytes
-	ConfidenceScore float64 // Confidence score from torrentname parser
+	Size            int64     // Size in bytes
+	Seeders         int       // Seeders reported by the provider
+	Leechers        int       // Leechers reported by the provider
+	UploadDate      time.Time // Zero when the provider does not report it
+	ConfidenceScore float64   // Confidence score from torrentname parser
+	Weight          float64   // Ranking weight of the source provider, 0 means 1
 }
 
 type YggTorrent struct {
@@ -37,6 +43,7 @@ type MagnetInfo struct {
 	Hash   string
 	Title  string
 	Source string
+	ID     string // Debrid provider magnet ID, when the provider tracks magnets by ID
 }
 
 type ProcessedMagnet struct {
@@ -44,7 +51,7 @@ type ProcessedMagnet struct {
 	Ready  bool
 	Name   string
 	Size   float64
-	ID     int64
+	ID     string // Debrid provider magnet ID, empty when nothing was added to the account
 	Source string
 	Links  []interface{}
 }
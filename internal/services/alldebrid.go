@@ -1,15 +1,14 @@
 package services
 
 import (
+	"context"
 	"fmt"
-	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/amaumene/gostremiofr/internal/constants"
-	"github.com/amaumene/gostremiofr/internal/database"
 	"github.com/amaumene/gostremiofr/internal/models"
 	"github.com/amaumene/gostremiofr/pkg/alldebrid"
 	"github.com/amaumene/gostremiofr/pkg/logger"
@@ -41,7 +40,6 @@ type AllDebrid struct {
 	logger      logger.Logger
 	validator   *security.APIKeyValidator
 	fileParsers *fileParsers
-	db          database.Database
 }
 
 // fileParsers handles parsing of file metadata
@@ -59,7 +57,7 @@ func NewAllDebrid(apiKey string) *AllDebrid {
 
 	return &AllDebrid{
 		apiKey:      sanitizedKey,
-		rateLimiter: ratelimiter.NewTokenBucket(constants.AllDebridRateLimit, constants.AllDebridRateBurst),
+		rateLimiter: newRateLimiter("alldebrid", constants.AllDebridRateLimit, constants.AllDebridRateBurst),
 		client:      alldebrid.NewClient(),
 		logger:      logger.New(),
 		validator:   validator,
@@ -67,9 +65,14 @@ func NewAllDebrid(apiKey string) *AllDebrid {
 	}
 }
 
-// SetDB sets the database instance
-func (a *AllDebrid) SetDB(db database.Database) {
-	a.db = db
+// log returns the logger of the request served with ctx
+func (a *AllDebrid) log(ctx context.Context) logger.Logger {
+	return logger.FromContext(ctx, a.logger)
+}
+
+// Name returns the provider display name
+func (a *AllDebrid) Name() string {
+	return "AllDebrid"
 }
 
 // newFileParsers creates and compiles all regex patterns for file parsing
@@ -81,7 +84,7 @@ func newFileParsers() *fileParsers {
 	}
 }
 
-func (a *AllDebrid) CheckMagnets(magnets []models.MagnetInfo, apiKey string) ([]models.ProcessedMagnet, error) {
+func (a *AllDebrid) CheckMagnets(ctx context.Context, magnets []models.MagnetInfo, apiKey string) ([]models.ProcessedMagnet, error) {
 	// Validate API key
 	apiKey, err := a.validateAndPrepareAPIKey(apiKey)
 	if err != nil {
@@ -91,83 +94,118 @@ func (a *AllDebrid) CheckMagnets(magnets []models.MagnetInfo, apiKey string) ([]
 	// Build hash list for the API call
 	hashes, hashToMagnet := a.buildHashMapping(magnets)
 
-	a.rateLimiter.Wait()
+	if err := a.rateLimiter.WaitContext(ctx); err != nil {
+		return nil, err
+	}
 
 	// Make API call to check magnet status
-	response, err := a.checkMagnetStatus(apiKey, hashes)
+	response, err := a.checkMagnetStatus(ctx, apiKey, hashes)
 	if err != nil {
 		return nil, err
 	}
 
 	// Process response into structured data
-	processed := a.processMagnetResponse(response, hashToMagnet)
+	processed := a.processMagnetResponse(ctx, response, hashToMagnet)
 
-	a.logger.Debugf("API call returned %d results for %d requested magnets", len(processed), len(magnets))
+	a.log(ctx).Debugf("API call returned %d results for %d requested magnets", len(processed), len(magnets))
 
 	return processed, nil
 }
 
-func (a *AllDebrid) UploadMagnet(hash, title, apiKey string) error {
+// CheckInstantAvailability reports which hashes are cached on AllDebrid using a single API call.
+// The returned map is keyed by lowercase hash; hashes missing from the response are not cached.
+func (a *AllDebrid) CheckInstantAvailability(ctx context.Context, hashes []string, apiKey string) (map[string]bool, error) {
+	apiKey, err := a.validateAndPrepareAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := make(map[string]bool, len(hashes))
+	if len(hashes) == 0 {
+		return availability, nil
+	}
+
+	if err := a.rateLimiter.WaitContext(ctx); err != nil {
+		return nil, err
+	}
+
+	a.log(ctx).Debugf("[AllDebrid] checking instant availability for %d hashes", len(hashes))
+
+	resp, err := a.client.CheckInstant(ctx, apiKey, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check instant availability: %w", err)
+	}
+
+	if resp.Status != allDebridStatusSuccess {
+		if resp.Error != nil {
+			return nil, &DebridError{Provider: "AllDebrid", Code: resp.Error.Code, Message: resp.Error.Message}
+		}
+		return nil, fmt.Errorf("AllDebrid API error: %s", resp.Status)
+	}
+
+	for _, hash := range hashes {
+		availability[strings.ToLower(hash)] = false
+	}
+	for _, magnet := range resp.Data.Magnets {
+		hash := magnet.Hash
+		if hash == "" {
+			hash = magnet.Magnet
+		}
+		availability[strings.ToLower(hash)] = magnet.Instant
+	}
+
+	return availability, nil
+}
+
+func (a *AllDebrid) UploadMagnet(ctx context.Context, hash, title, apiKey string) (string, error) {
 	// Validate API key
 	apiKey, err := a.validateAndPrepareAPIKey(apiKey)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	a.rateLimiter.Wait()
+	if err := a.rateLimiter.WaitContext(ctx); err != nil {
+		return "", err
+	}
 
-	magnetURL := fmt.Sprintf("magnet:?xt=urn:btih:%s&dn=%s", hash, url.QueryEscape(title))
+	magnetURL := buildMagnetURL(hash, title)
 	
-	a.logger.Debugf("[AllDebrid] uploading magnet URL: %s", magnetURL)
+	a.log(ctx).Debugf("[AllDebrid] uploading magnet URL: %s", magnetURL)
 
 	// Use our local client
-	resp, err := a.client.UploadMagnet(apiKey, []string{magnetURL})
+	resp, err := a.client.UploadMagnet(ctx, apiKey, []string{magnetURL})
 	if err != nil {
-		return fmt.Errorf("failed to upload magnet: %w", err)
+		return "", fmt.Errorf("failed to upload magnet: %w", err)
 	}
 
 	// Check response status and errors
 	if err := a.checkAPIResponse(resp.Status, resp.Error, resp.Data.Magnets); err != nil {
-		return err
+		return "", err
 	}
 
-	// Store magnet information in database for cleanup
-	if a.db != nil && len(resp.Data.Magnets) > 0 {
-		magnet := resp.Data.Magnets[0]
-		// Use AllDebrid's magnet ID as part of our ID to ensure uniqueness
-		dbMagnet := &database.Magnet{
-			ID:           fmt.Sprintf("ad_%s_%d_%d", hash, magnet.ID, time.Now().UnixNano()),
-			Hash:         hash,
-			Name:         title,
-			AllDebridID:  fmt.Sprintf("%d", magnet.ID),
-			AllDebridKey: apiKey,
-		}
-		if err := a.db.StoreMagnet(dbMagnet); err != nil {
-			// Check if it's a duplicate constraint error
-			if strings.Contains(err.Error(), "unique constraint") {
-				a.logger.Debugf("magnet already exists in database: %s", hash)
-			} else {
-				a.logger.Warnf("failed to store magnet in database: %v", err)
-			}
-			// Don't fail the upload, just log the warning
-		}
+	if len(resp.Data.Magnets) == 0 {
+		return "", nil
 	}
-
-	return nil
+	if magnetErr := resp.Data.Magnets[0].Error; magnetErr != nil {
+		return "", &DebridError{Provider: "AllDebrid", Code: magnetErr.Code, Message: magnetErr.Message}
+	}
+	return strconv.FormatInt(resp.Data.Magnets[0].ID, 10), nil
 }
 
-func (a *AllDebrid) GetVideoFiles(magnetID, apiKey string) ([]models.VideoFile, error) {
+func (a *AllDebrid) GetVideoFiles(ctx context.Context, magnetID, apiKey string) ([]models.VideoFile, error) {
 	// Validate API key
 	apiKey, err := a.validateAndPrepareAPIKey(apiKey)
 	if err != nil {
 		return nil, err
 	}
 
-	a.rateLimiter.Wait()
+	if err := a.rateLimiter.WaitContext(ctx); err != nil {
+		return nil, err
+	}
 
 	// Get magnet files
-	a.logger.Debugf("[AllDebrid] getting files for magnet ID: %s", magnetID)
-	resp, err := a.client.GetMagnetFiles(apiKey, magnetID)
+	a.log(ctx).Debugf("[AllDebrid] getting files for magnet ID: %s", magnetID)
+	resp, err := a.client.GetMagnetFiles(ctx, apiKey, magnetID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get video files: %w", err)
 	}
@@ -175,7 +213,7 @@ func (a *AllDebrid) GetVideoFiles(magnetID, apiKey string) ([]models.VideoFile,
 	// Check response status
 	if resp.Status != allDebridStatusSuccess {
 		if resp.Error != nil {
-			return nil, fmt.Errorf("AllDebrid API error: %s - %s", resp.Error.Code, resp.Error.Message)
+			return nil, &DebridError{Provider: "AllDebrid", Code: resp.Error.Code, Message: resp.Error.Message}
 		}
 		return nil, fmt.Errorf("AllDebrid API error: %s", resp.Status)
 	}
@@ -186,19 +224,21 @@ func (a *AllDebrid) GetVideoFiles(magnetID, apiKey string) ([]models.VideoFile,
 	return videoFiles, nil
 }
 
-func (a *AllDebrid) UnlockLink(link, apiKey string) (string, error) {
+func (a *AllDebrid) UnlockLink(ctx context.Context, link, apiKey string) (string, error) {
 	// Validate API key
 	apiKey, err := a.validateAndPrepareAPIKey(apiKey)
 	if err != nil {
 		return "", err
 	}
 
-	a.rateLimiter.Wait()
+	if err := a.rateLimiter.WaitContext(ctx); err != nil {
+		return "", err
+	}
 	
-	a.logger.Debugf("[AllDebrid] unlocking link: %s", link)
+	a.log(ctx).Debugf("[AllDebrid] unlocking link: %s", link)
 
 	// Use our local client
-	resp, err := a.client.UnlockLink(apiKey, link)
+	resp, err := a.client.UnlockLink(ctx, apiKey, link)
 	if err != nil {
 		return "", fmt.Errorf("failed to unlock link: %w", err)
 	}
@@ -216,24 +256,26 @@ func (a *AllDebrid) UnlockLink(link, apiKey string) (string, error) {
 }
 
 // DeleteMagnet deletes a magnet from AllDebrid
-func (a *AllDebrid) DeleteMagnet(magnetID, apiKey string) error {
+func (a *AllDebrid) DeleteMagnet(ctx context.Context, magnetID, apiKey string) error {
 	// Validate API key
 	apiKey, err := a.validateAndPrepareAPIKey(apiKey)
 	if err != nil {
 		return err
 	}
 
-	a.rateLimiter.Wait()
+	if err := a.rateLimiter.WaitContext(ctx); err != nil {
+		return err
+	}
 	
-	a.logger.Debugf("[AllDebrid] deleting magnet ID: %s", magnetID)
+	a.log(ctx).Debugf("[AllDebrid] deleting magnet ID: %s", magnetID)
 
 	// Use our local client
-	err = a.client.DeleteMagnet(apiKey, magnetID)
+	err = a.client.DeleteMagnet(ctx, apiKey, magnetID)
 	if err != nil {
 		return fmt.Errorf("failed to delete magnet: %w", err)
 	}
 
-	a.logger.Infof("[AllDebrid] successfully deleted non-cached magnet ID: %s", magnetID)
+	a.log(ctx).Infof("[AllDebrid] successfully deleted non-cached magnet ID: %s", magnetID)
 	return nil
 }
 
@@ -269,15 +311,15 @@ func (a *AllDebrid) buildHashMapping(magnets []models.MagnetInfo) ([]string, map
 }
 
 // checkMagnetStatus makes the API call to check magnet status
-func (a *AllDebrid) checkMagnetStatus(apiKey string, hashes []string) (*magnetStatusResponse, error) {
+func (a *AllDebrid) checkMagnetStatus(ctx context.Context, apiKey string, hashes []string) (*magnetStatusResponse, error) {
 	requestURL := allDebridAPIBase + allDebridMagnetStatus
 	formData := a.buildMagnetFormData(apiKey, hashes)
 	
-	a.logger.Infof("checking %d specific magnets (API key: %s)", len(hashes), a.validator.MaskAPIKey(apiKey))
-	a.logger.Infof("making POST request to %s", requestURL)
-	a.logger.Debugf("[AllDebrid] API URL: %s (POST with %d hashes)", requestURL, len(hashes))
+	a.log(ctx).Infof("checking %d specific magnets (API key: %s)", len(hashes), a.validator.MaskAPIKey(apiKey))
+	a.log(ctx).Infof("making POST request to %s", requestURL)
+	a.log(ctx).Debugf("[AllDebrid] API URL: %s (POST with %d hashes)", requestURL, len(hashes))
 
-	resp, err := a.makeAPIRequest(requestURL, formData)
+	resp, err := a.makeAPIRequest(ctx, requestURL, formData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check magnets: %w", err)
 	}
@@ -289,7 +331,7 @@ func (a *AllDebrid) checkMagnetStatus(apiKey string, hashes []string) (*magnetSt
 
 	if response.Status != allDebridStatusSuccess {
 		if response.Error.Message != "" {
-			return nil, fmt.Errorf("AllDebrid API error: %s - %s", response.Error.Code, response.Error.Message)
+			return nil, &DebridError{Provider: "AllDebrid", Code: response.Error.Code, Message: response.Error.Message}
 		}
 		return nil, fmt.Errorf("AllDebrid API error: %s", response.Status)
 	}
@@ -298,7 +340,7 @@ func (a *AllDebrid) checkMagnetStatus(apiKey string, hashes []string) (*magnetSt
 }
 
 // processMagnetResponse processes the API response into structured data
-func (a *AllDebrid) processMagnetResponse(response *magnetStatusResponse, hashToMagnet map[string]models.MagnetInfo) []models.ProcessedMagnet {
+func (a *AllDebrid) processMagnetResponse(ctx context.Context, response *magnetStatusResponse, hashToMagnet map[string]models.MagnetInfo) []models.ProcessedMagnet {
 	var processed []models.ProcessedMagnet
 	for _, magnet := range response.Data.Magnets {
 		if original, ok := hashToMagnet[magnet.Hash]; ok {
@@ -327,7 +369,7 @@ func (a *AllDebrid) processMagnetResponse(response *magnetStatusResponse, hashTo
 				statusDesc = fmt.Sprintf("UNKNOWN_%d", magnet.StatusCode)
 			}
 
-			a.logger.Infof("[AllDebrid] magnet status - %s: %s (hash: %s, links: %d)",
+			a.log(ctx).Infof("[AllDebrid] magnet status - %s: %s (hash: %s, links: %d)",
 				name, statusDesc, magnet.Hash[:12], len(magnet.Links))
 
 			processed = append(processed, models.ProcessedMagnet{
@@ -335,7 +377,7 @@ func (a *AllDebrid) processMagnetResponse(response *magnetStatusResponse, hashTo
 				Ready:  ready,
 				Name:   name,
 				Size:   magnet.Size,
-				ID:     magnet.ID,
+				ID:     strconv.FormatInt(magnet.ID, 10),
 				Source: original.Source,
 				Links:  magnet.Links,
 			})
@@ -344,17 +386,17 @@ func (a *AllDebrid) processMagnetResponse(response *magnetStatusResponse, hashTo
 	return processed
 }
 
+// allDebridAPIError is the error object of AllDebrid API responses
+type allDebridAPIError = struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
 // checkAPIResponse checks the status and error of an API response
 func (a *AllDebrid) checkAPIResponse(status string, apiError interface{}, magnets interface{}) error {
 	if status != allDebridStatusSuccess {
-		if apiError != nil {
-			// Handle different error types
-			switch err := apiError.(type) {
-			case *struct{ Code, Message string }:
-				return fmt.Errorf("AllDebrid API error: %s - %s", err.Code, err.Message)
-			default:
-				return fmt.Errorf("AllDebrid API error: %s", status)
-			}
+		if err, ok := apiError.(*allDebridAPIError); ok && err != nil {
+			return &DebridError{Provider: "AllDebrid", Code: err.Code, Message: err.Message}
 		}
 		return fmt.Errorf("AllDebrid API error: %s", status)
 	}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -57,17 +58,23 @@ func (a *AllDebrid) buildMagnetFormData(apiKey string, hashes []string) url.Valu
 	return formData
 }
 
-func (a *AllDebrid) makeAPIRequest(requestURL string, formData url.Values) (*http.Response, error) {
+func (a *AllDebrid) makeAPIRequest(ctx context.Context, requestURL string, formData url.Values) (*http.Response, error) {
 	httpClient := httputil.NewHTTPClient(allDebridAPITimeout)
-	
-	a.logger.Infof("sending POST request...")
-	resp, err := httpClient.PostForm(requestURL, formData)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, strings.NewReader(formData.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	a.log(ctx).Infof("sending POST request...")
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		a.logger.Errorf("POST request failed: %v", err)
+		a.log(ctx).Errorf("POST request failed: %v", err)
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
 	
-	a.logger.Infof("received HTTP response with status: %s", resp.Status)
+	a.log(ctx).Infof("received HTTP response with status: %s", resp.Status)
 	return resp, nil
 }
 
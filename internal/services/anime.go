@@ -0,0 +1,158 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// AnimeMapping links a Kitsu or MyAnimeList entry to the TMDB and IMDb IDs of the show.
+// Anime databases list each season, or each part of a split season, as its own entry, so the
+// entry also records its season and the episodes of that season before its first one.
+type AnimeMapping struct {
+	IMDBID        string // First IMDb ID of the entry, empty when unknown
+	TMDBID        int    // TMDB ID, 0 when unknown
+	Season        int    // TMDB season of the entry, 1 when unknown
+	EpisodeOffset int    // Added to entry episode numbers to get season episodes, e.g. 12 for a second part
+	Type          string // Entry type, e.g. "TV" or "MOVIE"
+}
+
+// AnimeMapper resolves anime IDs from an offline mapping dataset.
+// A nil AnimeMapper is valid and resolves nothing.
+type AnimeMapper struct {
+	kitsu map[int]AnimeMapping
+	mal   map[int]AnimeMapping
+}
+
+// animeListEntry is an entry of the Fribb/anime-lists dataset (anime-list-full.json or anime-list-mini.json)
+type animeListEntry struct {
+	KitsuID       flexibleInt    `json:"kitsu_id"`
+	MALID         flexibleInt    `json:"mal_id"`
+	IMDBID        string         `json:"imdb_id"`
+	TMDBID        flexibleInt    `json:"themoviedb_id"`
+	Type          string         `json:"type"`
+	Season        map[string]int `json:"season"`
+	EpisodeOffset flexibleInt    `json:"episodeoffset"`
+}
+
+// flexibleInt decodes IDs the dataset stores either as numbers or as strings
+type flexibleInt int
+
+func (f *flexibleInt) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "" || value == "null" {
+		*f = 0
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		// Lists of IDs are not usable for a one-to-one mapping
+		*f = 0
+		return nil
+	}
+	*f = flexibleInt(n)
+	return nil
+}
+
+// LoadAnimeMapper reads a Fribb/anime-lists mapping file.
+func LoadAnimeMapper(path string) (*AnimeMapper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anime mapping: %w", err)
+	}
+
+	var entries []animeListEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode anime mapping: %w", err)
+	}
+
+	mapper := &AnimeMapper{
+		kitsu: make(map[int]AnimeMapping),
+		mal:   make(map[int]AnimeMapping),
+	}
+	for _, entry := range entries {
+		mapping, ok := entry.mapping()
+		if !ok {
+			continue
+		}
+		if entry.KitsuID > 0 {
+			mapper.kitsu[int(entry.KitsuID)] = mapping
+		}
+		if entry.MALID > 0 {
+			mapper.mal[int(entry.MALID)] = mapping
+		}
+	}
+
+	return mapper, nil
+}
+
+// mapping converts an entry, reporting false when it has neither an IMDb nor a TMDB ID
+func (e animeListEntry) mapping() (AnimeMapping, bool) {
+	imdbID := strings.TrimSpace(strings.Split(e.IMDBID, ",")[0])
+	if !strings.HasPrefix(imdbID, "tt") {
+		imdbID = ""
+	}
+	if imdbID == "" && e.TMDBID == 0 {
+		return AnimeMapping{}, false
+	}
+
+	season := e.Season["tmdb"]
+	if season <= 0 {
+		season = 1
+	}
+	offset := int(e.EpisodeOffset)
+	if offset < 0 {
+		offset = 0
+	}
+	return AnimeMapping{IMDBID: imdbID, TMDBID: int(e.TMDBID), Season: season, EpisodeOffset: offset, Type: e.Type}, true
+}
+
+// Lookup resolves an anime ID such as "kitsu:1376" or "mal:21".
+func (m *AnimeMapper) Lookup(id string) (AnimeMapping, bool) {
+	if m == nil {
+		return AnimeMapping{}, false
+	}
+
+	prefix, value, found := strings.Cut(id, ":")
+	if !found {
+		return AnimeMapping{}, false
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return AnimeMapping{}, false
+	}
+
+	var mapping AnimeMapping
+	switch prefix {
+	case "kitsu":
+		mapping, found = m.kitsu[n]
+	case "mal":
+		mapping, found = m.mal[n]
+	default:
+		found = false
+	}
+	return mapping, found
+}
+
+// Len returns the number of mapped entries.
+func (m *AnimeMapper) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.kitsu) + len(m.mal)
+}
+
+// Episode converts an episode number of the entry to the episode number of its TMDB season.
+func (a AnimeMapping) Episode(episode int) int {
+	return episode + a.EpisodeOffset
+}
+
+// MediaID returns the ID to look the show up on TMDB: the IMDb ID when known, "tmdb:<id>" otherwise.
+func (a AnimeMapping) MediaID() string {
+	if a.IMDBID != "" {
+		return a.IMDBID
+	}
+	return fmt.Sprintf("tmdb:%d", a.TMDBID)
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFlexibleInt(t *testing.T) {
+	tests := []struct {
+		data string
+		want flexibleInt
+	}{
+		{`42`, 42},
+		{`"42"`, 42},
+		{`""`, 0},
+		{`null`, 0},
+		{`"1,2"`, 0},
+		{`[1,2]`, 0},
+	}
+
+	for _, tt := range tests {
+		f := flexibleInt(-1)
+		if err := json.Unmarshal([]byte(tt.data), &f); err != nil {
+			t.Errorf("Unmarshal(%s): %v", tt.data, err)
+			continue
+		}
+		if f != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, want %d", tt.data, f, tt.want)
+		}
+	}
+}
+
+func newTestAnimeMapper(t *testing.T, data string) *AnimeMapper {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "anime-list.json")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	mapper, err := LoadAnimeMapper(path)
+	if err != nil {
+		t.Fatalf("LoadAnimeMapper: %v", err)
+	}
+	return mapper
+}
+
+func TestAnimeMapperLookup(t *testing.T) {
+	mapper := newTestAnimeMapper(t, `[
+		{"kitsu_id": 1376, "mal_id": 21, "imdb_id": "tt0388629", "themoviedb_id": 37854, "type": "TV"},
+		{"kitsu_id": "8671", "mal_id": "16498", "imdb_id": "tt2560140,tt9999999", "themoviedb_id": "1429", "type": "TV", "season": {"tvdb": 1, "tmdb": 1}},
+		{"kitsu_id": 13569, "mal_id": 38524, "imdb_id": "tt2560140", "themoviedb_id": 1429, "type": "TV", "season": {"tmdb": 3}, "episodeoffset": 12},
+		{"kitsu_id": 42, "themoviedb_id": 500, "type": "MOVIE", "episodeoffset": -3},
+		{"kitsu_id": 7, "mal_id": 8, "imdb_id": "", "type": "TV"},
+		{"kitsu_id": 9, "imdb_id": "unknown", "themoviedb_id": "123,456", "type": "TV"}
+	]`)
+
+	tests := []struct {
+		id    string
+		want  AnimeMapping
+		found bool
+	}{
+		{"kitsu:1376", AnimeMapping{IMDBID: "tt0388629", TMDBID: 37854, Season: 1, Type: "TV"}, true},
+		{"mal:21", AnimeMapping{IMDBID: "tt0388629", TMDBID: 37854, Season: 1, Type: "TV"}, true},
+		{"kitsu:8671", AnimeMapping{IMDBID: "tt2560140", TMDBID: 1429, Season: 1, Type: "TV"}, true},
+		{"mal:38524", AnimeMapping{IMDBID: "tt2560140", TMDBID: 1429, Season: 3, EpisodeOffset: 12, Type: "TV"}, true},
+		{"kitsu:42", AnimeMapping{TMDBID: 500, Season: 1, Type: "MOVIE"}, true},
+		{"kitsu:7", AnimeMapping{}, false},
+		{"mal:8", AnimeMapping{}, false},
+		{"kitsu:9", AnimeMapping{}, false},
+		{"mal:1376", AnimeMapping{}, false},
+		{"anilist:21", AnimeMapping{}, false},
+		{"kitsu:abc", AnimeMapping{}, false},
+		{"kitsu", AnimeMapping{}, false},
+	}
+
+	for _, tt := range tests {
+		got, found := mapper.Lookup(tt.id)
+		if found != tt.found || got != tt.want {
+			t.Errorf("Lookup(%q) = %+v, %v, want %+v, %v", tt.id, got, found, tt.want, tt.found)
+		}
+	}
+
+	if got := mapper.Len(); got != 7 {
+		t.Errorf("Len() = %d, want 7", got)
+	}
+}
+
+func TestNilAnimeMapper(t *testing.T) {
+	var mapper *AnimeMapper
+	if _, found := mapper.Lookup("kitsu:1376"); found {
+		t.Error("nil mapper resolved an ID")
+	}
+	if got := mapper.Len(); got != 0 {
+		t.Errorf("Len() = %d, want 0", got)
+	}
+}
+
+func TestLoadAnimeMapperErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadAnimeMapper(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("missing file loaded")
+	}
+
+	path := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(path, []byte(`{"kitsu_id": 1}`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := LoadAnimeMapper(path); err == nil {
+		t.Error("invalid file loaded")
+	}
+}
+
+func TestAnimeMappingEpisodeAndMediaID(t *testing.T) {
+	tests := []struct {
+		mapping     AnimeMapping
+		episode     int
+		wantEpisode int
+		wantID      string
+	}{
+		{AnimeMapping{IMDBID: "tt0388629", TMDBID: 37854}, 5, 5, "tt0388629"},
+		{AnimeMapping{IMDBID: "tt2560140", TMDBID: 1429, EpisodeOffset: 12}, 1, 13, "tt2560140"},
+		{AnimeMapping{TMDBID: 500, EpisodeOffset: 24}, 3, 27, "tmdb:500"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.mapping.Episode(tt.episode); got != tt.wantEpisode {
+			t.Errorf("%+v Episode(%d) = %d, want %d", tt.mapping, tt.episode, got, tt.wantEpisode)
+		}
+		if got := tt.mapping.MediaID(); got != tt.wantID {
+			t.Errorf("%+v MediaID() = %q, want %q", tt.mapping, got, tt.wantID)
+		}
+	}
+}
@@ -2,10 +2,13 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/amaumene/gostremiofr/internal/constants"
 	"github.com/amaumene/gostremiofr/internal/database"
+	"github.com/amaumene/gostremiofr/internal/metrics"
 	"github.com/amaumene/gostremiofr/pkg/logger"
 	"github.com/amaumene/gostremiofr/pkg/security"
 )
@@ -13,27 +16,41 @@ import (
 const (
 	// Default cleanup settings
 	defaultCleanupInterval = 1 * time.Hour
-	defaultRetentionPeriod = 4 * time.Hour
+	defaultRetentionPeriod = constants.DefaultRetentionHours * time.Hour
 )
 
 // CleanupService manages periodic cleanup of old resources
 type CleanupService struct {
 	db              database.Database
-	allDebrid       *AllDebrid
+	providers       map[string]DebridProvider
 	logger          logger.Logger
 	interval        time.Duration
 	retentionPeriod time.Duration
 	mu              sync.Mutex
 	running         bool
 	stopChan        chan struct{}
+	loopDone        chan struct{} // closed when the cleanup loop returns, nil until started
 	validator       *security.APIKeyValidator
+	lastRun         time.Time // end of the last run, zero before the first one
+	lastResult      string
+	lastErr         error
 }
 
-// NewCleanupService creates a new cleanup service
-func NewCleanupService(db database.Database, allDebrid *AllDebrid) *CleanupService {
+// CleanupStatus describes the cleanup service state reported on /status
+type CleanupStatus struct {
+	Running    bool       `json:"running"`
+	Interval   string     `json:"interval"`
+	Retention  string     `json:"retention"`
+	LastRun    *time.Time `json:"last_run,omitempty"`
+	LastResult string     `json:"last_result,omitempty"` // success, empty or error
+	LastError  string     `json:"last_error,omitempty"`
+}
+
+// NewCleanupService creates a new cleanup service for magnets uploaded through the given providers
+func NewCleanupService(db database.Database, providers map[string]DebridProvider) *CleanupService {
 	return &CleanupService{
 		db:              db,
-		allDebrid:       allDebrid,
+		providers:       providers,
 		logger:          logger.New(),
 		interval:        defaultCleanupInterval,
 		retentionPeriod: defaultRetentionPeriod,
@@ -64,6 +81,7 @@ func (c *CleanupService) Start(ctx context.Context) error {
 		return nil
 	}
 	c.running = true
+	c.loopDone = make(chan struct{})
 	c.mu.Unlock()
 
 	c.logger.Infof("starting cleanup service with interval: %v, retention: %v", c.interval, c.retentionPeriod)
@@ -91,8 +109,60 @@ func (c *CleanupService) Stop() {
 	c.logger.Infof("cleanup service stopped")
 }
 
+// Shutdown stops the service and waits for a cleanup in progress to finish until ctx is done
+func (c *CleanupService) Shutdown(ctx context.Context) error {
+	c.Stop()
+
+	c.mu.Lock()
+	loopDone := c.loopDone
+	c.mu.Unlock()
+	if loopDone == nil {
+		return nil
+	}
+
+	select {
+	case <-loopDone:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("cleanup still running: %w", ctx.Err())
+	}
+}
+
+// Status returns the state of the service and the outcome of its last run
+func (c *CleanupService) Status() CleanupStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := CleanupStatus{
+		Running:    c.running,
+		Interval:   c.interval.String(),
+		Retention:  c.retentionPeriod.String(),
+		LastResult: c.lastResult,
+	}
+	if !c.lastRun.IsZero() {
+		lastRun := c.lastRun
+		status.LastRun = &lastRun
+	}
+	if c.lastErr != nil {
+		status.LastError = logger.Redact(c.lastErr.Error())
+	}
+	return status
+}
+
+// recordRun stores the outcome of a run
+func (c *CleanupService) recordRun(result string, err error) {
+	metrics.CleanupRuns.Inc(result)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastRun = time.Now().UTC()
+	c.lastResult = result
+	c.lastErr = err
+}
+
 // cleanupLoop runs periodic cleanup
 func (c *CleanupService) cleanupLoop(ctx context.Context) {
+	defer close(c.loopDone)
 	ticker := time.NewTicker(c.interval)
 	defer ticker.Stop()
 
@@ -112,38 +182,58 @@ func (c *CleanupService) cleanupLoop(ctx context.Context) {
 // performCleanup executes the cleanup process
 func (c *CleanupService) performCleanup() {
 	c.logger.Infof("starting cleanup process")
+	start := time.Now()
+	defer metrics.CleanupDuration.ObserveSince(start)
+
+	if err := c.db.DeleteExpiredStreamCache(); err != nil {
+		c.logger.Warnf("failed to delete expired stream cache: %v", err)
+	}
 
 	oldMagnets, err := c.fetchOldMagnets()
-	if err != nil || oldMagnets == nil {
+	if err != nil {
+		c.recordRun("error", err)
+		return
+	}
+	if oldMagnets == nil {
+		c.recordRun("empty", nil)
 		return
 	}
 
-	magnetsByKey := c.groupMagnetsByAPIKey(oldMagnets)
-	c.cleanupAllDebridConcurrently(magnetsByKey)
+	magnetsByAccount := c.groupMagnetsByAccount(oldMagnets)
+	c.cleanupAccountsConcurrently(magnetsByAccount)
 	
 	cleaned := c.deleteMagnetsFromDatabase(oldMagnets)
 	c.logger.Infof("cleanup completed: %d magnets removed from database", cleaned)
+	c.recordRun("success", nil)
 }
 
-// cleanupAllDebridMagnets removes magnets from AllDebrid
-func (c *CleanupService) cleanupAllDebridMagnets(apiKey string, magnets []database.Magnet) {
+// cleanupAccountMagnets removes magnets from a debrid account
+func (c *CleanupService) cleanupAccountMagnets(account magnetAccount, magnets []database.Magnet) {
+	provider, ok := c.providers[account.provider]
+	if !ok {
+		c.logger.Warnf("skipping cleanup for unknown debrid provider: %s", account.provider)
+		return
+	}
+
 	// Validate API key
-	if !c.validator.IsValidAllDebridKey(apiKey) {
-		c.logger.Warnf("skipping cleanup for invalid API key: %s", c.validator.MaskAPIKey(apiKey))
+	if !c.validator.ValidateAPIKey(account.apiKey) {
+		c.logger.Warnf("skipping cleanup for invalid API key: %s", c.validator.MaskAPIKey(account.apiKey))
 		return
 	}
 
 	for _, magnet := range magnets {
-		if magnet.AllDebridID == "" {
+		if magnet.DebridID == "" {
 			continue
 		}
 
-		err := c.allDebrid.DeleteMagnet(magnet.AllDebridID, apiKey)
+		err := provider.DeleteMagnet(context.Background(), magnet.DebridID, account.apiKey)
 		if err != nil {
-			c.logger.Warnf("failed to delete magnet %s from AllDebrid: %v", magnet.AllDebridID, err)
+			c.logger.Warnf("failed to delete magnet %s from %s: %v", magnet.DebridID, provider.Name(), err)
 			// Continue with other magnets even if one fails
 		} else {
-			c.logger.Debugf("deleted magnet %s from AllDebrid", magnet.AllDebridID)
+			c.logger.Debugf("deleted magnet %s from %s", magnet.DebridID, provider.Name())
+			metrics.CleanupMagnets.Inc(account.provider)
+			c.invalidateStreamCache(account, magnet)
 		}
 
 		// Small delay between deletions to avoid rate limiting
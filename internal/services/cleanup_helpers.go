@@ -3,9 +3,16 @@ package services
 import (
 	"sync"
 
+	"github.com/amaumene/gostremiofr/internal/constants"
 	"github.com/amaumene/gostremiofr/internal/database"
 )
 
+// magnetAccount identifies the debrid account a magnet was uploaded to
+type magnetAccount struct {
+	provider string
+	apiKey   string
+}
+
 func (c *CleanupService) fetchOldMagnets() ([]database.Magnet, error) {
 	oldMagnets, err := c.db.GetOldMagnets(c.retentionPeriod)
 	if err != nil {
@@ -22,24 +29,31 @@ func (c *CleanupService) fetchOldMagnets() ([]database.Magnet, error) {
 	return oldMagnets, nil
 }
 
-func (c *CleanupService) groupMagnetsByAPIKey(magnets []database.Magnet) map[string][]database.Magnet {
-	magnetsByKey := make(map[string][]database.Magnet)
+func (c *CleanupService) groupMagnetsByAccount(magnets []database.Magnet) map[magnetAccount][]database.Magnet {
+	magnetsByAccount := make(map[magnetAccount][]database.Magnet)
 	for _, magnet := range magnets {
-		if magnet.AllDebridID != "" && magnet.AllDebridKey != "" {
-			magnetsByKey[magnet.AllDebridKey] = append(magnetsByKey[magnet.AllDebridKey], magnet)
+		if magnet.DebridID == "" || magnet.DebridKey == "" {
+			continue
 		}
+		provider := magnet.Provider
+		if provider == "" {
+			// Entries stored before multi-provider support are AllDebrid magnets
+			provider = constants.DebridAllDebrid
+		}
+		account := magnetAccount{provider: provider, apiKey: magnet.DebridKey}
+		magnetsByAccount[account] = append(magnetsByAccount[account], magnet)
 	}
-	return magnetsByKey
+	return magnetsByAccount
 }
 
-func (c *CleanupService) cleanupAllDebridConcurrently(magnetsByKey map[string][]database.Magnet) {
+func (c *CleanupService) cleanupAccountsConcurrently(magnetsByAccount map[magnetAccount][]database.Magnet) {
 	var wg sync.WaitGroup
-	for apiKey, magnets := range magnetsByKey {
+	for account, magnets := range magnetsByAccount {
 		wg.Add(1)
-		go func(key string, mags []database.Magnet) {
+		go func(acc magnetAccount, mags []database.Magnet) {
 			defer wg.Done()
-			c.cleanupAllDebridMagnets(key, mags)
-		}(apiKey, magnets)
+			c.cleanupAccountMagnets(acc, mags)
+		}(account, magnets)
 	}
 	wg.Wait()
 }
@@ -54,4 +68,12 @@ func (c *CleanupService) deleteMagnetsFromDatabase(magnets []database.Magnet) in
 		}
 	}
 	return cleaned
-}
\ No newline at end of file
+}
+
+// invalidateStreamCache drops the cached streams that relied on a deleted magnet
+func (c *CleanupService) invalidateStreamCache(account magnetAccount, magnet database.Magnet) {
+	fingerprint := database.AccountFingerprint(account.provider, account.apiKey)
+	if err := c.db.InvalidateStreamCache(fingerprint, magnet.Hash); err != nil {
+		c.logger.Warnf("failed to invalidate cached streams of magnet %s: %v", magnet.DebridID, err)
+	}
+}
@@ -2,6 +2,8 @@
 package services
 
 import (
+	"context"
+
 	"github.com/amaumene/gostremiofr/internal/cache"
 	"github.com/amaumene/gostremiofr/internal/database"
 	"github.com/amaumene/gostremiofr/internal/models"
@@ -12,32 +14,26 @@ import (
 // Container holds all application services for dependency injection.
 type Container struct {
 	TMDB           TMDBService
-	AllDebrid      AllDebridService
+	Debrid         map[string]DebridProvider
+	DebridHealth   *AccountHealth // accounts on cooldown after auth or quota errors
 	Cache          *cache.LRUCache
 	DB             database.Database
 	Logger         logger.Logger
-	TorrentSorter  *TorrentSorter
 	Cleanup        *CleanupService
 	TorrentSearch  *torrentsearch.TorrentSearch
+	Anime          *AnimeMapper // nil when no anime mapping is configured
 }
 
 // TMDBService defines the interface for TMDB API operations.
 type TMDBService interface {
-	GetIMDBInfo(imdbID string) (string, string, string, int, string, error)
-	GetTMDBInfo(tmdbID string) (string, string, string, int, string, error)
-	GetTMDBInfoWithType(tmdbID, mediaType string) (string, string, string, int, string, error)
-	GetPopularMovies(page int, genreID string) ([]models.Meta, error)
-	GetPopularSeries(page int, genreID string) ([]models.Meta, error)
-	GetTrending(mediaType string, timeWindow string, page int) ([]models.Meta, error)
-	SearchMulti(query string, page int) ([]models.Meta, error)
-	GetMetadata(mediaType, tmdbID string) (*models.Meta, error)
-}
-
-// AllDebridService defines the interface for AllDebrid API operations.
-type AllDebridService interface {
-	CheckMagnets(magnets []models.MagnetInfo, apiKey string) ([]models.ProcessedMagnet, error)
-	UploadMagnet(hash, title, apiKey string) error
-	GetVideoFiles(magnetID, apiKey string) ([]models.VideoFile, error)
-	UnlockLink(link, apiKey string) (string, error)
-	DeleteMagnet(magnetID, apiKey string) error
+	// WithAPIKey returns a request-scoped view of the service using the given API key
+	WithAPIKey(apiKey string) TMDBService
+	GetIMDBInfo(ctx context.Context, imdbID string) (string, string, string, int, string, error)
+	GetTMDBInfo(ctx context.Context, tmdbID string) (string, string, string, int, string, error)
+	GetTMDBInfoWithType(ctx context.Context, tmdbID, mediaType string) (string, string, string, int, string, error)
+	GetPopularMovies(ctx context.Context, page int, genreID string) ([]models.Meta, error)
+	GetPopularSeries(ctx context.Context, page int, genreID string) ([]models.Meta, error)
+	GetTrending(ctx context.Context, mediaType string, timeWindow string, page int) ([]models.Meta, error)
+	SearchMulti(ctx context.Context, query string, page int) ([]models.Meta, error)
+	GetMetadata(ctx context.Context, mediaType, tmdbID string) (*models.Meta, error)
 }
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/amaumene/gostremiofr/internal/constants"
+	"github.com/amaumene/gostremiofr/internal/database"
+	"github.com/amaumene/gostremiofr/internal/models"
+	"github.com/amaumene/gostremiofr/pkg/logger"
+)
+
+// DebridProvider defines the operations the stream pipeline needs from a debrid service.
+// File links returned by CheckMagnets are opaque to callers and must be passed to UnlockLink.
+type DebridProvider interface {
+	// Name returns the display name used in logs and stream titles
+	Name() string
+	// CheckInstantAvailability reports which hashes are cached, keyed by lowercase hash
+	CheckInstantAvailability(ctx context.Context, hashes []string, apiKey string) (map[string]bool, error)
+	// UploadMagnet adds a magnet to the account and returns the provider magnet ID, if any
+	UploadMagnet(ctx context.Context, hash, title, apiKey string) (string, error)
+	// CheckMagnets returns the status and files of previously uploaded magnets
+	CheckMagnets(ctx context.Context, magnets []models.MagnetInfo, apiKey string) ([]models.ProcessedMagnet, error)
+	// UnlockLink converts a file link into a direct streaming URL
+	UnlockLink(ctx context.Context, link, apiKey string) (string, error)
+	// DeleteMagnet removes a magnet from the account
+	DeleteMagnet(ctx context.Context, magnetID, apiKey string) error
+}
+
+// DebridAccount pairs a debrid provider with the API key of the account using it.
+type DebridAccount struct {
+	ProviderID string // configuration identifier, e.g. "realdebrid"
+	Provider   DebridProvider
+	APIKey     string
+}
+
+// Fingerprint identifies the account without its API key, see database.AccountFingerprint.
+// A pool is identified by the fingerprints of all its accounts.
+func (a *DebridAccount) Fingerprint() string {
+	if pool, ok := a.Provider.(*debridPool); ok {
+		return pool.key
+	}
+	return database.AccountFingerprint(a.ProviderID, a.APIKey)
+}
+
+// MagnetOwner returns the fingerprint of the account holding a magnet returned by this account,
+// which for a pool is the pool account the magnet was uploaded to.
+func (a *DebridAccount) MagnetOwner(magnetID string) string {
+	if pool, ok := a.Provider.(*debridPool); ok {
+		if account, _, found := pool.owner(magnetID); found {
+			return account.fingerprint
+		}
+	}
+	return database.AccountFingerprint(a.ProviderID, a.APIKey)
+}
+
+// NewDebridProviders creates every supported debrid provider, keyed by configuration identifier.
+// Every call is measured, and uploaded magnets are recorded in the database so the cleanup
+// service can remove them later.
+func NewDebridProviders(db database.Database) map[string]DebridProvider {
+	providers := map[string]DebridProvider{
+		constants.DebridAllDebrid:  NewAllDebrid(""),
+		constants.DebridRealDebrid: NewRealDebrid(),
+		constants.DebridPremiumize: NewPremiumize(),
+		constants.DebridTorBox:     NewTorBox(),
+	}
+
+	for id, provider := range providers {
+		providers[id] = &measuredDebrid{DebridProvider: provider}
+	}
+
+	if db == nil {
+		return providers
+	}
+
+	for id, provider := range providers {
+		providers[id] = &trackedDebrid{DebridProvider: provider, id: id, db: db, logger: logger.New()}
+	}
+	return providers
+}
+
+// ResolveDebridAccount returns the account for the given provider identifier and API key.
+// An empty identifier selects AllDebrid for compatibility with older configurations.
+func ResolveDebridAccount(providers map[string]DebridProvider, providerID, apiKey string) (*DebridAccount, error) {
+	providerID = strings.ToLower(strings.TrimSpace(providerID))
+	if providerID == "" {
+		providerID = constants.DebridAllDebrid
+	}
+
+	provider, ok := providers[providerID]
+	if !ok {
+		return nil, fmt.Errorf("unsupported debrid provider: %s", providerID)
+	}
+
+	return &DebridAccount{ProviderID: providerID, Provider: provider, APIKey: apiKey}, nil
+}
+
+// trackedDebrid records uploaded magnets in the database for later cleanup
+type trackedDebrid struct {
+	DebridProvider
+	id     string
+	db     database.Database
+	logger logger.Logger
+}
+
+func (t *trackedDebrid) UploadMagnet(ctx context.Context, hash, title, apiKey string) (string, error) {
+	magnetID, err := t.DebridProvider.UploadMagnet(ctx, hash, title, apiKey)
+	if err != nil || magnetID == "" {
+		return magnetID, err
+	}
+
+	// Use the provider's magnet ID as part of our ID to ensure uniqueness
+	dbMagnet := &database.Magnet{
+		ID:        fmt.Sprintf("%s_%s_%s_%d", t.id, hash, magnetID, time.Now().UnixNano()),
+		Hash:      hash,
+		Name:      title,
+		Provider:  t.id,
+		DebridID:  magnetID,
+		DebridKey: apiKey,
+	}
+	if err := t.db.StoreMagnet(dbMagnet); err != nil {
+		// Don't fail the upload, but the magnet will not be cleaned up
+		t.logger.Warnf("failed to store magnet %s in database: %v", hash, err)
+	}
+
+	return magnetID, nil
+}
+
+// buildMagnetURL builds a magnet URI from an info hash and display name
+func buildMagnetURL(hash, title string) string {
+	return fmt.Sprintf("magnet:?xt=urn:btih:%s&dn=%s", hash, url.QueryEscape(title))
+}
@@ -0,0 +1,438 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amaumene/gostremiofr/internal/constants"
+	"github.com/amaumene/gostremiofr/internal/database"
+	"github.com/amaumene/gostremiofr/internal/models"
+	"github.com/amaumene/gostremiofr/pkg/logger"
+	"github.com/amaumene/gostremiofr/pkg/premiumize"
+	"github.com/amaumene/gostremiofr/pkg/realdebrid"
+	"github.com/amaumene/gostremiofr/pkg/torbox"
+)
+
+// DebridError is an error code returned by a debrid API, e.g. AUTH_BAD_APIKEY or MAGNET_TOO_MANY.
+type DebridError struct {
+	Provider string
+	Code     string
+	Message  string
+}
+
+func (e *DebridError) Error() string {
+	return fmt.Sprintf("%s API error: %s - %s", e.Provider, e.Code, e.Message)
+}
+
+// allDebridAccountErrors are the AllDebrid codes about the account rather than the request
+var allDebridAccountErrors = map[string]bool{
+	"AUTH_MISSING_APIKEY":      true,
+	"AUTH_BAD_APIKEY":          true,
+	"AUTH_BLOCKED":             true,
+	"AUTH_USER_BANNED":         true,
+	"MUST_BE_PREMIUM":          true,
+	"MAGNET_MUST_BE_PREMIUM":   true,
+	"MAGNET_TOO_MANY":          true,
+	"MAGNET_TOO_MANY_ACTIVE":   true,
+	"FREE_TRIAL_LIMIT_REACHED": true,
+}
+
+// realDebridAccountErrors are the Real-Debrid error codes about the account: bad token,
+// permission denied, account locked, premium only, too many active downloads, IP not allowed
+// and too many requests
+var realDebridAccountErrors = map[int]bool{8: true, 9: true, 14: true, 20: true, 21: true, 22: true, 34: true}
+
+// torBoxAccountErrors are the TorBox codes about the account rather than the request
+var torBoxAccountErrors = map[string]bool{
+	"BAD_TOKEN":               true,
+	"AUTH_ERROR":              true,
+	"NO_AUTH":                 true,
+	"PLAN_RESTRICTED_FEATURE": true,
+	"ACTIVE_LIMIT":            true,
+	"MONTHLY_LIMIT":           true,
+	"COOLDOWN_LIMIT":          true,
+}
+
+// premiumizeAccountErrors are fragments of the Premiumize errors about the account: most
+// endpoints report them with a message only, e.g. "Not logged in." or "Fair use limit reached"
+var premiumizeAccountErrors = []string{"not logged in", "api key", "apikey", "auth", "premium", "fair use", "limit"}
+
+// IsAccountError reports whether err means the debrid account cannot serve requests for now:
+// a bad or blocked API key, a missing subscription or a quota. Another account may succeed.
+func IsAccountError(err error) bool {
+	var debridErr *DebridError
+	if errors.As(err, &debridErr) {
+		return allDebridAccountErrors[debridErr.Code]
+	}
+
+	var rdErr *realdebrid.APIError
+	if errors.As(err, &rdErr) {
+		return realDebridAccountErrors[rdErr.Code] || isAccountStatus(rdErr.StatusCode)
+	}
+
+	var tbErr *torbox.APIError
+	if errors.As(err, &tbErr) {
+		return torBoxAccountErrors[tbErr.Code] || isAccountStatus(tbErr.StatusCode)
+	}
+
+	var pmErr *premiumize.APIError
+	if errors.As(err, &pmErr) {
+		return isAccountStatus(pmErr.StatusCode) || isPremiumizeAccountError(pmErr.Code+" "+pmErr.Message)
+	}
+
+	return false
+}
+
+// isPremiumizeAccountError reports whether a Premiumize error code or message is about the account
+func isPremiumizeAccountError(text string) bool {
+	text = strings.ToLower(text)
+	for _, fragment := range premiumizeAccountErrors {
+		if strings.Contains(text, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAccountStatus reports whether an HTTP status rejects the account
+func isAccountStatus(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusTooManyRequests
+}
+
+// AccountHealth remembers the debrid accounts that recently failed with an account error,
+// so that they are tried last for a while. A nil AccountHealth is valid and remembers nothing.
+type AccountHealth struct {
+	mu          sync.Mutex
+	failedUntil map[string]time.Time // account fingerprint -> end of the cooldown
+}
+
+// NewAccountHealth creates an empty account health tracker.
+func NewAccountHealth() *AccountHealth {
+	return &AccountHealth{
+		failedUntil: make(map[string]time.Time),
+	}
+}
+
+// healthy reports whether the account is outside its cooldown
+func (h *AccountHealth) healthy(fingerprint string) bool {
+	if h == nil {
+		return true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	until, failed := h.failedUntil[fingerprint]
+	if failed && time.Now().After(until) {
+		delete(h.failedUntil, fingerprint)
+		return true
+	}
+	return !failed
+}
+
+// markFailed starts the cooldown of an account
+func (h *AccountHealth) markFailed(fingerprint string) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failedUntil[fingerprint] = time.Now().Add(constants.DebridAccountCooldown)
+}
+
+// poolIDSeparator separates the owning account fingerprint from magnet IDs and file links
+const poolIDSeparator = "~"
+
+// poolAccount is an account of a pool with its fingerprint
+type poolAccount struct {
+	*DebridAccount
+	fingerprint string
+}
+
+// debridPool serves a user with several debrid accounts, in order. Torrents are uploaded to an
+// account having them cached, other uploads are spread across the healthy accounts by hash,
+// and every call fails over to the next account on account errors.
+// Magnet IDs and file links it returns are prefixed with the fingerprint of their account,
+// so that later calls reach the account owning them; API keys passed to it are ignored.
+type debridPool struct {
+	accounts []poolAccount
+	health   *AccountHealth
+	key      string
+	logger   logger.Logger
+
+	mu       sync.Mutex
+	cachedOn map[string]map[string]bool // lowercase hash -> fingerprints of the accounts having it cached
+}
+
+// NewDebridPool combines the accounts of a user into one account. The first account gives
+// the pool its provider ID and API key. A single account is returned unchanged.
+func NewDebridPool(accounts []*DebridAccount, health *AccountHealth) *DebridAccount {
+	if len(accounts) == 1 {
+		return accounts[0]
+	}
+
+	pool := &debridPool{health: health, logger: logger.New(), cachedOn: make(map[string]map[string]bool)}
+	var fingerprints []string
+	for _, account := range accounts {
+		fingerprint := database.AccountFingerprint(account.ProviderID, account.APIKey)
+		pool.accounts = append(pool.accounts, poolAccount{DebridAccount: account, fingerprint: fingerprint})
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	pool.key = strings.Join(fingerprints, ",")
+
+	return &DebridAccount{ProviderID: accounts[0].ProviderID, Provider: pool, APIKey: accounts[0].APIKey}
+}
+
+// Name returns the names of the providers of the pool
+func (p *debridPool) Name() string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, account := range p.accounts {
+		if name := account.Provider.Name(); !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "+")
+}
+
+// order returns the accounts to try: healthy ones first, starting at offset, then the others
+func (p *debridPool) order(offset int) []poolAccount {
+	var healthy, failed []poolAccount
+	for i := range p.accounts {
+		account := p.accounts[(offset+i)%len(p.accounts)]
+		if p.health.healthy(account.fingerprint) {
+			healthy = append(healthy, account)
+		} else {
+			failed = append(failed, account)
+		}
+	}
+	return append(healthy, failed...)
+}
+
+// available returns the healthy accounts, or every account when all of them are on cooldown
+func (p *debridPool) available() []poolAccount {
+	var healthy []poolAccount
+	for _, account := range p.accounts {
+		if p.health.healthy(account.fingerprint) {
+			healthy = append(healthy, account)
+		}
+	}
+	if len(healthy) == 0 {
+		return p.accounts
+	}
+	return healthy
+}
+
+// uploadOrder returns the accounts to upload a torrent to: those having it cached first, then
+// the others from an offset derived from the hash, so that uploads spread across accounts
+func (p *debridPool) uploadOrder(hash string) []poolAccount {
+	hash = strings.ToLower(hash)
+	sum := fnv.New32a()
+	sum.Write([]byte(hash))
+	accounts := p.order(int(sum.Sum32() % uint32(len(p.accounts))))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var cached, others []poolAccount
+	for _, account := range accounts {
+		if p.cachedOn[hash][account.fingerprint] {
+			cached = append(cached, account)
+		} else {
+			others = append(others, account)
+		}
+	}
+	return append(cached, others...)
+}
+
+// failover runs call on the accounts in order until one does not fail with an account error
+func (p *debridPool) failover(accounts []poolAccount, call func(poolAccount) error) error {
+	var err error
+	for _, account := range accounts {
+		if err = call(account); err == nil || !IsAccountError(err) {
+			return err
+		}
+		p.reportFailure(account, err)
+	}
+	return err
+}
+
+// reportFailure puts an account on cooldown after an account error
+func (p *debridPool) reportFailure(account poolAccount, err error) {
+	p.logger.Warnf("[%s] account %s unavailable, trying the next account: %v", account.Provider.Name(), account.fingerprint[:8], err)
+	p.health.markFailed(account.fingerprint)
+}
+
+// owner returns the account a prefixed magnet ID or link belongs to, and the unprefixed value
+func (p *debridPool) owner(value string) (poolAccount, string, bool) {
+	fingerprint, raw, found := strings.Cut(value, poolIDSeparator)
+	if !found {
+		return poolAccount{}, value, false
+	}
+	for _, account := range p.accounts {
+		if account.fingerprint == fingerprint {
+			return account, raw, true
+		}
+	}
+	return poolAccount{}, raw, false
+}
+
+// CheckInstantAvailability asks the healthy accounts concurrently. A hash is available when one
+// account has it cached, and the pool remembers which ones do for the upload that follows.
+func (p *debridPool) CheckInstantAvailability(ctx context.Context, hashes []string, _ string) (map[string]bool, error) {
+	accounts := p.available()
+	results := make([]map[string]bool, len(accounts))
+	errs := make([]error, len(accounts))
+	var wg sync.WaitGroup
+	for i, account := range accounts {
+		wg.Add(1)
+		go func(i int, account poolAccount) {
+			defer wg.Done()
+			results[i], errs[i] = account.Provider.CheckInstantAvailability(ctx, hashes, account.APIKey)
+		}(i, account)
+	}
+	wg.Wait()
+
+	availability := make(map[string]bool, len(hashes))
+	answered := false
+	var lastErr error
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, account := range accounts {
+		if errs[i] != nil {
+			if IsAccountError(errs[i]) {
+				p.reportFailure(account, errs[i])
+			}
+			lastErr = errs[i]
+			continue
+		}
+		answered = true
+		for hash, cached := range results[i] {
+			availability[hash] = availability[hash] || cached
+			if !cached {
+				continue
+			}
+			key := strings.ToLower(hash)
+			if p.cachedOn[key] == nil {
+				p.cachedOn[key] = make(map[string]bool)
+			}
+			p.cachedOn[key][account.fingerprint] = true
+		}
+	}
+	if !answered {
+		return nil, lastErr
+	}
+	return availability, nil
+}
+
+func (p *debridPool) UploadMagnet(ctx context.Context, hash, title, _ string) (string, error) {
+	var magnetID string
+	err := p.failover(p.uploadOrder(hash), func(account poolAccount) error {
+		id, err := account.Provider.UploadMagnet(ctx, hash, title, account.APIKey)
+		if err == nil {
+			magnetID = account.fingerprint + poolIDSeparator + id
+		}
+		return err
+	})
+	return magnetID, err
+}
+
+// CheckMagnets asks each account about the magnets it owns; magnets without owner go through failover
+func (p *debridPool) CheckMagnets(ctx context.Context, magnets []models.MagnetInfo, _ string) ([]models.ProcessedMagnet, error) {
+	owned := make(map[string][]models.MagnetInfo)
+	var unowned []models.MagnetInfo
+	for _, magnet := range magnets {
+		account, id, found := p.owner(magnet.ID)
+		magnet.ID = id
+		if found {
+			owned[account.fingerprint] = append(owned[account.fingerprint], magnet)
+		} else {
+			unowned = append(unowned, magnet)
+		}
+	}
+
+	var processed []models.ProcessedMagnet
+	var lastErr error
+	for _, account := range p.accounts {
+		if len(owned[account.fingerprint]) == 0 {
+			continue
+		}
+		results, err := account.Provider.CheckMagnets(ctx, owned[account.fingerprint], account.APIKey)
+		if err != nil {
+			if IsAccountError(err) {
+				p.reportFailure(account, err)
+			}
+			lastErr = err
+			continue
+		}
+		processed = append(processed, p.claim(account, results)...)
+	}
+
+	if len(unowned) > 0 {
+		err := p.failover(p.order(0), func(account poolAccount) error {
+			results, err := account.Provider.CheckMagnets(ctx, unowned, account.APIKey)
+			if err == nil {
+				processed = append(processed, p.claim(account, results)...)
+			}
+			return err
+		})
+		if err != nil {
+			lastErr = err
+		}
+	}
+
+	if len(processed) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return processed, nil
+}
+
+// claim prefixes the magnet IDs and file links of an account's results with its fingerprint
+func (p *debridPool) claim(account poolAccount, magnets []models.ProcessedMagnet) []models.ProcessedMagnet {
+	prefix := account.fingerprint + poolIDSeparator
+	for i := range magnets {
+		magnets[i].ID = prefix + magnets[i].ID
+		links := make([]interface{}, len(magnets[i].Links))
+		for j, raw := range magnets[i].Links {
+			links[j] = raw
+			file, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			claimed := make(map[string]interface{}, len(file))
+			for key, value := range file {
+				claimed[key] = value
+			}
+			if link, ok := file["link"].(string); ok {
+				claimed["link"] = prefix + link
+			}
+			links[j] = claimed
+		}
+		magnets[i].Links = links
+	}
+	return magnets
+}
+
+func (p *debridPool) UnlockLink(ctx context.Context, link, _ string) (string, error) {
+	account, raw, found := p.owner(link)
+	if !found {
+		return "", fmt.Errorf("link of an unknown debrid account")
+	}
+	url, err := account.Provider.UnlockLink(ctx, raw, account.APIKey)
+	if IsAccountError(err) {
+		p.reportFailure(account, err)
+	}
+	return url, err
+}
+
+func (p *debridPool) DeleteMagnet(ctx context.Context, magnetID, _ string) error {
+	account, raw, found := p.owner(magnetID)
+	if !found {
+		return fmt.Errorf("magnet of an unknown debrid account")
+	}
+	return account.Provider.DeleteMagnet(ctx, raw, account.APIKey)
+}
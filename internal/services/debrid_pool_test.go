@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/amaumene/gostremiofr/internal/database"
+	"github.com/amaumene/gostremiofr/internal/models"
+	"github.com/amaumene/gostremiofr/pkg/premiumize"
+	"github.com/amaumene/gostremiofr/pkg/realdebrid"
+	"github.com/amaumene/gostremiofr/pkg/torbox"
+)
+
+// fakeDebrid is a debrid provider answering from memory and recording its calls
+type fakeDebrid struct {
+	name   string
+	err    error           // returned by every call when set
+	cached map[string]bool // lowercase hashes the account has cached
+
+	mu    sync.Mutex
+	calls []string // "operation:argument" of each call
+}
+
+func (f *fakeDebrid) record(call string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+}
+
+func (f *fakeDebrid) recorded() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+func (f *fakeDebrid) Name() string { return f.name }
+
+func (f *fakeDebrid) CheckInstantAvailability(ctx context.Context, hashes []string, apiKey string) (map[string]bool, error) {
+	f.record("check:" + apiKey)
+	if f.err != nil {
+		return nil, f.err
+	}
+	availability := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		availability[hash] = f.cached[hash]
+	}
+	return availability, nil
+}
+
+func (f *fakeDebrid) UploadMagnet(ctx context.Context, hash, title, apiKey string) (string, error) {
+	f.record("upload:" + hash)
+	if f.err != nil {
+		return "", f.err
+	}
+	return f.name + "-" + hash, nil
+}
+
+func (f *fakeDebrid) CheckMagnets(ctx context.Context, magnets []models.MagnetInfo, apiKey string) ([]models.ProcessedMagnet, error) {
+	var processed []models.ProcessedMagnet
+	for _, magnet := range magnets {
+		f.record("status:" + magnet.ID)
+		processed = append(processed, models.ProcessedMagnet{
+			ID:    magnet.ID,
+			Ready: true,
+			Links: []interface{}{map[string]interface{}{"link": "file-" + magnet.ID, "filename": "video.mkv"}},
+		})
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return processed, nil
+}
+
+func (f *fakeDebrid) UnlockLink(ctx context.Context, link, apiKey string) (string, error) {
+	f.record("unlock:" + link)
+	if f.err != nil {
+		return "", f.err
+	}
+	return "https://" + f.name + "/" + link, nil
+}
+
+func (f *fakeDebrid) DeleteMagnet(ctx context.Context, magnetID, apiKey string) error {
+	f.record("delete:" + magnetID)
+	return f.err
+}
+
+// newTestPool pools one account per fake provider, keyed "key-<name>"
+func newTestPool(health *AccountHealth, providers ...*fakeDebrid) (*DebridAccount, *debridPool) {
+	var accounts []*DebridAccount
+	for _, provider := range providers {
+		accounts = append(accounts, &DebridAccount{ProviderID: provider.name, Provider: provider, APIKey: "key-" + provider.name})
+	}
+	account := NewDebridPool(accounts, health)
+	return account, account.Provider.(*debridPool)
+}
+
+func fingerprint(provider *fakeDebrid) string {
+	return database.AccountFingerprint(provider.name, "key-"+provider.name)
+}
+
+func TestIsAccountError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"alldebrid bad key", &DebridError{Provider: "AllDebrid", Code: "AUTH_BAD_APIKEY"}, true},
+		{"alldebrid quota", &DebridError{Provider: "AllDebrid", Code: "MAGNET_TOO_MANY_ACTIVE"}, true},
+		{"alldebrid bad magnet", &DebridError{Provider: "AllDebrid", Code: "MAGNET_INVALID_ID"}, false},
+		{"realdebrid bad token", &realdebrid.APIError{StatusCode: 401, Code: 8}, true},
+		{"realdebrid too many requests", &realdebrid.APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{"realdebrid unknown resource", &realdebrid.APIError{StatusCode: 404, Code: 7}, false},
+		{"torbox active limit", &torbox.APIError{StatusCode: 200, Code: "ACTIVE_LIMIT"}, true},
+		{"torbox forbidden", &torbox.APIError{StatusCode: http.StatusForbidden}, true},
+		{"torbox missing torrent", &torbox.APIError{StatusCode: 404, Code: "UNKNOWN_TORRENT"}, false},
+		{"premiumize not logged in", &premiumize.APIError{StatusCode: 200, Message: "Not logged in."}, true},
+		{"premiumize auth code", &premiumize.APIError{StatusCode: 200, Code: "auth", Message: "Bad key"}, true},
+		{"premiumize unauthorized", &premiumize.APIError{StatusCode: http.StatusUnauthorized}, true},
+		{"premiumize bad magnet", &premiumize.APIError{StatusCode: 200, Message: "Invalid hash"}, false},
+		{"wrapped account error", fmt.Errorf("failed to upload: %w", &DebridError{Code: "AUTH_BLOCKED"}), true},
+		{"plain error", errors.New("connection refused"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsAccountError(tt.err); got != tt.want {
+			t.Errorf("%s: IsAccountError(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestNewDebridPoolSingleAccount(t *testing.T) {
+	provider := &fakeDebrid{name: "one"}
+	account := &DebridAccount{ProviderID: "one", Provider: provider, APIKey: "key-one"}
+	if pooled := NewDebridPool([]*DebridAccount{account}, nil); pooled != account {
+		t.Errorf("single account pooled as %+v", pooled)
+	}
+}
+
+func TestDebridPoolUploadFailover(t *testing.T) {
+	blocked := &fakeDebrid{name: "blocked", err: &DebridError{Provider: "AllDebrid", Code: "AUTH_BLOCKED"}}
+	healthy := &fakeDebrid{name: "healthy"}
+	health := NewAccountHealth()
+	account, _ := newTestPool(health, blocked, healthy)
+
+	// Hashes spread uploads across accounts, so try several until one starts on the blocked account
+	for _, hash := range []string{"aaa", "bbb", "ccc", "ddd"} {
+		magnetID, err := account.Provider.UploadMagnet(context.Background(), hash, "title", "")
+		if err != nil {
+			t.Fatalf("UploadMagnet(%s): %v", hash, err)
+		}
+		if want := fingerprint(healthy) + poolIDSeparator + "healthy-" + hash; magnetID != want {
+			t.Errorf("UploadMagnet(%s) = %q, want %q", hash, magnetID, want)
+		}
+	}
+
+	// The blocked account failed once, then stays on cooldown
+	if calls := blocked.recorded(); len(calls) != 1 {
+		t.Errorf("blocked account calls = %v, want a single upload", calls)
+	}
+	if health.healthy(fingerprint(blocked)) {
+		t.Error("blocked account is not on cooldown")
+	}
+	if !health.healthy(fingerprint(healthy)) {
+		t.Error("healthy account is on cooldown")
+	}
+}
+
+func TestDebridPoolRequestErrorDoesNotFailOver(t *testing.T) {
+	first := &fakeDebrid{name: "first", err: errors.New("invalid magnet")}
+	second := &fakeDebrid{name: "second", err: errors.New("invalid magnet")}
+	health := NewAccountHealth()
+	account, _ := newTestPool(health, first, second)
+
+	if _, err := account.Provider.UploadMagnet(context.Background(), "aaa", "title", ""); err == nil {
+		t.Fatal("UploadMagnet succeeded")
+	}
+	if calls := len(first.recorded()) + len(second.recorded()); calls != 1 {
+		t.Errorf("request error reached %d accounts, want 1", calls)
+	}
+	if !health.healthy(fingerprint(first)) || !health.healthy(fingerprint(second)) {
+		t.Error("request error put an account on cooldown")
+	}
+}
+
+func TestDebridPoolAllAccountsOnCooldown(t *testing.T) {
+	first := &fakeDebrid{name: "first"}
+	second := &fakeDebrid{name: "second"}
+	health := NewAccountHealth()
+	health.markFailed(fingerprint(first))
+	health.markFailed(fingerprint(second))
+	account, pool := newTestPool(health, first, second)
+
+	if got := len(pool.available()); got != 2 {
+		t.Errorf("available() returned %d accounts, want every account", got)
+	}
+	if _, err := account.Provider.CheckInstantAvailability(context.Background(), []string{"aaa"}, ""); err != nil {
+		t.Fatalf("CheckInstantAvailability: %v", err)
+	}
+	if len(first.recorded()) != 1 || len(second.recorded()) != 1 {
+		t.Errorf("calls = %v and %v, want one per account", first.recorded(), second.recorded())
+	}
+}
+
+func TestDebridPoolUploadsToAccountHavingTorrentCached(t *testing.T) {
+	without := &fakeDebrid{name: "without"}
+	with := &fakeDebrid{name: "with", cached: map[string]bool{"aaa": true, "bbb": true, "ccc": true}}
+	account, _ := newTestPool(nil, without, with)
+
+	hashes := []string{"aaa", "bbb", "ccc"}
+	availability, err := account.Provider.CheckInstantAvailability(context.Background(), hashes, "")
+	if err != nil {
+		t.Fatalf("CheckInstantAvailability: %v", err)
+	}
+	for _, hash := range hashes {
+		if !availability[hash] {
+			t.Errorf("%s is not available", hash)
+		}
+		magnetID, err := account.Provider.UploadMagnet(context.Background(), hash, "title", "")
+		if err != nil {
+			t.Fatalf("UploadMagnet(%s): %v", hash, err)
+		}
+		if want := fingerprint(with) + poolIDSeparator + "with-" + hash; magnetID != want {
+			t.Errorf("UploadMagnet(%s) = %q, want %q", hash, magnetID, want)
+		}
+	}
+}
+
+func TestDebridPoolRoutesPrefixedIDs(t *testing.T) {
+	first := &fakeDebrid{name: "first"}
+	second := &fakeDebrid{name: "second"}
+	account, _ := newTestPool(nil, first, second)
+	ctx := context.Background()
+
+	firstID := fingerprint(first) + poolIDSeparator + "m1"
+	secondID := fingerprint(second) + poolIDSeparator + "m2"
+	magnets, err := account.Provider.CheckMagnets(ctx, []models.MagnetInfo{{ID: firstID}, {ID: secondID}}, "")
+	if err != nil {
+		t.Fatalf("CheckMagnets: %v", err)
+	}
+	if len(magnets) != 2 {
+		t.Fatalf("CheckMagnets returned %d magnets, want 2", len(magnets))
+	}
+	links := make(map[string]string)
+	for _, magnet := range magnets {
+		links[magnet.ID] = magnet.Links[0].(map[string]interface{})["link"].(string)
+	}
+	if want := fingerprint(first) + poolIDSeparator + "file-m1"; links[firstID] != want {
+		t.Errorf("link of %s = %q, want %q", firstID, links[firstID], want)
+	}
+	if want := fingerprint(second) + poolIDSeparator + "file-m2"; links[secondID] != want {
+		t.Errorf("link of %s = %q, want %q", secondID, links[secondID], want)
+	}
+
+	url, err := account.Provider.UnlockLink(ctx, links[secondID], "")
+	if err != nil {
+		t.Fatalf("UnlockLink: %v", err)
+	}
+	if url != "https://second/file-m2" {
+		t.Errorf("UnlockLink = %q, want the second account's URL", url)
+	}
+	if err := account.Provider.DeleteMagnet(ctx, firstID, ""); err != nil {
+		t.Fatalf("DeleteMagnet: %v", err)
+	}
+
+	wantFirst := []string{"status:m1", "delete:m1"}
+	wantSecond := []string{"status:m2", "unlock:file-m2"}
+	if got := first.recorded(); fmt.Sprint(got) != fmt.Sprint(wantFirst) {
+		t.Errorf("first account calls = %v, want %v", got, wantFirst)
+	}
+	if got := second.recorded(); fmt.Sprint(got) != fmt.Sprint(wantSecond) {
+		t.Errorf("second account calls = %v, want %v", got, wantSecond)
+	}
+
+	if got := account.MagnetOwner(secondID); got != fingerprint(second) {
+		t.Errorf("MagnetOwner(%s) = %q, want %q", secondID, got, fingerprint(second))
+	}
+	if want := fingerprint(first) + "," + fingerprint(second); account.Fingerprint() != want {
+		t.Errorf("Fingerprint() = %q, want %q", account.Fingerprint(), want)
+	}
+}
+
+func TestDebridPoolRejectsUnknownAccount(t *testing.T) {
+	account, _ := newTestPool(nil, &fakeDebrid{name: "first"}, &fakeDebrid{name: "second"})
+	ctx := context.Background()
+
+	if _, err := account.Provider.UnlockLink(ctx, "unknown"+poolIDSeparator+"file", ""); err == nil {
+		t.Error("UnlockLink accepted a link of an unknown account")
+	}
+	if _, err := account.Provider.UnlockLink(ctx, "file", ""); err == nil {
+		t.Error("UnlockLink accepted a link without account")
+	}
+	if err := account.Provider.DeleteMagnet(ctx, "m1", ""); err == nil {
+		t.Error("DeleteMagnet accepted a magnet without account")
+	}
+}
+
+func TestDebridPoolUnownedMagnetsFailOver(t *testing.T) {
+	blocked := &fakeDebrid{name: "blocked", err: &torbox.APIError{StatusCode: http.StatusUnauthorized, Code: "BAD_TOKEN"}}
+	healthy := &fakeDebrid{name: "healthy"}
+	account, _ := newTestPool(NewAccountHealth(), blocked, healthy)
+
+	magnets, err := account.Provider.CheckMagnets(context.Background(), []models.MagnetInfo{{ID: "m1"}}, "")
+	if err != nil {
+		t.Fatalf("CheckMagnets: %v", err)
+	}
+	if len(magnets) != 1 || magnets[0].ID != fingerprint(healthy)+poolIDSeparator+"m1" {
+		t.Errorf("CheckMagnets = %+v, want m1 claimed by the healthy account", magnets)
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/amaumene/gostremiofr/internal/health"
+	"github.com/amaumene/gostremiofr/internal/metrics"
+	"github.com/amaumene/gostremiofr/internal/models"
+	"github.com/amaumene/gostremiofr/internal/tracing"
+	"github.com/amaumene/gostremiofr/pkg/ratelimiter"
+	"github.com/amaumene/gostremiofr/pkg/realdebrid"
+	"github.com/amaumene/gostremiofr/pkg/torbox"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the spans of debrid calls
+var tracer = otel.Tracer("github.com/amaumene/gostremiofr/internal/services")
+
+// newRateLimiter creates a token bucket whose waits are exported under the limiter name
+func newRateLimiter(name string, rate, burst int64) *ratelimiter.TokenBucket {
+	limiter := ratelimiter.NewTokenBucket(rate, burst)
+	limiter.OnWait(metrics.RateLimiterObserver(name))
+	return limiter
+}
+
+// measuredDebrid records the latency and error code of every debrid call, and traces it
+// with a debrid.<operation> span
+type measuredDebrid struct {
+	DebridProvider
+}
+
+// begin starts a call of an operation; the returned function records its outcome
+func (m *measuredDebrid) begin(ctx context.Context, operation string) (context.Context, func(error)) {
+	provider := m.DebridProvider.Name()
+	ctx, span := tracer.Start(ctx, "debrid."+operation, trace.WithAttributes(attribute.String("debrid.provider", provider)))
+	start := time.Now()
+
+	return ctx, func(err error) {
+		code := debridErrorCode(err)
+		metrics.DebridRequestDuration.ObserveSince(start, provider, operation)
+		metrics.DebridRequests.Inc(provider, operation, code)
+		health.Upstreams.Record(health.KindDebrid, provider, err)
+		span.SetAttributes(attribute.String("debrid.code", code))
+		tracing.End(span, err)
+	}
+}
+
+func (m *measuredDebrid) CheckInstantAvailability(ctx context.Context, hashes []string, apiKey string) (map[string]bool, error) {
+	ctx, done := m.begin(ctx, "instant_availability")
+	availability, err := m.DebridProvider.CheckInstantAvailability(ctx, hashes, apiKey)
+	done(err)
+	return availability, err
+}
+
+func (m *measuredDebrid) UploadMagnet(ctx context.Context, hash, title, apiKey string) (string, error) {
+	ctx, done := m.begin(ctx, "upload_magnet")
+	magnetID, err := m.DebridProvider.UploadMagnet(ctx, hash, title, apiKey)
+	done(err)
+	return magnetID, err
+}
+
+func (m *measuredDebrid) CheckMagnets(ctx context.Context, magnets []models.MagnetInfo, apiKey string) ([]models.ProcessedMagnet, error) {
+	ctx, done := m.begin(ctx, "check_magnets")
+	processed, err := m.DebridProvider.CheckMagnets(ctx, magnets, apiKey)
+	done(err)
+	return processed, err
+}
+
+func (m *measuredDebrid) UnlockLink(ctx context.Context, link, apiKey string) (string, error) {
+	ctx, done := m.begin(ctx, "unlock_link")
+	directURL, err := m.DebridProvider.UnlockLink(ctx, link, apiKey)
+	done(err)
+	return directURL, err
+}
+
+func (m *measuredDebrid) DeleteMagnet(ctx context.Context, magnetID, apiKey string) error {
+	ctx, done := m.begin(ctx, "delete_magnet")
+	err := m.DebridProvider.DeleteMagnet(ctx, magnetID, apiKey)
+	done(err)
+	return err
+}
+
+// debridErrorCode returns the metric code of a debrid call result: "ok", the API error code,
+// "timeout", "canceled" or "error" for other failures
+func debridErrorCode(err error) string {
+	if err == nil {
+		return "ok"
+	}
+
+	var debridErr *DebridError
+	if errors.As(err, &debridErr) && debridErr.Code != "" {
+		return debridErr.Code
+	}
+	var rdErr *realdebrid.APIError
+	if errors.As(err, &rdErr) {
+		if rdErr.Code != 0 {
+			return strconv.Itoa(rdErr.Code)
+		}
+		return strconv.Itoa(rdErr.StatusCode)
+	}
+	var tbErr *torbox.APIError
+	if errors.As(err, &tbErr) && tbErr.Code != "" {
+		return tbErr.Code
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	}
+	return "error"
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/amaumene/gostremiofr/internal/constants"
+	"github.com/amaumene/gostremiofr/internal/models"
+	"github.com/amaumene/gostremiofr/pkg/logger"
+	"github.com/amaumene/gostremiofr/pkg/premiumize"
+	"github.com/amaumene/gostremiofr/pkg/ratelimiter"
+	"github.com/amaumene/gostremiofr/pkg/security"
+)
+
+// Premiumize serves cached torrents through direct download links.
+// Nothing is added to the account, so uploads and deletions are no-ops.
+type Premiumize struct {
+	rateLimiter *ratelimiter.TokenBucket
+	client      *premiumize.Client
+	logger      logger.Logger
+	validator   *security.APIKeyValidator
+}
+
+func NewPremiumize() *Premiumize {
+	return &Premiumize{
+		rateLimiter: newRateLimiter("premiumize", constants.PremiumizeRateLimit, constants.PremiumizeRateBurst),
+		client:      premiumize.NewClient(),
+		logger:      logger.New(),
+		validator:   security.NewAPIKeyValidator(),
+	}
+}
+
+// Name returns the provider display name
+func (p *Premiumize) Name() string {
+	return "Premiumize"
+}
+
+// CheckInstantAvailability reports which hashes are in the Premiumize cache
+func (p *Premiumize) CheckInstantAvailability(ctx context.Context, hashes []string, apiKey string) (map[string]bool, error) {
+	apiKey, err := p.validateAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := make(map[string]bool, len(hashes))
+	if len(hashes) == 0 {
+		return availability, nil
+	}
+
+	if err := p.rateLimiter.WaitContext(ctx); err != nil {
+		return nil, err
+	}
+
+	p.logger.Debugf("[Premiumize] checking cache for %d hashes", len(hashes))
+
+	resp, err := p.client.CheckCache(ctx, apiKey, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check instant availability: %w", err)
+	}
+
+	for i, hash := range hashes {
+		availability[strings.ToLower(hash)] = i < len(resp.Response) && resp.Response[i]
+	}
+
+	return availability, nil
+}
+
+// UploadMagnet is a no-op: cached content is resolved directly by CheckMagnets
+func (p *Premiumize) UploadMagnet(ctx context.Context, hash, title, apiKey string) (string, error) {
+	if _, err := p.validateAPIKey(apiKey); err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+// CheckMagnets resolves each magnet into its files; a magnet is ready when the cache returns files
+func (p *Premiumize) CheckMagnets(ctx context.Context, magnets []models.MagnetInfo, apiKey string) ([]models.ProcessedMagnet, error) {
+	apiKey, err := p.validateAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var processed []models.ProcessedMagnet
+	for _, magnet := range magnets {
+		if err := p.rateLimiter.WaitContext(ctx); err != nil {
+			return nil, err
+		}
+
+		resp, err := p.client.DirectDL(ctx, apiKey, buildMagnetURL(magnet.Hash, magnet.Title))
+		if err != nil {
+			// An account error fails every magnet, let the caller fail over to another account
+			if IsAccountError(err) {
+				return nil, fmt.Errorf("failed to resolve magnet %s: %w", magnet.Hash, err)
+			}
+			p.logger.Debugf("[Premiumize] magnet not available - %s: %v", magnet.Title, err)
+			processed = append(processed, models.ProcessedMagnet{Hash: magnet.Hash, Name: magnet.Title, Source: magnet.Source})
+			continue
+		}
+
+		var links []interface{}
+		var size float64
+		for _, file := range resp.Content {
+			links = append(links, map[string]interface{}{
+				"link":     file.Link,
+				"filename": path.Base(file.Path),
+				"path":     file.Path,
+				"size":     float64(file.Size),
+			})
+			size += float64(file.Size)
+		}
+
+		p.logger.Infof("[Premiumize] magnet status - %s: %d files", magnet.Title, len(links))
+
+		processed = append(processed, models.ProcessedMagnet{
+			Hash:   magnet.Hash,
+			Ready:  len(links) > 0,
+			Name:   magnet.Title,
+			Size:   size,
+			Source: magnet.Source,
+			Links:  links,
+		})
+	}
+
+	return processed, nil
+}
+
+// UnlockLink returns the link unchanged; Premiumize direct download links are already playable
+func (p *Premiumize) UnlockLink(ctx context.Context, link, apiKey string) (string, error) {
+	if link == "" {
+		return "", fmt.Errorf("no direct link available")
+	}
+	return link, nil
+}
+
+// DeleteMagnet is a no-op since no transfer is created
+func (p *Premiumize) DeleteMagnet(ctx context.Context, magnetID, apiKey string) error {
+	return nil
+}
+
+func (p *Premiumize) validateAPIKey(apiKey string) (string, error) {
+	apiKey = p.validator.SanitizeAPIKey(apiKey)
+	if !p.validator.ValidateAPIKey(apiKey) {
+		p.logger.Errorf("invalid API key format (key: %s)", p.validator.MaskAPIKey(apiKey))
+		return "", fmt.Errorf("invalid Premiumize API key format")
+	}
+	return apiKey, nil
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/amaumene/gostremiofr/internal/constants"
+	"github.com/amaumene/gostremiofr/internal/models"
+	"github.com/amaumene/gostremiofr/pkg/logger"
+	"github.com/amaumene/gostremiofr/pkg/ratelimiter"
+	"github.com/amaumene/gostremiofr/pkg/realdebrid"
+	"github.com/amaumene/gostremiofr/pkg/security"
+)
+
+const (
+	// Real-Debrid torrent status meaning all files are available
+	realDebridStatusDownloaded = "downloaded"
+	// Host key listing cached variants in instant availability responses
+	realDebridCacheHost = "rd"
+)
+
+type RealDebrid struct {
+	rateLimiter *ratelimiter.TokenBucket
+	client      *realdebrid.Client
+	logger      logger.Logger
+	validator   *security.APIKeyValidator
+}
+
+func NewRealDebrid() *RealDebrid {
+	return &RealDebrid{
+		rateLimiter: newRateLimiter("realdebrid", constants.RealDebridRateLimit, constants.RealDebridRateBurst),
+		client:      realdebrid.NewClient(),
+		logger:      logger.New(),
+		validator:   security.NewAPIKeyValidator(),
+	}
+}
+
+// Name returns the provider display name
+func (r *RealDebrid) Name() string {
+	return "RealDebrid"
+}
+
+// CheckInstantAvailability reports which hashes have a cached variant on Real-Debrid
+func (r *RealDebrid) CheckInstantAvailability(ctx context.Context, hashes []string, apiKey string) (map[string]bool, error) {
+	apiKey, err := r.validateAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := make(map[string]bool, len(hashes))
+	if len(hashes) == 0 {
+		return availability, nil
+	}
+
+	if err := r.rateLimiter.WaitContext(ctx); err != nil {
+		return nil, err
+	}
+
+	r.logger.Debugf("[RealDebrid] checking instant availability for %d hashes", len(hashes))
+
+	resp, err := r.client.InstantAvailability(ctx, apiKey, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check instant availability: %w", err)
+	}
+
+	for _, hash := range hashes {
+		availability[strings.ToLower(hash)] = false
+	}
+	for hash, variants := range resp {
+		availability[strings.ToLower(hash)] = hasCachedVariant(variants)
+	}
+
+	return availability, nil
+}
+
+// UploadMagnet adds the magnet and selects all of its files so links become available
+func (r *RealDebrid) UploadMagnet(ctx context.Context, hash, title, apiKey string) (string, error) {
+	apiKey, err := r.validateAPIKey(apiKey)
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.rateLimiter.WaitContext(ctx); err != nil {
+		return "", err
+	}
+
+	magnetURL := buildMagnetURL(hash, title)
+	r.logger.Debugf("[RealDebrid] uploading magnet URL: %s", magnetURL)
+
+	resp, err := r.client.AddMagnet(ctx, apiKey, magnetURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload magnet: %w", err)
+	}
+
+	if err := r.rateLimiter.WaitContext(ctx); err != nil {
+		return "", err
+	}
+
+	if err := r.client.SelectFiles(ctx, apiKey, resp.ID, "all"); err != nil {
+		r.logger.Debugf("[RealDebrid] failed to select files for torrent %s: %v", resp.ID, err)
+	}
+
+	return resp.ID, nil
+}
+
+// CheckMagnets fetches the status and file links of each uploaded torrent
+func (r *RealDebrid) CheckMagnets(ctx context.Context, magnets []models.MagnetInfo, apiKey string) ([]models.ProcessedMagnet, error) {
+	apiKey, err := r.validateAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var processed []models.ProcessedMagnet
+	for _, magnet := range magnets {
+		if magnet.ID == "" {
+			continue
+		}
+
+		if err := r.rateLimiter.WaitContext(ctx); err != nil {
+			return nil, err
+		}
+
+		info, err := r.client.GetTorrentInfo(ctx, apiKey, magnet.ID)
+		if err != nil {
+			// An account error fails every magnet, let the caller fail over to another account
+			if IsAccountError(err) {
+				return nil, fmt.Errorf("failed to get torrent %s: %w", magnet.ID, err)
+			}
+			r.logger.Warnf("[RealDebrid] failed to get torrent %s: %v", magnet.ID, err)
+			continue
+		}
+
+		links := r.buildLinks(info)
+		ready := info.Status == realDebridStatusDownloaded && len(links) > 0
+
+		r.logger.Infof("[RealDebrid] magnet status - %s: %s (links: %d)", magnet.Title, info.Status, len(links))
+
+		processed = append(processed, models.ProcessedMagnet{
+			Hash:   magnet.Hash,
+			Ready:  ready,
+			Name:   info.Filename,
+			Size:   float64(info.Bytes),
+			ID:     info.ID,
+			Source: magnet.Source,
+			Links:  links,
+		})
+	}
+
+	return processed, nil
+}
+
+func (r *RealDebrid) UnlockLink(ctx context.Context, link, apiKey string) (string, error) {
+	apiKey, err := r.validateAPIKey(apiKey)
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.rateLimiter.WaitContext(ctx); err != nil {
+		return "", err
+	}
+
+	r.logger.Debugf("[RealDebrid] unlocking link: %s", link)
+
+	resp, err := r.client.UnrestrictLink(ctx, apiKey, link)
+	if err != nil {
+		return "", fmt.Errorf("failed to unlock link: %w", err)
+	}
+
+	if resp.Download == "" {
+		return "", fmt.Errorf("no direct link returned from unrestrict API")
+	}
+
+	return resp.Download, nil
+}
+
+// DeleteMagnet deletes a torrent from Real-Debrid
+func (r *RealDebrid) DeleteMagnet(ctx context.Context, magnetID, apiKey string) error {
+	apiKey, err := r.validateAPIKey(apiKey)
+	if err != nil {
+		return err
+	}
+
+	if err := r.rateLimiter.WaitContext(ctx); err != nil {
+		return err
+	}
+
+	r.logger.Debugf("[RealDebrid] deleting torrent ID: %s", magnetID)
+
+	if err := r.client.DeleteTorrent(ctx, apiKey, magnetID); err != nil {
+		return fmt.Errorf("failed to delete magnet: %w", err)
+	}
+
+	r.logger.Infof("[RealDebrid] successfully deleted torrent ID: %s", magnetID)
+	return nil
+}
+
+func (r *RealDebrid) validateAPIKey(apiKey string) (string, error) {
+	apiKey = r.validator.SanitizeAPIKey(apiKey)
+	if !r.validator.ValidateAPIKey(apiKey) {
+		r.logger.Errorf("invalid API key format (key: %s)", r.validator.MaskAPIKey(apiKey))
+		return "", fmt.Errorf("invalid Real-Debrid API key format")
+	}
+	return apiKey, nil
+}
+
+// buildLinks pairs the selected files with their hoster links, which Real-Debrid returns in file order
+func (r *RealDebrid) buildLinks(info *realdebrid.TorrentInfo) []interface{} {
+	var links []interface{}
+	linkIndex := 0
+	for _, file := range info.Files {
+		if file.Selected != 1 {
+			continue
+		}
+		if linkIndex >= len(info.Links) {
+			break
+		}
+		links = append(links, map[string]interface{}{
+			"link":     info.Links[linkIndex],
+			"filename": path.Base(file.Path),
+			"path":     file.Path,
+			"size":     float64(file.Bytes),
+		})
+		linkIndex++
+	}
+	return links
+}
+
+// hasCachedVariant reports whether an instant availability entry lists at least one cached variant
+func hasCachedVariant(variants json.RawMessage) bool {
+	var hosts map[string][]json.RawMessage
+	if err := json.Unmarshal(variants, &hosts); err != nil {
+		return false
+	}
+	return len(hosts[realDebridCacheHost]) > 0
+}
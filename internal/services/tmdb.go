@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -41,7 +42,7 @@ func NewTMDB(apiKey string, cache *cache.LRUCache) *TMDB {
 	return &TMDB{
 		apiKey:      sanitizedKey,
 		cache:       cache,
-		rateLimiter: ratelimiter.NewTokenBucket(constants.TMDBRateLimit, constants.TMDBRateBurst),
+		rateLimiter: newRateLimiter("tmdb", constants.TMDBRateLimit, constants.TMDBRateBurst),
 		httpClient:  httputil.NewHTTPClient(10 * time.Second),
 		logger:      logger.New(),
 		validator:   validator,
@@ -52,17 +53,25 @@ func (t *TMDB) SetDB(db database.Database) {
 	t.db = db
 }
 
-func (t *TMDB) SetAPIKey(apiKey string) {
-	// Sanitize and validate the API key
+// WithAPIKey returns a view of the service that uses the given API key.
+// The view shares the cache, database, rate limiter and HTTP client, so it is cheap
+// to create per request and never changes the key seen by other callers.
+func (t *TMDB) WithAPIKey(apiKey string) TMDBService {
 	sanitizedKey := t.validator.SanitizeAPIKey(apiKey)
-	if apiKey != "" && !t.validator.IsValidTMDBKey(sanitizedKey) {
-		t.logger.Errorf("failed to set API key: invalid format (key: %s)", t.validator.MaskAPIKey(sanitizedKey))
-		return
+	if sanitizedKey == "" || sanitizedKey == t.apiKey {
+		return t
+	}
+	if !t.validator.IsValidTMDBKey(sanitizedKey) {
+		t.logger.Errorf("ignoring API key: invalid format (key: %s)", t.validator.MaskAPIKey(sanitizedKey))
+		return t
 	}
-	t.apiKey = sanitizedKey
+
+	scoped := *t
+	scoped.apiKey = sanitizedKey
+	return &scoped
 }
 
-func (t *TMDB) GetIMDBInfo(imdbID string) (string, string, string, int, string, error) {
+func (t *TMDB) GetIMDBInfo(ctx context.Context, imdbID string) (string, string, string, int, string, error) {
 	cacheKey := fmt.Sprintf("tmdb:%s", imdbID)
 
 	if result := t.checkMemoryCache(cacheKey); result != nil {
@@ -73,7 +82,7 @@ func (t *TMDB) GetIMDBInfo(imdbID string) (string, string, string, int, string,
 		return result.Type, result.Title, result.Title, result.Year, result.OriginalLanguage, nil
 	}
 
-	tmdbResp, err := t.fetchIMDBData(imdbID)
+	tmdbResp, err := t.fetchIMDBData(ctx, imdbID)
 	if err != nil {
 		return "", "", "", 0, "", err
 	}
@@ -90,7 +99,7 @@ func (t *TMDB) GetIMDBInfo(imdbID string) (string, string, string, int, string,
 }
 
 // GetTMDBInfo fetches info for a TMDB ID directly
-func (t *TMDB) GetTMDBInfo(tmdbID string) (string, string, string, int, string, error) {
+func (t *TMDB) GetTMDBInfo(ctx context.Context, tmdbID string) (string, string, string, int, string, error) {
 	id, err := t.extractTMDBNumericID(tmdbID)
 	if err != nil {
 		return "", "", "", 0, "", err
@@ -110,9 +119,11 @@ func (t *TMDB) GetTMDBInfo(tmdbID string) (string, string, string, int, string,
 		return "", "", "", 0, "", err
 	}
 
-	t.rateLimiter.Wait()
+	if err := t.rateLimiter.WaitContext(ctx); err != nil {
+		return "", "", "", 0, "", err
+	}
 
-	mediaType, title, originalLanguage, year, err := t.tryFetchTMDBData(id, tmdbID)
+	mediaType, title, originalLanguage, year, err := t.tryFetchTMDBData(ctx, id, tmdbID)
 	if err != nil {
 		return "", "", "", 0, "", err
 	}
@@ -129,22 +140,22 @@ func (t *TMDB) extractTMDBNumericID(tmdbID string) (string, error) {
 	return parts[1], nil
 }
 
-func (t *TMDB) tryFetchTMDBData(id, tmdbID string) (string, string, string, int, error) {
+func (t *TMDB) tryFetchTMDBData(ctx context.Context, id, tmdbID string) (string, string, string, int, error) {
 	// Try movie first
-	if mediaType, title, originalLanguage, year, err := t.tryFetchMovie(id, tmdbID); err == nil {
+	if mediaType, title, originalLanguage, year, err := t.tryFetchMovie(ctx, id, tmdbID); err == nil {
 		return mediaType, title, originalLanguage, year, nil
 	}
 
 	// Try TV if movie fails
-	return t.tryFetchTV(id, tmdbID)
+	return t.tryFetchTV(ctx, id, tmdbID)
 }
 
-func (t *TMDB) tryFetchMovie(id, tmdbID string) (string, string, string, int, error) {
+func (t *TMDB) tryFetchMovie(ctx context.Context, id, tmdbID string) (string, string, string, int, error) {
 	movieURL := fmt.Sprintf("https://api.themoviedb.org/3/movie/%s?api_key=%s", id, t.apiKey)
 	t.logger.Debugf("trying movie endpoint for TMDB ID %s", tmdbID)
 	t.logger.Debugf("[TMDB] API URL: %s", movieURL)
 
-	resp, err := t.httpClient.Get(movieURL)
+	resp, err := t.get(ctx, movieURL)
 	if err != nil {
 		return "", "", "", 0, err
 	}
@@ -168,12 +179,12 @@ func (t *TMDB) tryFetchMovie(id, tmdbID string) (string, string, string, int, er
 	return "movie", movie.OriginalTitle, movie.OriginalLanguage, year, nil
 }
 
-func (t *TMDB) tryFetchTV(id, tmdbID string) (string, string, string, int, error) {
+func (t *TMDB) tryFetchTV(ctx context.Context, id, tmdbID string) (string, string, string, int, error) {
 	tvURL := fmt.Sprintf("https://api.themoviedb.org/3/tv/%s?api_key=%s", id, t.apiKey)
 	t.logger.Debugf("trying TV endpoint for TMDB ID %s", tmdbID)
 	t.logger.Debugf("[TMDB] API URL: %s", tvURL)
 
-	resp, err := t.httpClient.Get(tvURL)
+	resp, err := t.get(ctx, tvURL)
 	if err != nil {
 		return "", "", "", 0, fmt.Errorf("failed to fetch TMDB data for %s: %w", tmdbID, err)
 	}
@@ -221,7 +232,7 @@ func (t *TMDB) cacheTMDBResult(cacheKey, tmdbID, mediaType, title string, year i
 }
 
 // GetTMDBInfoWithType fetches info for a TMDB ID with a specific media type
-func (t *TMDB) GetTMDBInfoWithType(tmdbID, mediaType string) (string, string, string, int, string, error) {
+func (t *TMDB) GetTMDBInfoWithType(ctx context.Context, tmdbID, mediaType string) (string, string, string, int, string, error) {
 	id, err := t.extractTMDBID(tmdbID)
 	if err != nil {
 		return "", "", "", 0, "", err
@@ -241,9 +252,11 @@ func (t *TMDB) GetTMDBInfoWithType(tmdbID, mediaType string) (string, string, st
 		return "", "", "", 0, "", err
 	}
 
-	t.rateLimiter.Wait()
+	if err := t.rateLimiter.WaitContext(ctx); err != nil {
+		return "", "", "", 0, "", err
+	}
 
-	tmdbData, err := t.fetchTMDBDetails(id, tmdbID, mediaType)
+	tmdbData, err := t.fetchTMDBDetails(ctx, id, tmdbID, mediaType)
 	if err != nil {
 		return "", "", "", 0, "", err
 	}
@@ -255,7 +268,7 @@ func (t *TMDB) GetTMDBInfoWithType(tmdbID, mediaType string) (string, string, st
 }
 
 // GetPopularMovies fetches popular movies from TMDB
-func (t *TMDB) GetPopularMovies(page int, genreID string) ([]models.Meta, error) {
+func (t *TMDB) GetPopularMovies(ctx context.Context, page int, genreID string) ([]models.Meta, error) {
 	cacheKey := fmt.Sprintf("tmdb:popular:movies:%d:%s", page, genreID)
 
 	if data, found := t.cache.Get(cacheKey); found {
@@ -266,7 +279,9 @@ func (t *TMDB) GetPopularMovies(page int, genreID string) ([]models.Meta, error)
 		return nil, err
 	}
 
-	t.rateLimiter.Wait()
+	if err := t.rateLimiter.WaitContext(ctx); err != nil {
+		return nil, err
+	}
 
 	url := fmt.Sprintf("https://api.themoviedb.org/3/movie/popular?api_key=%s&page=%d&region=FR",
 		t.apiKey, page)
@@ -278,7 +293,7 @@ func (t *TMDB) GetPopularMovies(page int, genreID string) ([]models.Meta, error)
 	t.logger.Debugf("fetching popular movies page %d", page)
 	t.logger.Debugf("[TMDB] API URL: %s", url)
 
-	resp, err := t.httpClient.Get(url)
+	resp, err := t.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch popular movies: %w", err)
 	}
@@ -300,7 +315,7 @@ func (t *TMDB) GetPopularMovies(page int, genreID string) ([]models.Meta, error)
 }
 
 // GetPopularSeries fetches popular TV series from TMDB
-func (t *TMDB) GetPopularSeries(page int, genreID string) ([]models.Meta, error) {
+func (t *TMDB) GetPopularSeries(ctx context.Context, page int, genreID string) ([]models.Meta, error) {
 	cacheKey := fmt.Sprintf("tmdb:popular:series:%d:%s", page, genreID)
 
 	if data, found := t.cache.Get(cacheKey); found {
@@ -311,7 +326,9 @@ func (t *TMDB) GetPopularSeries(page int, genreID string) ([]models.Meta, error)
 		return nil, err
 	}
 
-	t.rateLimiter.Wait()
+	if err := t.rateLimiter.WaitContext(ctx); err != nil {
+		return nil, err
+	}
 
 	url := fmt.Sprintf("https://api.themoviedb.org/3/tv/popular?api_key=%s&page=%d",
 		t.apiKey, page)
@@ -323,7 +340,7 @@ func (t *TMDB) GetPopularSeries(page int, genreID string) ([]models.Meta, error)
 	t.logger.Debugf("fetching popular series page %d", page)
 	t.logger.Debugf("[TMDB] API URL: %s", url)
 
-	resp, err := t.httpClient.Get(url)
+	resp, err := t.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch popular series: %w", err)
 	}
@@ -345,7 +362,7 @@ func (t *TMDB) GetPopularSeries(page int, genreID string) ([]models.Meta, error)
 }
 
 // GetTrending fetches trending content from TMDB
-func (t *TMDB) GetTrending(mediaType string, timeWindow string, page int) ([]models.Meta, error) {
+func (t *TMDB) GetTrending(ctx context.Context, mediaType string, timeWindow string, page int) ([]models.Meta, error) {
 	cacheKey := fmt.Sprintf("tmdb:trending:%s:%s:%d", mediaType, timeWindow, page)
 
 	if data, found := t.cache.Get(cacheKey); found {
@@ -356,7 +373,9 @@ func (t *TMDB) GetTrending(mediaType string, timeWindow string, page int) ([]mod
 		return nil, err
 	}
 
-	t.rateLimiter.Wait()
+	if err := t.rateLimiter.WaitContext(ctx); err != nil {
+		return nil, err
+	}
 
 	url := fmt.Sprintf("https://api.themoviedb.org/3/trending/%s/%s?api_key=%s&page=%d",
 		mediaType, timeWindow, t.apiKey, page)
@@ -364,7 +383,7 @@ func (t *TMDB) GetTrending(mediaType string, timeWindow string, page int) ([]mod
 	t.logger.Debugf("fetching trending %s for %s", mediaType, timeWindow)
 	t.logger.Debugf("[TMDB] API URL: %s", url)
 
-	resp, err := t.httpClient.Get(url)
+	resp, err := t.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch trending: %w", err)
 	}
@@ -395,14 +414,14 @@ func (t *TMDB) GetTrending(mediaType string, timeWindow string, page int) ([]mod
 }
 
 // SearchMulti searches for movies and TV shows
-func (t *TMDB) SearchMulti(query string, page int) ([]models.Meta, error) {
+func (t *TMDB) SearchMulti(ctx context.Context, query string, page int) ([]models.Meta, error) {
 	cacheKey := fmt.Sprintf("tmdb:search:%s:%d", query, page)
 
 	if data, found := t.cache.Get(cacheKey); found {
 		return data.([]models.Meta), nil
 	}
 
-	results, err := t.fetchSearchResults(query, page)
+	results, err := t.fetchSearchResults(ctx, query, page)
 	if err != nil {
 		return nil, err
 	}
@@ -424,7 +443,7 @@ func (t *TMDB) processSearchResults(results []json.RawMessage) []models.Meta {
 }
 
 // GetMetadata fetches detailed metadata for a specific item
-func (t *TMDB) GetMetadata(mediaType, tmdbID string) (*models.Meta, error) {
+func (t *TMDB) GetMetadata(ctx context.Context, mediaType, tmdbID string) (*models.Meta, error) {
 	cacheKey := fmt.Sprintf("tmdb:meta:%s:%s", mediaType, tmdbID)
 
 	if data, found := t.cache.Get(cacheKey); found {
@@ -432,7 +451,7 @@ func (t *TMDB) GetMetadata(mediaType, tmdbID string) (*models.Meta, error) {
 		return meta, nil
 	}
 
-	details, err := t.fetchMediaDetails(mediaType, tmdbID)
+	details, err := t.fetchMediaDetails(ctx, mediaType, tmdbID)
 	if err != nil {
 		return nil, err
 	}
@@ -443,7 +462,7 @@ func (t *TMDB) GetMetadata(mediaType, tmdbID string) (*models.Meta, error) {
 		meta = t.convertMovieDetailsToMeta(*movieDetails)
 	} else {
 		tvDetails := details.(*models.TMDBTVDetails)
-		meta = t.convertTVDetailsToMeta(*tvDetails)
+		meta = t.convertTVDetailsToMeta(ctx, *tvDetails)
 	}
 
 	t.cache.Set(cacheKey, &meta)
@@ -503,7 +522,7 @@ func (t *TMDB) convertTVToMetas(shows []models.TMDBTV) []models.Meta {
 }
 
 // fetchTVDetails fetches detailed TV show information from TMDB
-func (t *TMDB) fetchTVDetails(tmdbID int) (*models.TMDBTVDetails, error) {
+func (t *TMDB) fetchTVDetails(ctx context.Context, tmdbID int) (*models.TMDBTVDetails, error) {
 	cacheKey := fmt.Sprintf("tmdb:tv_details:%d", tmdbID)
 	if data, found := t.cache.Get(cacheKey); found {
 		details := data.(*models.TMDBTVDetails)
@@ -514,14 +533,16 @@ func (t *TMDB) fetchTVDetails(tmdbID int) (*models.TMDBTVDetails, error) {
 		return nil, err
 	}
 
-	t.rateLimiter.Wait()
+	if err := t.rateLimiter.WaitContext(ctx); err != nil {
+		return nil, err
+	}
 
 	url := fmt.Sprintf("https://api.themoviedb.org/3/tv/%d?api_key=%s&append_to_response=credits,external_ids",
 		tmdbID, t.apiKey)
 	
 	t.logger.Debugf("[TMDB] API URL: %s", url)
 
-	resp, err := t.httpClient.Get(url)
+	resp, err := t.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch TV details: %w", err)
 	}
@@ -599,11 +620,11 @@ func (t *TMDB) convertMovieDetailsToMeta(details models.TMDBMovieDetails) models
 	}
 }
 
-func (t *TMDB) convertTVDetailsToMeta(details models.TMDBTVDetails) models.Meta {
+func (t *TMDB) convertTVDetailsToMeta(ctx context.Context, details models.TMDBTVDetails) models.Meta {
 	genres := t.extractGenres(details.Genres)
 	cast := t.extractTopCast(details.Credits.Cast, 5)
 	runtime := t.formatRuntime(details.EpisodeRunTime)
-	videos := t.fetchAllSeasonVideos(details)
+	videos := t.fetchAllSeasonVideos(ctx, details)
 
 	return models.Meta{
 		ID:          details.ExternalIds.IMDBId,
@@ -648,11 +669,11 @@ func (t *TMDB) formatRuntime(episodeRunTime []int) string {
 	return ""
 }
 
-func (t *TMDB) fetchAllSeasonVideos(details models.TMDBTVDetails) []models.Video {
+func (t *TMDB) fetchAllSeasonVideos(ctx context.Context, details models.TMDBTVDetails) []models.Video {
 	seasonsToFetch := t.filterRegularSeasons(details.Seasons)
 	seasonsToFetch = t.limitSeasonsForLargeSeries(seasonsToFetch, details.ID)
 	
-	seasonVideos := t.fetchSeasonsInBatches(details.ID, details.ExternalIds.IMDBId, seasonsToFetch)
+	seasonVideos := t.fetchSeasonsInBatches(ctx, details.ID, details.ExternalIds.IMDBId, seasonsToFetch)
 	return t.combineSeasonVideos(seasonsToFetch, seasonVideos)
 }
 
@@ -681,7 +702,7 @@ type seasonResult struct {
 	videos       []models.Video
 }
 
-func (t *TMDB) fetchSeasonsInBatches(seriesID int, imdbID string, seasons []models.TMDBSeason) map[int][]models.Video {
+func (t *TMDB) fetchSeasonsInBatches(ctx context.Context, seriesID int, imdbID string, seasons []models.TMDBSeason) map[int][]models.Video {
 	const batchSize = 5
 	resultsChan := make(chan seasonResult, len(seasons))
 	var wg sync.WaitGroup
@@ -691,7 +712,7 @@ func (t *TMDB) fetchSeasonsInBatches(seriesID int, imdbID string, seasons []mode
 		if end > len(seasons) {
 			end = len(seasons)
 		}
-		t.processBatchOfSeasons(seriesID, imdbID, seasons[i:end], resultsChan, &wg)
+		t.processBatchOfSeasons(ctx, seriesID, imdbID, seasons[i:end], resultsChan, &wg)
 		wg.Wait()
 	}
 
@@ -699,17 +720,17 @@ func (t *TMDB) fetchSeasonsInBatches(seriesID int, imdbID string, seasons []mode
 	return t.collectSeasonResults(resultsChan)
 }
 
-func (t *TMDB) processBatchOfSeasons(seriesID int, imdbID string, batch []models.TMDBSeason, resultsChan chan<- seasonResult, wg *sync.WaitGroup) {
+func (t *TMDB) processBatchOfSeasons(ctx context.Context, seriesID int, imdbID string, batch []models.TMDBSeason, resultsChan chan<- seasonResult, wg *sync.WaitGroup) {
 	for _, season := range batch {
 		wg.Add(1)
-		go t.fetchSeasonVideos(seriesID, imdbID, season, resultsChan, wg)
+		go t.fetchSeasonVideos(ctx, seriesID, imdbID, season, resultsChan, wg)
 	}
 }
 
-func (t *TMDB) fetchSeasonVideos(seriesID int, imdbID string, season models.TMDBSeason, resultsChan chan<- seasonResult, wg *sync.WaitGroup) {
+func (t *TMDB) fetchSeasonVideos(ctx context.Context, seriesID int, imdbID string, season models.TMDBSeason, resultsChan chan<- seasonResult, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	episodes, err := t.getSeasonEpisodes(seriesID, season.SeasonNumber)
+	episodes, err := t.getSeasonEpisodes(ctx, seriesID, season.SeasonNumber)
 	if err != nil {
 		t.logger.Warnf("failed to fetch episodes for season %d of series %d: %v", season.SeasonNumber, seriesID, err)
 		return
@@ -758,7 +779,7 @@ func (t *TMDB) combineSeasonVideos(seasonsToFetch []models.TMDBSeason, seasonMap
 }
 
 // getSeasonEpisodes fetches episodes for a specific season
-func (t *TMDB) getSeasonEpisodes(seriesID, seasonNumber int) ([]models.TMDBEpisode, error) {
+func (t *TMDB) getSeasonEpisodes(ctx context.Context, seriesID, seasonNumber int) ([]models.TMDBEpisode, error) {
 	cacheKey := fmt.Sprintf("tmdb:season:%d:%d", seriesID, seasonNumber)
 
 	if data, found := t.cache.Get(cacheKey); found {
@@ -769,7 +790,9 @@ func (t *TMDB) getSeasonEpisodes(seriesID, seasonNumber int) ([]models.TMDBEpiso
 		return nil, err
 	}
 
-	t.rateLimiter.Wait()
+	if err := t.rateLimiter.WaitContext(ctx); err != nil {
+		return nil, err
+	}
 
 	url := fmt.Sprintf("https://api.themoviedb.org/3/tv/%d/season/%d?api_key=%s",
 		seriesID, seasonNumber, t.apiKey)
@@ -777,7 +800,7 @@ func (t *TMDB) getSeasonEpisodes(seriesID, seasonNumber int) ([]models.TMDBEpiso
 	t.logger.Debugf("fetching episodes for series %d season %d", seriesID, seasonNumber)
 	t.logger.Debugf("[TMDB] API URL: %s", url)
 
-	resp, err := t.httpClient.Get(url)
+	resp, err := t.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch season details: %w", err)
 	}
@@ -797,7 +820,7 @@ func (t *TMDB) getSeasonEpisodes(seriesID, seasonNumber int) ([]models.TMDBEpiso
 }
 
 // prefetchSeasons fetches multiple seasons concurrently with batching
-func (t *TMDB) prefetchSeasons(seriesID int, seasons []models.TMDBSeason) {
+func (t *TMDB) prefetchSeasons(ctx context.Context, seriesID int, seasons []models.TMDBSeason) {
 	const batchSize = 10 // Increase batch size for prefetching
 	var wg sync.WaitGroup
 
@@ -816,7 +839,7 @@ func (t *TMDB) prefetchSeasons(seriesID int, seasons []models.TMDBSeason) {
 			go func(seasonNum int) {
 				defer wg.Done()
 				// Attempt to fetch but don't fail if individual season fails
-				_, _ = t.getSeasonEpisodes(seriesID, seasonNum)
+				_, _ = t.getSeasonEpisodes(ctx, seriesID, seasonNum)
 			}(seasons[j].SeasonNumber)
 		}
 
@@ -839,8 +862,8 @@ func (t *TMDB) mapGenreIDs(ids []int, mediaType string) []string {
 }
 
 // GetSeasonVideos fetches episodes for a specific season and returns them as videos
-func (t *TMDB) GetSeasonVideos(imdbID string, seriesID int, seasonNumber int) ([]models.Video, error) {
-	episodes, err := t.getSeasonEpisodes(seriesID, seasonNumber)
+func (t *TMDB) GetSeasonVideos(ctx context.Context, imdbID string, seriesID int, seasonNumber int) ([]models.Video, error) {
+	episodes, err := t.getSeasonEpisodes(ctx, seriesID, seasonNumber)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch season %d: %w", seasonNumber, err)
 	}
@@ -1,14 +1,19 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/amaumene/gostremiofr/internal/database"
+	"github.com/amaumene/gostremiofr/internal/health"
+	"github.com/amaumene/gostremiofr/internal/metrics"
 	"github.com/amaumene/gostremiofr/internal/models"
 )
 
@@ -47,7 +52,7 @@ func (t *TMDB) checkDatabaseCache(tmdbID, cacheKey string) *models.TMDBData {
 	return tmdbData
 }
 
-func (t *TMDB) fetchTMDBDetails(id, tmdbID, mediaType string) (*models.TMDBData, error) {
+func (t *TMDB) fetchTMDBDetails(ctx context.Context, id, tmdbID, mediaType string) (*models.TMDBData, error) {
 	apiURL := t.buildTMDBDetailURL(id, mediaType)
 	if apiURL == "" {
 		return nil, fmt.Errorf("unsupported media type: %s", mediaType)
@@ -56,7 +61,7 @@ func (t *TMDB) fetchTMDBDetails(id, tmdbID, mediaType string) (*models.TMDBData,
 	t.logger.Debugf("fetching %s info for TMDB ID %s", mediaType, tmdbID)
 	t.logger.Debugf("[TMDB] API URL: %s", apiURL)
 
-	resp, err := t.httpClient.Get(apiURL)
+	resp, err := t.get(ctx, apiURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch TMDB data for %s: %w", tmdbID, err)
 	}
@@ -138,12 +143,14 @@ func (t *TMDB) storeTMDBCache(tmdbID string, tmdbData *models.TMDBData) {
 	}
 }
 
-func (t *TMDB) fetchIMDBData(imdbID string) (*models.TMDBFindResponse, error) {
+func (t *TMDB) fetchIMDBData(ctx context.Context, imdbID string) (*models.TMDBFindResponse, error) {
 	if err := t.validateAPIKey(); err != nil {
 		return nil, err
 	}
 
-	t.rateLimiter.Wait()
+	if err := t.rateLimiter.WaitContext(ctx); err != nil {
+		return nil, err
+	}
 
 	url := fmt.Sprintf("https://api.themoviedb.org/3/find/%s?api_key=%s&external_source=imdb_id",
 		imdbID, t.apiKey)
@@ -151,7 +158,7 @@ func (t *TMDB) fetchIMDBData(imdbID string) (*models.TMDBFindResponse, error) {
 	t.logger.Debugf("fetching info for %s", imdbID)
 	t.logger.Debugf("[TMDB] API URL: %s", url)
 
-	resp, err := t.httpClient.Get(url)
+	resp, err := t.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch TMDB data: %w", err)
 	}
@@ -201,12 +208,14 @@ func (t *TMDB) processIMDBResponse(tmdbResp *models.TMDBFindResponse, imdbID str
 	return nil, fmt.Errorf("no results found for IMDB ID: %s", imdbID)
 }
 
-func (t *TMDB) fetchSearchResults(query string, page int) ([]json.RawMessage, error) {
+func (t *TMDB) fetchSearchResults(ctx context.Context, query string, page int) ([]json.RawMessage, error) {
 	if err := t.validateAPIKey(); err != nil {
 		return nil, err
 	}
 
-	t.rateLimiter.Wait()
+	if err := t.rateLimiter.WaitContext(ctx); err != nil {
+		return nil, err
+	}
 
 	encodedQuery := url.QueryEscape(query)
 	apiURL := fmt.Sprintf("https://api.themoviedb.org/3/search/multi?api_key=%s&query=%s&page=%d&include_adult=false",
@@ -215,7 +224,7 @@ func (t *TMDB) fetchSearchResults(query string, page int) ([]json.RawMessage, er
 	t.logger.Debugf("searching for '%s' page %d", query, page)
 	t.logger.Debugf("[TMDB] API URL: %s", apiURL)
 
-	resp, err := t.httpClient.Get(apiURL)
+	resp, err := t.get(ctx, apiURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search: %w", err)
 	}
@@ -265,26 +274,28 @@ func (t *TMDB) processSearchResult(result json.RawMessage) *models.Meta {
 	return nil
 }
 
-func (t *TMDB) fetchMediaDetails(mediaType, tmdbID string) (interface{}, error) {
+func (t *TMDB) fetchMediaDetails(ctx context.Context, mediaType, tmdbID string) (interface{}, error) {
 	if err := t.validateAPIKey(); err != nil {
 		return nil, err
 	}
 
-	t.rateLimiter.Wait()
+	if err := t.rateLimiter.WaitContext(ctx); err != nil {
+		return nil, err
+	}
 
 	if mediaType == "movie" {
-		return t.fetchMovieDetails(tmdbID)
+		return t.fetchMovieDetails(ctx, tmdbID)
 	}
-	return t.fetchTVDetailsWithAppend(tmdbID)
+	return t.fetchTVDetailsWithAppend(ctx, tmdbID)
 }
 
-func (t *TMDB) fetchMovieDetails(tmdbID string) (*models.TMDBMovieDetails, error) {
+func (t *TMDB) fetchMovieDetails(ctx context.Context, tmdbID string) (*models.TMDBMovieDetails, error) {
 	url := fmt.Sprintf("https://api.themoviedb.org/3/movie/%s?api_key=%s&append_to_response=credits",
 		tmdbID, t.apiKey)
 	
 	t.logger.Debugf("[TMDB] API URL: %s", url)
 
-	resp, err := t.httpClient.Get(url)
+	resp, err := t.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch movie details: %w", err)
 	}
@@ -302,13 +313,13 @@ func (t *TMDB) fetchMovieDetails(tmdbID string) (*models.TMDBMovieDetails, error
 	return &details, nil
 }
 
-func (t *TMDB) fetchTVDetailsWithAppend(tmdbID string) (*models.TMDBTVDetails, error) {
+func (t *TMDB) fetchTVDetailsWithAppend(ctx context.Context, tmdbID string) (*models.TMDBTVDetails, error) {
 	url := fmt.Sprintf("https://api.themoviedb.org/3/tv/%s?api_key=%s&append_to_response=credits,external_ids",
 		tmdbID, t.apiKey)
 	
 	t.logger.Debugf("[TMDB] API URL: %s", url)
 
-	resp, err := t.httpClient.Get(url)
+	resp, err := t.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch TV details: %w", err)
 	}
@@ -324,4 +335,36 @@ func (t *TMDB) fetchTVDetailsWithAppend(tmdbID string) (*models.TMDBTVDetails, e
 	}
 
 	return &details, nil
-}
\ No newline at end of file
+}
+// get sends a GET request to the TMDB API that is cancelled with ctx
+func (t *TMDB) get(ctx context.Context, apiURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := metrics.TMDBEndpoint(apiURL)
+	start := time.Now()
+	resp, err := t.httpClient.Do(req)
+	metrics.TMDBRequestDuration.ObserveSince(start, endpoint)
+	if err != nil {
+		metrics.TMDBRequests.Inc(endpoint, "error")
+		health.Upstreams.Record(health.KindTMDB, "api", err)
+		return nil, err
+	}
+	metrics.TMDBRequests.Inc(endpoint, strconv.Itoa(resp.StatusCode))
+	health.Upstreams.Record(health.KindTMDB, "api", tmdbStatusError(endpoint, resp))
+	return resp, nil
+}
+
+// tmdbStatusError returns the error reported as TMDB health for a response: server errors,
+// rate limiting and rejected API keys; missing content is a normal answer
+func tmdbStatusError(endpoint string, resp *http.Response) error {
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError,
+		resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	return nil
+}
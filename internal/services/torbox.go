@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/amaumene/gostremiofr/internal/constants"
+	"github.com/amaumene/gostremiofr/internal/models"
+	"github.com/amaumene/gostremiofr/pkg/logger"
+	"github.com/amaumene/gostremiofr/pkg/ratelimiter"
+	"github.com/amaumene/gostremiofr/pkg/security"
+	"github.com/amaumene/gostremiofr/pkg/torbox"
+)
+
+type TorBox struct {
+	rateLimiter *ratelimiter.TokenBucket
+	client      *torbox.Client
+	logger      logger.Logger
+	validator   *security.APIKeyValidator
+}
+
+func NewTorBox() *TorBox {
+	return &TorBox{
+		rateLimiter: newRateLimiter("torbox", constants.TorBoxRateLimit, constants.TorBoxRateBurst),
+		client:      torbox.NewClient(),
+		logger:      logger.New(),
+		validator:   security.NewAPIKeyValidator(),
+	}
+}
+
+// Name returns the provider display name
+func (t *TorBox) Name() string {
+	return "TorBox"
+}
+
+// CheckInstantAvailability reports which hashes are cached on TorBox
+func (t *TorBox) CheckInstantAvailability(ctx context.Context, hashes []string, apiKey string) (map[string]bool, error) {
+	apiKey, err := t.validateAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := make(map[string]bool, len(hashes))
+	if len(hashes) == 0 {
+		return availability, nil
+	}
+
+	if err := t.rateLimiter.WaitContext(ctx); err != nil {
+		return nil, err
+	}
+
+	t.logger.Debugf("[TorBox] checking instant availability for %d hashes", len(hashes))
+
+	cached, err := t.client.CheckCached(ctx, apiKey, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check instant availability: %w", err)
+	}
+
+	for _, hash := range hashes {
+		availability[strings.ToLower(hash)] = false
+	}
+	for hash := range cached {
+		availability[strings.ToLower(hash)] = true
+	}
+
+	return availability, nil
+}
+
+func (t *TorBox) UploadMagnet(ctx context.Context, hash, title, apiKey string) (string, error) {
+	apiKey, err := t.validateAPIKey(apiKey)
+	if err != nil {
+		return "", err
+	}
+
+	if err := t.rateLimiter.WaitContext(ctx); err != nil {
+		return "", err
+	}
+
+	magnetURL := buildMagnetURL(hash, title)
+	t.logger.Debugf("[TorBox] uploading magnet URL: %s", magnetURL)
+
+	resp, err := t.client.CreateTorrent(ctx, apiKey, magnetURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload magnet: %w", err)
+	}
+
+	return strconv.FormatInt(resp.TorrentID, 10), nil
+}
+
+// CheckMagnets fetches the status and files of each uploaded torrent
+func (t *TorBox) CheckMagnets(ctx context.Context, magnets []models.MagnetInfo, apiKey string) ([]models.ProcessedMagnet, error) {
+	apiKey, err := t.validateAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var processed []models.ProcessedMagnet
+	for _, magnet := range magnets {
+		torrentID, err := strconv.ParseInt(magnet.ID, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if err := t.rateLimiter.WaitContext(ctx); err != nil {
+			return nil, err
+		}
+
+		torrent, err := t.client.GetTorrent(ctx, apiKey, torrentID)
+		if err != nil {
+			// An account error fails every magnet, let the caller fail over to another account
+			if IsAccountError(err) {
+				return nil, fmt.Errorf("failed to get torrent %d: %w", torrentID, err)
+			}
+			t.logger.Warnf("[TorBox] failed to get torrent %d: %v", torrentID, err)
+			continue
+		}
+
+		var links []interface{}
+		for _, file := range torrent.Files {
+			filename := file.ShortName
+			if filename == "" {
+				filename = path.Base(file.Name)
+			}
+			links = append(links, map[string]interface{}{
+				// TorBox resolves downloads per torrent and file ID
+				"link":     fmt.Sprintf("%d:%d", torrent.ID, file.ID),
+				"filename": filename,
+				"path":     file.Name,
+				"size":     float64(file.Size),
+			})
+		}
+
+		ready := torrent.DownloadFinished && torrent.DownloadPresent && len(links) > 0
+		t.logger.Infof("[TorBox] magnet status - %s: finished=%t (files: %d)", magnet.Title, torrent.DownloadFinished, len(links))
+
+		processed = append(processed, models.ProcessedMagnet{
+			Hash:   magnet.Hash,
+			Ready:  ready,
+			Name:   torrent.Name,
+			Size:   float64(torrent.Size),
+			ID:     magnet.ID,
+			Source: magnet.Source,
+			Links:  links,
+		})
+	}
+
+	return processed, nil
+}
+
+// UnlockLink requests a download URL for a "torrentID:fileID" link returned by CheckMagnets
+func (t *TorBox) UnlockLink(ctx context.Context, link, apiKey string) (string, error) {
+	apiKey, err := t.validateAPIKey(apiKey)
+	if err != nil {
+		return "", err
+	}
+
+	torrentPart, filePart, found := strings.Cut(link, ":")
+	if !found {
+		return "", fmt.Errorf("invalid TorBox link: %s", link)
+	}
+	torrentID, err := strconv.ParseInt(torrentPart, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid TorBox torrent ID: %w", err)
+	}
+	fileID, err := strconv.ParseInt(filePart, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid TorBox file ID: %w", err)
+	}
+
+	if err := t.rateLimiter.WaitContext(ctx); err != nil {
+		return "", err
+	}
+
+	t.logger.Debugf("[TorBox] requesting download for torrent %d file %d", torrentID, fileID)
+
+	directURL, err := t.client.RequestDownload(ctx, apiKey, torrentID, fileID)
+	if err != nil {
+		return "", fmt.Errorf("failed to unlock link: %w", err)
+	}
+	if directURL == "" {
+		return "", fmt.Errorf("no direct link returned from download API")
+	}
+
+	return directURL, nil
+}
+
+// DeleteMagnet deletes a torrent from TorBox
+func (t *TorBox) DeleteMagnet(ctx context.Context, magnetID, apiKey string) error {
+	apiKey, err := t.validateAPIKey(apiKey)
+	if err != nil {
+		return err
+	}
+
+	torrentID, err := strconv.ParseInt(magnetID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid TorBox torrent ID: %w", err)
+	}
+
+	if err := t.rateLimiter.WaitContext(ctx); err != nil {
+		return err
+	}
+
+	t.logger.Debugf("[TorBox] deleting torrent ID: %s", magnetID)
+
+	if err := t.client.DeleteTorrent(ctx, apiKey, torrentID); err != nil {
+		return fmt.Errorf("failed to delete magnet: %w", err)
+	}
+
+	t.logger.Infof("[TorBox] successfully deleted torrent ID: %s", magnetID)
+	return nil
+}
+
+func (t *TorBox) validateAPIKey(apiKey string) (string, error) {
+	apiKey = t.validator.SanitizeAPIKey(apiKey)
+	if !t.validator.ValidateAPIKey(apiKey) {
+		t.logger.Errorf("invalid API key format (key: %s)", t.validator.MaskAPIKey(apiKey))
+		return "", fmt.Errorf("invalid TorBox API key format")
+	}
+	return apiKey, nil
+}
@@ -3,8 +3,6 @@ package services
 import (
 	"fmt"
 	"net/http"
-	"sort"
-	"strings"
 	"time"
 
 	"github.com/amaumene/gostremiofr/internal/cache"
@@ -13,6 +11,7 @@ import (
 	"github.com/amaumene/gostremiofr/internal/models"
 	"github.com/amaumene/gostremiofr/pkg/httputil"
 	"github.com/amaumene/gostremiofr/pkg/ratelimiter"
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/scoring"
 	"github.com/amaumene/gostremiofr/pkg/torrentsearch/utils"
 	"github.com/cehbz/torrentname"
 )
@@ -142,36 +141,47 @@ func (b *BaseTorrentService) ContainsSeasonEpisode(title string) bool {
 	return parsed != nil && parsed.Season > 0 && parsed.Episode > 0
 }
 
-func (b *BaseTorrentService) SortTorrents(torrents []models.TorrentInfo) {
-	sort.Slice(torrents, func(i, j int) bool {
-		// Check if titles contain "remux" (case-insensitive)
-		iIsRemux := b.isRemux(torrents[i].Title)
-		jIsRemux := b.isRemux(torrents[j].Title)
-		
-		// Remux torrents go first
-		if iIsRemux != jIsRemux {
-			return iIsRemux
-		}
-		
-		// Then sort by size (largest first)
-		return torrents[i].Size > torrents[j].Size
-	})
-}
-
-func (b *BaseTorrentService) isRemux(title string) bool {
-	titleLower := strings.ToLower(title)
-	return strings.Contains(titleLower, "remux")
-}
-
+// TorrentSorter ranks torrents with the scoring engine, using the configured weights.
 type TorrentSorter struct {
 	*BaseTorrentService
+	engine *scoring.Engine
 }
 
 func NewTorrentSorter(config *config.Config) *TorrentSorter {
 	base := &BaseTorrentService{
 		config: config,
 	}
-	return &TorrentSorter{BaseTorrentService: base}
+	weights := scoring.DefaultWeights()
+	if config != nil {
+		weights = config.ScoreWeights
+	}
+	return &TorrentSorter{BaseTorrentService: base, engine: scoring.New(weights)}
+}
+
+// SortTorrents orders torrents by descending score.
+func (ts *TorrentSorter) SortTorrents(torrents []models.TorrentInfo) {
+	scoring.Sort(ts.engine, torrents, scoringInput)
+}
+
+// Score returns the score breakdown of a torrent, for debugging.
+func (ts *TorrentSorter) Score(torrent models.TorrentInfo) scoring.Breakdown {
+	return ts.engine.Score(scoringInput(torrent))
+}
+
+// MinConfidence returns the parser confidence below which torrents are dropped.
+func (ts *TorrentSorter) MinConfidence() float64 {
+	return ts.engine.Weights().MinConfidence
+}
+
+// scoringInput converts a torrent to the scoring engine input.
+func scoringInput(torrent models.TorrentInfo) scoring.Torrent {
+	return scoring.Torrent{
+		Title:      torrent.Title,
+		Size:       torrent.Size,
+		Seeders:    torrent.Seeders,
+		Confidence: torrent.ConfidenceScore,
+		Weight:     torrent.Weight,
+	}
 }
 
 func (ts *TorrentSorter) SortResults(results *models.TorrentResults) {
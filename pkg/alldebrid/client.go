@@ -1,6 +1,7 @@
 package alldebrid
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -87,11 +88,54 @@ type MagnetFilesResponse struct {
 	} `json:"error,omitempty"`
 }
 
-func (c *Client) UploadMagnet(apiKey string, magnetURLs []string) (*MagnetUploadResponse, error) {
+// MagnetInstantResponse represents the response from magnet instant availability endpoint
+type MagnetInstantResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Magnets []struct {
+			Magnet  string `json:"magnet"`
+			Hash    string `json:"hash"`
+			Instant bool   `json:"instant"`
+			Error   *struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error,omitempty"`
+		} `json:"magnets"`
+	} `json:"data"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// CheckInstant reports which of the given magnets or hashes are already cached, in a single call
+func (c *Client) CheckInstant(ctx context.Context, apiKey string, hashes []string) (*MagnetInstantResponse, error) {
+	endpoint := fmt.Sprintf("%s/magnet/instant", c.baseURL)
+	formData := c.buildMagnetFormData(apiKey, hashes)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(formData.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result MagnetInstantResponse
+	if err := c.decodeResponse(resp, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) UploadMagnet(ctx context.Context, apiKey string, magnetURLs []string) (*MagnetUploadResponse, error) {
 	endpoint := fmt.Sprintf("%s/magnet/upload", c.baseURL)
 	formData := c.buildMagnetFormData(apiKey, magnetURLs)
 
-	req, err := http.NewRequest("POST", endpoint, strings.NewReader(formData.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(formData.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -129,6 +173,15 @@ func (c *Client) buildParams(apiKey string, extraParams map[string]string) url.V
 	return params
 }
 
+// get sends a GET request that is cancelled with ctx
+func (c *Client) get(ctx context.Context, fullURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	return c.httpClient.Do(req)
+}
+
 func (c *Client) decodeResponse(resp *http.Response, result interface{}) error {
 	defer resp.Body.Close()
 
@@ -144,12 +197,12 @@ func (c *Client) decodeResponse(resp *http.Response, result interface{}) error {
 	return nil
 }
 
-func (c *Client) UnlockLink(apiKey, link string) (*LinkUnlockResponse, error) {
+func (c *Client) UnlockLink(ctx context.Context, apiKey, link string) (*LinkUnlockResponse, error) {
 	endpoint := fmt.Sprintf("%s/link/unlock", c.baseURL)
 	params := c.buildParams(apiKey, map[string]string{"link": link})
 	fullURL := fmt.Sprintf("%s?%s", endpoint, params.Encode())
 
-	resp, err := c.httpClient.Get(fullURL)
+	resp, err := c.get(ctx, fullURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -160,12 +213,12 @@ func (c *Client) UnlockLink(apiKey, link string) (*LinkUnlockResponse, error) {
 	return &result, nil
 }
 
-func (c *Client) GetMagnetFiles(apiKey, magnetID string) (*MagnetFilesResponse, error) {
+func (c *Client) GetMagnetFiles(ctx context.Context, apiKey, magnetID string) (*MagnetFilesResponse, error) {
 	endpoint := fmt.Sprintf("%s/magnet/files", c.baseURL)
 	params := c.buildParams(apiKey, map[string]string{"id": magnetID})
 	fullURL := fmt.Sprintf("%s?%s", endpoint, params.Encode())
 
-	resp, err := c.httpClient.Get(fullURL)
+	resp, err := c.get(ctx, fullURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -176,12 +229,12 @@ func (c *Client) GetMagnetFiles(apiKey, magnetID string) (*MagnetFilesResponse,
 	return &result, nil
 }
 
-func (c *Client) DeleteMagnet(apiKey string, magnetID string) error {
+func (c *Client) DeleteMagnet(ctx context.Context, apiKey string, magnetID string) error {
 	endpoint := fmt.Sprintf("%s/magnet/delete", c.baseURL)
 	params := c.buildParams(apiKey, map[string]string{"id": magnetID})
 	fullURL := fmt.Sprintf("%s?%s", endpoint, params.Encode())
 
-	resp, err := c.httpClient.Get(fullURL)
+	resp, err := c.get(ctx, fullURL)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
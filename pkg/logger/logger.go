@@ -2,11 +2,15 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 // Logger defines the logging interface
@@ -21,6 +25,8 @@ type Logger interface {
 	Errorf(format string, v ...interface{})
 	Fatal(v ...interface{})
 	Fatalf(format string, v ...interface{})
+	// With returns a logger adding the given key/value pairs to every message
+	With(keyvals ...interface{}) Logger
 }
 
 // Level represents logging levels
@@ -33,24 +39,59 @@ const (
 	LevelError
 )
 
+// Format represents output formats, selected with LOG_FORMAT
+type Format int
+
+const (
+	FormatText   Format = iota // [INFO] 2024/03/14 10:00:00 message key=value
+	FormatJSON                 // {"time":"...","level":"info","msg":"message","key":"value"}
+	FormatLogfmt               // time=... level=info msg=message key=value
+)
+
+// field is a key/value pair added to messages
+type field struct {
+	key   string
+	value interface{}
+}
+
 // logger implements the Logger interface
 type logger struct {
 	level   Level
+	format  Format
 	loggers map[Level]*log.Logger
+	fields  []field // never modified once set, With copies them
 	mu      sync.RWMutex
 }
 
 // New creates a new logger instance
 func New() Logger {
-	level := parseLevel(os.Getenv("LOG_LEVEL"))
-	
+	return newLogger(parseLevel(os.Getenv("LOG_LEVEL")), parseFormat(os.Getenv("LOG_FORMAT")), os.Stdout, os.Stderr)
+}
+
+// newLogger creates a logger writing debug to warn messages to out and errors to errOut
+func newLogger(level Level, format Format, out, errOut io.Writer) *logger {
+	if format != FormatText {
+		// Structured lines carry their own time and level
+		return &logger{
+			level:  level,
+			format: format,
+			loggers: map[Level]*log.Logger{
+				LevelDebug: log.New(out, "", 0),
+				LevelInfo:  log.New(out, "", 0),
+				LevelWarn:  log.New(out, "", 0),
+				LevelError: log.New(errOut, "", 0),
+			},
+		}
+	}
+
 	return &logger{
-		level: level,
+		level:  level,
+		format: format,
 		loggers: map[Level]*log.Logger{
-			LevelDebug: log.New(os.Stdout, "[DEBUG] ", log.LstdFlags|log.Lshortfile),
-			LevelInfo:  log.New(os.Stdout, "[INFO] ", log.LstdFlags),
-			LevelWarn:  log.New(os.Stdout, "[WARN] ", log.LstdFlags),
-			LevelError: log.New(os.Stderr, "[ERROR] ", log.LstdFlags|log.Lshortfile),
+			LevelDebug: log.New(out, "[DEBUG] ", log.LstdFlags|log.Lshortfile),
+			LevelInfo:  log.New(out, "[INFO] ", log.LstdFlags),
+			LevelWarn:  log.New(out, "[WARN] ", log.LstdFlags),
+			LevelError: log.New(errOut, "[ERROR] ", log.LstdFlags|log.Lshortfile),
 		},
 	}
 }
@@ -69,6 +110,43 @@ func parseLevel(levelStr string) Level {
 	}
 }
 
+// parseFormat converts string log format to Format type
+func parseFormat(formatStr string) Format {
+	switch strings.ToLower(formatStr) {
+	case "json":
+		return FormatJSON
+	case "logfmt":
+		return FormatLogfmt
+	default:
+		return FormatText
+	}
+}
+
+// String returns the lowercase level name used in structured output
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// With returns a logger adding the given key/value pairs to every message.
+// A trailing key without value is ignored.
+func (l *logger) With(keyvals ...interface{}) Logger {
+	fields := append([]field{}, l.fields...)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		fields = append(fields, field{key: fmt.Sprint(keyvals[i]), value: keyvals[i+1]})
+	}
+
+	return &logger{level: l.level, format: l.format, loggers: l.loggers, fields: fields}
+}
+
 // shouldLog checks if a message should be logged at given level
 func (l *logger) shouldLog(level Level) bool {
 	l.mu.RLock()
@@ -81,12 +159,7 @@ func (l *logger) output(level Level, v ...interface{}) {
 	if !l.shouldLog(level) {
 		return
 	}
-	
-	l.mu.RLock()
-	logger := l.loggers[level]
-	l.mu.RUnlock()
-	
-	logger.Output(3, fmt.Sprint(v...))
+	l.write(level, fmt.Sprint(v...))
 }
 
 // outputf logs a formatted message at the specified level
@@ -94,12 +167,100 @@ func (l *logger) outputf(level Level, format string, v ...interface{}) {
 	if !l.shouldLog(level) {
 		return
 	}
-	
+	l.write(level, fmt.Sprintf(format, v...))
+}
+
+// write formats a redacted message with the logger fields; it is called at a fixed depth
+// below the public methods so that text output reports the right caller
+func (l *logger) write(level Level, msg string) {
 	l.mu.RLock()
 	logger := l.loggers[level]
 	l.mu.RUnlock()
-	
-	logger.Output(3, fmt.Sprintf(format, v...))
+
+	msg = redactMessage(msg)
+	switch l.format {
+	case FormatJSON:
+		logger.Output(4, l.formatJSON(level, msg))
+	case FormatLogfmt:
+		logger.Output(4, l.formatLogfmt(level, msg))
+	default:
+		logger.Output(4, msg+l.formatFields())
+	}
+}
+
+// formatJSON formats a message as a JSON object
+func (l *logger) formatJSON(level Level, msg string) string {
+	var b strings.Builder
+	b.WriteString(`{"time":`)
+	writeJSON(&b, time.Now().UTC().Format(time.RFC3339Nano))
+	b.WriteString(`,"level":`)
+	writeJSON(&b, level.String())
+	b.WriteString(`,"msg":`)
+	writeJSON(&b, msg)
+	for _, f := range l.fields {
+		b.WriteByte(',')
+		writeJSON(&b, f.key)
+		b.WriteByte(':')
+		writeJSON(&b, fieldValue(f))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// formatLogfmt formats a message as logfmt key/value pairs
+func (l *logger) formatLogfmt(level Level, msg string) string {
+	return "time=" + time.Now().UTC().Format(time.RFC3339Nano) +
+		" level=" + level.String() +
+		" msg=" + logfmtValue(msg) +
+		l.formatFields()
+}
+
+// formatFields formats the logger fields as logfmt pairs, each preceded by a space
+func (l *logger) formatFields() string {
+	var b strings.Builder
+	for _, f := range l.fields {
+		b.WriteByte(' ')
+		b.WriteString(f.key)
+		b.WriteByte('=')
+		b.WriteString(logfmtValue(fmt.Sprint(fieldValue(f))))
+	}
+	return b.String()
+}
+
+// fieldValue returns the value of a field, redacted when its key names a secret;
+// errors and other values without JSON form are logged as text
+func fieldValue(f field) interface{} {
+	if isSecretKey(f.key) {
+		return redact(fmt.Sprint(f.value))
+	}
+	switch v := f.value.(type) {
+	case string:
+		return redactMessage(v)
+	case error:
+		return redactMessage(v.Error())
+	case fmt.Stringer:
+		return redactMessage(v.String())
+	case time.Duration:
+		return v.String()
+	}
+	return f.value
+}
+
+// writeJSON writes a JSON value, falling back to its text form when it cannot be encoded
+func writeJSON(b *strings.Builder, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	b.Write(data)
+}
+
+// logfmtValue quotes a logfmt value when it contains spaces, quotes or equal signs
+func logfmtValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
 }
 
 // Debug logs a debug message
@@ -152,4 +313,4 @@ func (l *logger) Fatal(v ...interface{}) {
 func (l *logger) Fatalf(format string, v ...interface{}) {
 	l.outputf(LevelError, format, v...)
 	os.Exit(1)
-}
\ No newline at end of file
+}
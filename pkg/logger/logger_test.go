@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStructuredFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		contains []string
+	}{
+		{"text", FormatText, []string{"[INFO] ", "searching ygg request_id=abc12 provider=ygg"}},
+		{"logfmt", FormatLogfmt, []string{" level=info ", `msg="searching ygg"`, " request_id=abc12 provider=ygg"}},
+		{"json", FormatJSON, []string{`"level":"info"`, `"msg":"searching ygg"`, `"request_id":"abc12"`, `"provider":"ygg"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			l := newLogger(LevelInfo, tt.format, &out, &out).With("request_id", "abc12").With("provider", "ygg")
+			l.Infof("searching %s", "ygg")
+			l.Debugf("filtered out by level")
+
+			line := out.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(line, want) {
+					t.Errorf("%q does not contain %q", line, want)
+				}
+			}
+			if strings.Contains(line, "filtered out") {
+				t.Errorf("debug message logged at info level: %q", line)
+			}
+		})
+	}
+}
+
+func TestJSONOutputIsValid(t *testing.T) {
+	var out bytes.Buffer
+	l := newLogger(LevelDebug, FormatJSON, &out, &out).With("err", errors.New(`bad "quote"`), "count", 3)
+	l.Error("failed\nbadly")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	if entry["level"] != "error" || entry["msg"] != "failed\nbadly" || entry["err"] != `bad "quote"` || entry["count"] != float64(3) {
+		t.Errorf("unexpected entry %v", entry)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	SetRedactor(func(s string) string { return s[:2] + "..." })
+	defer SetRedactor(func(string) string { return "***" })
+
+	var out bytes.Buffer
+	l := newLogger(LevelInfo, FormatLogfmt, &out, &out).With("api_key", "secretkey123", "cache_key", "tmdb:1")
+	l.Infof("GET https://api.themoviedb.org/3/find/tt1?api_key=abcdef&external_source=imdb_id")
+	l.Infof("POST /v4/magnet/status?agent=x&apikey=zyxwvu")
+
+	line := out.String()
+	for _, leaked := range []string{"secretkey123", "abcdef", "zyxwvu"} {
+		if strings.Contains(line, leaked) {
+			t.Errorf("%q leaks %q", line, leaked)
+		}
+	}
+	for _, want := range []string{"api_key=se...", "api_key=ab...&external_source", "apikey=zy...", "cache_key=tmdb:1"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("%q does not contain %q", line, want)
+		}
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	var out bytes.Buffer
+	base := newLogger(LevelInfo, FormatLogfmt, &out, &out)
+
+	if l := FromContext(context.Background(), base); l != Logger(base) {
+		t.Error("expected the base logger without request ID")
+	}
+
+	FromContext(WithRequestID(context.Background(), "req-1"), base).Info("served")
+	if !strings.Contains(out.String(), "request_id=req-1") {
+		t.Errorf("missing request ID in %q", out.String())
+	}
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	redactorMu sync.RWMutex
+	redactor   = func(string) string { return "***" }
+
+	// secretParamRegex matches API keys and tokens passed in URLs or key=value text
+	secretParamRegex = regexp.MustCompile(`(?i)\b(api_?key|apitoken|token|secret|password)=([^&\s"']+)`)
+)
+
+// SetRedactor sets the function masking secrets in logs, e.g. security.APIKeyValidator.MaskAPIKey.
+// Secrets are fields whose key names an API key, token, secret or password, and the values of
+// api_key=, apikey=, token=, secret= and password= parameters in messages.
+func SetRedactor(fn func(string) string) {
+	if fn == nil {
+		return
+	}
+	redactorMu.Lock()
+	defer redactorMu.Unlock()
+	redactor = fn
+}
+
+// redact masks a secret
+func redact(secret string) string {
+	redactorMu.RLock()
+	fn := redactor
+	redactorMu.RUnlock()
+	return fn(secret)
+}
+
+// Redact masks the API keys and tokens passed as parameters in a message, such as an
+// error embedding a request URL, with the function set by SetRedactor.
+func Redact(msg string) string {
+	return redactMessage(msg)
+}
+
+// redactMessage masks the secret parameters of a message
+func redactMessage(msg string) string {
+	if !strings.Contains(msg, "=") {
+		return msg
+	}
+	return secretParamRegex.ReplaceAllStringFunc(msg, func(param string) string {
+		name, value, _ := strings.Cut(param, "=")
+		return name + "=" + redact(value)
+	})
+}
+
+// isSecretKey reports whether a field key names a secret
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	if key == "key" {
+		return true
+	}
+	for _, secret := range []string{"apikey", "api_key", "api-key", "token", "secret", "password"} {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,3 @@
+module github.com/amaumene/gostremiofr/pkg/metrics
+
+go 1.24.3
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWritesTextFormat(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("app_requests_total", "Requests by endpoint.", "endpoint", "code")
+	latency := r.NewHistogram("app_latency_seconds", "Request latency.", []float64{0.5, 0.1}, "endpoint")
+
+	requests.Inc("magnet/status", "ok")
+	requests.Add(2, "magnet/status", "ok")
+	requests.Inc("link/unlock", `AUTH_"BAD"`)
+	latency.Observe(0.05, "find")
+	latency.Observe(0.3, "find")
+	latency.Observe(2, "find")
+
+	var out strings.Builder
+	if _, err := r.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP app_requests_total Requests by endpoint.
+# TYPE app_requests_total counter
+app_requests_total{endpoint="link/unlock",code="AUTH_\"BAD\""} 1
+app_requests_total{endpoint="magnet/status",code="ok"} 3
+# HELP app_latency_seconds Request latency.
+# TYPE app_latency_seconds histogram
+app_latency_seconds_bucket{endpoint="find",le="0.1"} 1
+app_latency_seconds_bucket{endpoint="find",le="0.5"} 2
+app_latency_seconds_bucket{endpoint="find",le="+Inf"} 3
+app_latency_seconds_sum{endpoint="find"} 2.35
+app_latency_seconds_count{endpoint="find"} 3
+`
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestCounterIgnoresNegativeValues(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("runs_total", "Runs.")
+	c.Inc()
+	c.Add(-5)
+	if got := c.Value(); got != 1 {
+		t.Errorf("expected 1, got %v", got)
+	}
+}
+
+func TestLabelMismatchPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(c *Counter, h *Histogram)
+	}{
+		{"counter without values", func(c *Counter, h *Histogram) { c.Inc() }},
+		{"counter with extra values", func(c *Counter, h *Histogram) { c.Inc("a", "b") }},
+		{"histogram without values", func(c *Counter, h *Histogram) { h.Observe(1) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			c := r.NewCounter("c_total", "C.", "label")
+			h := r.NewHistogram("h_seconds", "H.", nil, "label")
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			tt.call(c, h)
+		})
+	}
+}
+
+func TestDuplicateNamePanics(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("dup_total", "First.")
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	r.NewHistogram("dup_total", "Second.", nil)
+}
+
+func TestHandlerContentType(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("empty_total", "Nothing yet.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "empty_total 1\n") {
+		t.Errorf("missing sample in %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,141 @@
+package premiumize
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/amaumene/gostremiofr/pkg/httputil"
+)
+
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+func NewClient() *Client {
+	return &Client{
+		httpClient: httputil.NewHTTPClient(30 * time.Second),
+		baseURL:    "https://www.premiumize.me/api",
+	}
+}
+
+// APIError represents an error returned by the Premiumize API
+type APIError struct {
+	StatusCode int
+	Code       string // Set by some endpoints only
+	Message    string // e.g. "Not logged in."
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("Premiumize API error: %s - %s (status %d)", e.Code, e.Message, e.StatusCode)
+	}
+	return fmt.Sprintf("Premiumize API error: %s (status %d)", e.Message, e.StatusCode)
+}
+
+// CacheCheckResponse represents the response from the cache check endpoint.
+// Response, Filename and Filesize are indexed like the requested items.
+type CacheCheckResponse struct {
+	Status   string   `json:"status"`
+	Message  string   `json:"message,omitempty"`
+	Code     string   `json:"code,omitempty"`
+	Response []bool   `json:"response"`
+	Filename []string `json:"filename"`
+	Filesize []string `json:"filesize"`
+}
+
+// DirectDLResponse represents the response from the direct download endpoint
+type DirectDLResponse struct {
+	Status   string `json:"status"`
+	Message  string `json:"message,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Location string `json:"location"`
+	Filename string `json:"filename"`
+	Filesize int64  `json:"filesize"`
+	Content  []struct {
+		Path       string `json:"path"`
+		Size       int64  `json:"size"`
+		Link       string `json:"link"`
+		StreamLink string `json:"stream_link"`
+	} `json:"content"`
+}
+
+func (c *Client) CheckCache(ctx context.Context, apiKey string, items []string) (*CacheCheckResponse, error) {
+	params := url.Values{}
+	params.Set("apikey", apiKey)
+	for _, item := range items {
+		params.Add("items[]", item)
+	}
+	fullURL := fmt.Sprintf("%s/cache/check?%s", c.baseURL, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result CacheCheckResponse
+	if err := c.decodeResponse(resp, &result); err != nil {
+		return nil, err
+	}
+	if result.Status != "success" {
+		return nil, &APIError{StatusCode: resp.StatusCode, Code: result.Code, Message: result.Message}
+	}
+	return &result, nil
+}
+
+// DirectDL resolves a cached magnet into direct links for each of its files
+func (c *Client) DirectDL(ctx context.Context, apiKey, src string) (*DirectDLResponse, error) {
+	endpoint := fmt.Sprintf("%s/transfer/directdl", c.baseURL)
+	formData := url.Values{}
+	formData.Set("apikey", apiKey)
+	formData.Set("src", src)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(formData.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result DirectDLResponse
+	if err := c.decodeResponse(resp, &result); err != nil {
+		return nil, err
+	}
+	if result.Status != "success" {
+		return nil, &APIError{StatusCode: resp.StatusCode, Code: result.Code, Message: result.Message}
+	}
+	return &result, nil
+}
+
+func (c *Client) decodeResponse(resp *http.Response, result interface{}) error {
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		if resp.StatusCode >= http.StatusBadRequest {
+			return &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		}
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
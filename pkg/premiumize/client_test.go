@@ -0,0 +1,51 @@
+package premiumize
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   APIError
+	}{
+		{"error status", http.StatusOK, `{"status":"error","message":"Not logged in."}`, APIError{StatusCode: 200, Message: "Not logged in."}},
+		{"error code", http.StatusOK, `{"status":"error","code":"auth","message":"Bad key"}`, APIError{StatusCode: 200, Code: "auth", Message: "Bad key"}},
+		{"http status without body", http.StatusUnauthorized, `Unauthorized`, APIError{StatusCode: 401, Message: "Unauthorized"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient()
+			client.baseURL = server.URL
+			calls := map[string]func() error{
+				"CheckCache": func() error {
+					_, err := client.CheckCache(context.Background(), "key", []string{"abc"})
+					return err
+				},
+				"DirectDL": func() error {
+					_, err := client.DirectDL(context.Background(), "key", "magnet:?xt=urn:btih:abc")
+					return err
+				},
+			}
+			for name, call := range calls {
+				var apiErr *APIError
+				if err := call(); !errors.As(err, &apiErr) || *apiErr != tt.want {
+					t.Errorf("%s error = %v, want %+v", name, err, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@
 package ratelimiter
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -15,6 +16,8 @@ type RateLimiter interface {
 	Wait()
 	// WaitWithTimeout blocks until a token is available or timeout occurs
 	WaitWithTimeout(timeout time.Duration) error
+	// WaitContext blocks until a token is available or the context is done
+	WaitContext(ctx context.Context) error
 }
 
 // TokenBucket implements the token bucket algorithm for rate limiting.
@@ -25,6 +28,8 @@ type TokenBucket struct {
 	refillRate int64      // Tokens added per second
 	lastRefill time.Time  // Last time tokens were refilled
 	mu         sync.Mutex // Protects concurrent access
+
+	onWait func(time.Duration) // Called with the duration of each wait, when set
 }
 
 // NewTokenBucket creates a new token bucket with the specified capacity and refill rate.
@@ -48,6 +53,24 @@ func NewTokenBucket(capacity, refillRate int64) *TokenBucket {
 	}
 }
 
+// OnWait sets a function called with the time each WaitWithTimeout and WaitContext call
+// blocked, e.g. to export rate limiting delays as metrics.
+func (tb *TokenBucket) OnWait(fn func(time.Duration)) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.onWait = fn
+}
+
+// observeWait reports the time a wait started at start blocked
+func (tb *TokenBucket) observeWait(start time.Time) {
+	tb.mu.Lock()
+	fn := tb.onWait
+	tb.mu.Unlock()
+	if fn != nil {
+		fn(time.Since(start))
+	}
+}
+
 // TakeToken attempts to consume a token from the bucket.
 // Returns true if a token was available and consumed, false otherwise.
 // This method is thread-safe and refills tokens based on elapsed time.
@@ -82,6 +105,8 @@ const minWaitTime = 100 * time.Millisecond
 // WaitWithTimeout blocks until a token is available or the timeout expires.
 // Returns nil if a token was acquired, or an error if the timeout was reached.
 func (tb *TokenBucket) WaitWithTimeout(timeout time.Duration) error {
+	start := time.Now()
+	defer tb.observeWait(start)
 	deadline := time.Now().Add(timeout)
 
 	// Calculate wait time based on refill rate
@@ -99,6 +124,31 @@ func (tb *TokenBucket) WaitWithTimeout(timeout time.Duration) error {
 	return nil
 }
 
+// WaitContext blocks until a token is available or the context is done.
+// Returns nil if a token was acquired, or an error wrapping ctx.Err() otherwise.
+func (tb *TokenBucket) WaitContext(ctx context.Context) error {
+	start := time.Now()
+	defer tb.observeWait(start)
+
+	// Calculate wait time based on refill rate
+	waitTime := time.Second / time.Duration(tb.refillRate)
+	if waitTime < minWaitTime {
+		waitTime = minWaitTime
+	}
+
+	ticker := time.NewTicker(waitTime)
+	defer ticker.Stop()
+
+	for !tb.TakeToken() {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("rate limiter wait aborted: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
 // min returns the smaller of two int64 values
 func min(a, b int64) int64 {
 	if a < b {
@@ -0,0 +1,179 @@
+package realdebrid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/amaumene/gostremiofr/pkg/httputil"
+)
+
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+func NewClient() *Client {
+	return &Client{
+		httpClient: httputil.NewHTTPClient(30 * time.Second),
+		baseURL:    "https://api.real-debrid.com/rest/1.0",
+	}
+}
+
+// APIError represents an error returned by the Real-Debrid API
+type APIError struct {
+	StatusCode int
+	Message    string `json:"error"`
+	Code       int    `json:"error_code"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Real-Debrid API error: %s (code %d, status %d)", e.Message, e.Code, e.StatusCode)
+}
+
+// InstantAvailabilityResponse maps each hash to the cached file variants per host
+type InstantAvailabilityResponse map[string]json.RawMessage
+
+// AddMagnetResponse represents the response from the add magnet endpoint
+type AddMagnetResponse struct {
+	ID  string `json:"id"`
+	URI string `json:"uri"`
+}
+
+// TorrentInfo represents the response from the torrent info endpoint
+type TorrentInfo struct {
+	ID       string   `json:"id"`
+	Filename string   `json:"filename"`
+	Hash     string   `json:"hash"`
+	Bytes    int64    `json:"bytes"`
+	Status   string   `json:"status"`
+	Progress float64  `json:"progress"`
+	Links    []string `json:"links"`
+	Files    []struct {
+		ID       int    `json:"id"`
+		Path     string `json:"path"`
+		Bytes    int64  `json:"bytes"`
+		Selected int    `json:"selected"`
+	} `json:"files"`
+}
+
+// UnrestrictResponse represents the response from the unrestrict link endpoint
+type UnrestrictResponse struct {
+	ID         string `json:"id"`
+	Filename   string `json:"filename"`
+	Filesize   int64  `json:"filesize"`
+	Download   string `json:"download"`
+	Streamable int    `json:"streamable"`
+}
+
+func (c *Client) InstantAvailability(ctx context.Context, apiKey string, hashes []string) (InstantAvailabilityResponse, error) {
+	endpoint := fmt.Sprintf("%s/torrents/instantAvailability/%s", c.baseURL, strings.Join(hashes, "/"))
+
+	var result InstantAvailabilityResponse
+	if err := c.do(ctx, apiKey, http.MethodGet, endpoint, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *Client) AddMagnet(ctx context.Context, apiKey, magnetURL string) (*AddMagnetResponse, error) {
+	endpoint := fmt.Sprintf("%s/torrents/addMagnet", c.baseURL)
+	formData := url.Values{}
+	formData.Set("magnet", magnetURL)
+
+	var result AddMagnetResponse
+	if err := c.do(ctx, apiKey, http.MethodPost, endpoint, formData, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) SelectFiles(ctx context.Context, apiKey, torrentID, files string) error {
+	endpoint := fmt.Sprintf("%s/torrents/selectFiles/%s", c.baseURL, url.PathEscape(torrentID))
+	formData := url.Values{}
+	formData.Set("files", files)
+
+	return c.do(ctx, apiKey, http.MethodPost, endpoint, formData, nil)
+}
+
+func (c *Client) GetTorrentInfo(ctx context.Context, apiKey, torrentID string) (*TorrentInfo, error) {
+	endpoint := fmt.Sprintf("%s/torrents/info/%s", c.baseURL, url.PathEscape(torrentID))
+
+	var result TorrentInfo
+	if err := c.do(ctx, apiKey, http.MethodGet, endpoint, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) UnrestrictLink(ctx context.Context, apiKey, link string) (*UnrestrictResponse, error) {
+	endpoint := fmt.Sprintf("%s/unrestrict/link", c.baseURL)
+	formData := url.Values{}
+	formData.Set("link", link)
+
+	var result UnrestrictResponse
+	if err := c.do(ctx, apiKey, http.MethodPost, endpoint, formData, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) DeleteTorrent(ctx context.Context, apiKey, torrentID string) error {
+	endpoint := fmt.Sprintf("%s/torrents/delete/%s", c.baseURL, url.PathEscape(torrentID))
+	return c.do(ctx, apiKey, http.MethodDelete, endpoint, nil, nil)
+}
+
+func (c *Client) do(ctx context.Context, apiKey, method, endpoint string, formData url.Values, result interface{}) error {
+	var body io.Reader
+	if formData != nil {
+		body = strings.NewReader(formData.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	if formData != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return c.decodeResponse(resp, result)
+}
+
+func (c *Client) decodeResponse(resp *http.Response, result interface{}) error {
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(data, apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
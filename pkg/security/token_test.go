@@ -0,0 +1,24 @@
+package security
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTokenSignerRoundTrip(t *testing.T) {
+	signer := NewTokenSigner([]byte("secret"))
+
+	token := signer.Sign([]byte(`{"h":"abc","f":2}`))
+	payload, err := signer.Verify(token)
+	if err != nil || string(payload) != `{"h":"abc","f":2}` {
+		t.Fatalf("Verify(%q) = %q, %v", token, payload, err)
+	}
+
+	other := NewTokenSigner([]byte("other"))
+	tampered := signer.Sign([]byte(`{"h":"abd","f":2}`))[:len(token)-43] + token[len(token)-43:]
+	for _, invalid := range []string{"", "payload", token + "x", other.Sign([]byte("x")), tampered} {
+		if _, err := signer.Verify(invalid); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify(%q) should fail, got %v", invalid, err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package torbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amaumene/gostremiofr/pkg/httputil"
+)
+
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+func NewClient() *Client {
+	return &Client{
+		httpClient: httputil.NewHTTPClient(30 * time.Second),
+		baseURL:    "https://api.torbox.app/v1/api",
+	}
+}
+
+// Response is the envelope shared by every TorBox endpoint
+type Response struct {
+	Success bool            `json:"success"`
+	Error   string          `json:"error"`
+	Detail  string          `json:"detail"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// CreateTorrentData is the payload returned when a torrent is added
+type CreateTorrentData struct {
+	TorrentID int64  `json:"torrent_id"`
+	Hash      string `json:"hash"`
+	Name      string `json:"name"`
+}
+
+// Torrent represents a torrent in the user's list
+type Torrent struct {
+	ID               int64  `json:"id"`
+	Hash             string `json:"hash"`
+	Name             string `json:"name"`
+	Size             int64  `json:"size"`
+	DownloadFinished bool   `json:"download_finished"`
+	DownloadPresent  bool   `json:"download_present"`
+	Files            []struct {
+		ID        int64  `json:"id"`
+		Name      string `json:"name"`
+		ShortName string `json:"short_name"`
+		Size      int64  `json:"size"`
+	} `json:"files"`
+}
+
+// CheckCached returns the subset of hashes that TorBox has cached, keyed by hash
+func (c *Client) CheckCached(ctx context.Context, apiKey string, hashes []string) (map[string]json.RawMessage, error) {
+	params := url.Values{}
+	params.Set("hash", strings.Join(hashes, ","))
+	params.Set("format", "object")
+	params.Set("list_files", "false")
+
+	resp, err := c.do(ctx, apiKey, http.MethodGet, "/torrents/checkcached?"+params.Encode(), nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	cached := make(map[string]json.RawMessage)
+	if len(resp.Data) == 0 || string(resp.Data) == "null" {
+		return cached, nil
+	}
+	if err := json.Unmarshal(resp.Data, &cached); err != nil {
+		return nil, fmt.Errorf("failed to decode cached torrents: %w", err)
+	}
+	return cached, nil
+}
+
+func (c *Client) CreateTorrent(ctx context.Context, apiKey, magnetURL string) (*CreateTorrentData, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("magnet", magnetURL); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := c.do(ctx, apiKey, http.MethodPost, "/torrents/createtorrent", &body, writer.FormDataContentType())
+	if err != nil {
+		return nil, err
+	}
+
+	var data CreateTorrentData
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode created torrent: %w", err)
+	}
+	return &data, nil
+}
+
+func (c *Client) GetTorrent(ctx context.Context, apiKey string, torrentID int64) (*Torrent, error) {
+	params := url.Values{}
+	params.Set("id", strconv.FormatInt(torrentID, 10))
+	params.Set("bypass_cache", "true")
+
+	resp, err := c.do(ctx, apiKey, http.MethodGet, "/torrents/mylist?"+params.Encode(), nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var torrent Torrent
+	if err := json.Unmarshal(resp.Data, &torrent); err != nil {
+		return nil, fmt.Errorf("failed to decode torrent: %w", err)
+	}
+	return &torrent, nil
+}
+
+// RequestDownload returns a direct download URL for one file of a torrent
+func (c *Client) RequestDownload(ctx context.Context, apiKey string, torrentID, fileID int64) (string, error) {
+	params := url.Values{}
+	params.Set("token", apiKey)
+	params.Set("torrent_id", strconv.FormatInt(torrentID, 10))
+	params.Set("file_id", strconv.FormatInt(fileID, 10))
+
+	resp, err := c.do(ctx, apiKey, http.MethodGet, "/torrents/requestdl?"+params.Encode(), nil, "")
+	if err != nil {
+		return "", err
+	}
+
+	var link string
+	if err := json.Unmarshal(resp.Data, &link); err != nil {
+		return "", fmt.Errorf("failed to decode download link: %w", err)
+	}
+	return link, nil
+}
+
+func (c *Client) DeleteTorrent(ctx context.Context, apiKey string, torrentID int64) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"torrent_id": torrentID,
+		"operation":  "delete",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	_, err = c.do(ctx, apiKey, http.MethodPost, "/torrents/controltorrent", bytes.NewReader(payload), "application/json")
+	return err
+}
+
+func (c *Client) do(ctx context.Context, apiKey, method, path string, body io.Reader, contentType string) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return c.decodeResponse(resp)
+}
+
+// APIError represents an error returned by the TorBox API
+type APIError struct {
+	StatusCode int
+	Code       string // e.g. BAD_TOKEN, ACTIVE_LIMIT
+	Detail     string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("TorBox API error: %s - %s", e.Code, e.Detail)
+}
+
+func (c *Client) decodeResponse(resp *http.Response) (*Response, error) {
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var result Response
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if !result.Success {
+		return nil, &APIError{StatusCode: resp.StatusCode, Code: result.Error, Detail: result.Detail}
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,7 @@
+module github.com/amaumene/gostremiofr/pkg/torbox
+
+go 1.24.3
+
+require github.com/amaumene/gostremiofr/pkg/httputil v0.0.0
+
+replace github.com/amaumene/gostremiofr/pkg/httputil => ../httputil
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
@@ -29,6 +30,7 @@ func (c *SimpleCache) Set(key string, value interface{}) {
 }
 
 func main() {
+	ctx := context.Background()
 	cache := NewSimpleCache()
 	
 	search := torrentsearch.New(cache)
@@ -44,7 +46,7 @@ func main() {
 		Language:  "fr",
 	}
 	
-	results, err := search.Search(providers.ProviderYGG, searchOptions)
+	results, err := search.Search(ctx, providers.ProviderYGG, searchOptions)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -69,7 +71,7 @@ func main() {
 		SpecificEpisode: true,
 	}
 	
-	seriesResults, err := search.Search(providers.ProviderYGG, seriesOptions)
+	seriesResults, err := search.Search(ctx, providers.ProviderYGG, seriesOptions)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,203 @@
+// Package filematch maps the files of a torrent to the episodes they contain, so that the
+// right file can be picked from season and complete-series packs.
+package filematch
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// File is a file of a torrent.
+type File struct {
+	Path string // Path inside the torrent with "/" separated folders; a bare file name is fine
+	Size int64  // Size in bytes
+}
+
+// Episode is the numbering recognised in a file path.
+type Episode struct {
+	Season   int   // 0 when the file only carries an absolute number
+	Episodes []int // Episodes contained in the file, several for multi-episode files
+	Absolute int   // Absolute episode number, set when no season is known
+}
+
+// Target is the episode to find.
+type Target struct {
+	Season   int
+	Episode  int
+	Absolute int // Absolute episode number, 0 when unknown
+}
+
+var (
+	// S01E01, S01E01E02, S01E01-E03, S01E01-03, S01.E01
+	seasonEpisodeRegex = regexp.MustCompile(`(?i)\bs(\d{1,2})[ ._-]?e(\d{1,4})((?:[ ._-]?e\d{1,4}|-\d{1,4}\b)*)`)
+	extraEpisodeRegex  = regexp.MustCompile(`(?i)(-)?[ ._]?e?(\d{1,4})`)
+	// 1x01
+	crossEpisodeRegex = regexp.MustCompile(`(?i)\b(\d{1,2})x(\d{2,3})\b`)
+	// [Group] Anime S2 - 05, [Group] Anime 2nd Season - 05
+	animeSeasonRegex = regexp.MustCompile(`(?i)(?:\bs(\d{1,2})|\b(\d)(?:st|nd|rd|th)[ ._]season|\bseason[ ._]?(\d{1,2}))[ ._]+-[ ._]+(\d{1,4})(?:v\d)?\b`)
+	// Season 2 Episode 5, Saison 2 Épisode 5
+	wordedEpisodeRegex = regexp.MustCompile(`(?i)\b(?:season|saison)[ ._-]*(\d{1,2})[ ._-]*(?:episode|[ée]pisode|ep)[ ._-]*(\d{1,4})`)
+	// E05, Ep 05, Episode 5, Épisode 5
+	episodeOnlyRegex = regexp.MustCompile(`(?i)(?:^|[^\pL\d])(?:e|ep|episode|[ée]pisode)[ ._-]?(\d{1,4})(?:[^\pL\d]|$)`)
+	// [Group] Show - 123 [1080p], Show - 05v2
+	absoluteEpisodeRegex = regexp.MustCompile(`(?:^|[ ._])-[ ._]?(\d{1,4})(?:v\d)?(?:[ ._\[(]|$)`)
+	// 05 - Title, only trusted inside a season folder
+	leadingNumberRegex = regexp.MustCompile(`^(\d{1,3})(?:[ ._-]|$)`)
+	// Season 2, Saison 02, S02 folders
+	seasonFolderRegex = regexp.MustCompile(`(?i)(?:^|[^\pL\d])(?:season|saison|s)[ ._-]?(\d{1,2})(?:[^\pL\d]|$)`)
+	// Resolutions and years are not episode numbers
+	noiseRegex = regexp.MustCompile(`(?i)\b(?:\d{3,4}p|(?:19|20)\d{2}|[xh]\.?26[45])\b`)
+)
+
+// videoExtensions are the file types worth playing.
+var videoExtensions = map[string]bool{
+	".mkv": true, ".mp4": true, ".avi": true, ".m4v": true, ".mov": true,
+	".wmv": true, ".ts": true, ".webm": true, ".mpg": true,
+}
+
+// IsVideo reports whether the file has a video extension.
+func IsVideo(filePath string) bool {
+	return videoExtensions[strings.ToLower(path.Ext(filePath))]
+}
+
+// Parse extracts the episode numbering of a file. The season of a parent folder
+// ("Season 2", "Saison 02", "S02") is used when the file name carries none.
+// It reports false when no episode number is recognised.
+func Parse(filePath string) (Episode, bool) {
+	filePath = strings.ReplaceAll(filePath, "\\", "/")
+	name := strings.TrimSuffix(path.Base(filePath), path.Ext(filePath))
+
+	if episode, ok := parseName(name); ok {
+		return episode, true
+	}
+
+	folderSeason := folderSeason(path.Dir(filePath))
+	if match := episodeOnlyRegex.FindStringSubmatch(name); match != nil {
+		return numbered(folderSeason, atoi(match[1])), true
+	}
+
+	cleaned := noiseRegex.ReplaceAllString(name, " ")
+	if match := absoluteEpisodeRegex.FindStringSubmatch(cleaned); match != nil {
+		return numbered(folderSeason, atoi(match[1])), true
+	}
+
+	if match := leadingNumberRegex.FindStringSubmatch(cleaned); match != nil && folderSeason > 0 {
+		return numbered(folderSeason, atoi(match[1])), true
+	}
+
+	return Episode{}, false
+}
+
+// parseName recognises the numbering forms carrying both season and episode.
+func parseName(name string) (Episode, bool) {
+	if match := seasonEpisodeRegex.FindStringSubmatch(name); match != nil {
+		episode := Episode{Season: atoi(match[1]), Episodes: []int{atoi(match[2])}}
+		for _, extra := range extraEpisodeRegex.FindAllStringSubmatch(match[3], -1) {
+			last := episode.Episodes[len(episode.Episodes)-1]
+			number := atoi(extra[2])
+			if extra[1] == "" || number <= last {
+				episode.Episodes = append(episode.Episodes, number)
+				continue
+			}
+			// Ranges like E01-E03 include the episodes in between
+			for n := last + 1; n <= number; n++ {
+				episode.Episodes = append(episode.Episodes, n)
+			}
+		}
+		return episode, true
+	}
+
+	if match := wordedEpisodeRegex.FindStringSubmatch(name); match != nil {
+		return Episode{Season: atoi(match[1]), Episodes: []int{atoi(match[2])}}, true
+	}
+
+	if match := crossEpisodeRegex.FindStringSubmatch(name); match != nil {
+		return Episode{Season: atoi(match[1]), Episodes: []int{atoi(match[2])}}, true
+	}
+
+	if match := animeSeasonRegex.FindStringSubmatch(name); match != nil {
+		season := atoi(match[1] + match[2] + match[3])
+		return Episode{Season: season, Episodes: []int{atoi(match[4])}}, true
+	}
+
+	return Episode{}, false
+}
+
+// folderSeason returns the season of the closest parent folder naming one, or 0.
+func folderSeason(dir string) int {
+	folders := strings.Split(dir, "/")
+	for i := len(folders) - 1; i >= 0; i-- {
+		if match := seasonFolderRegex.FindStringSubmatch(folders[i]); match != nil {
+			return atoi(match[1])
+		}
+	}
+	return 0
+}
+
+// numbered returns a single-episode numbering, absolute when the season is unknown.
+func numbered(season, number int) Episode {
+	if season > 0 {
+		return Episode{Season: season, Episodes: []int{number}}
+	}
+	return Episode{Episodes: []int{number}, Absolute: number}
+}
+
+// Contains reports whether the file holds the given episode of a season.
+func (e Episode) Contains(season, episode int) bool {
+	if e.Season != season {
+		return false
+	}
+	for _, n := range e.Episodes {
+		if n == episode {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the file holds the target episode, by season and episode
+// or, for files without season, by absolute number.
+func (e Episode) Matches(target Target) bool {
+	if e.Contains(target.Season, target.Episode) {
+		return true
+	}
+	return e.Season == 0 && target.Absolute > 0 && e.Absolute == target.Absolute
+}
+
+// Match returns the index of the video file holding the target episode. Single-episode
+// files are preferred over multi-episode ones, then larger files over smaller ones.
+// It reports false rather than guessing when no file is numbered as the target.
+func Match(files []File, target Target) (int, bool) {
+	best := -1
+	var bestEpisodes int
+	var bestSize int64
+
+	for i, file := range files {
+		if !IsVideo(file.Path) || isSample(file.Path) {
+			continue
+		}
+		episode, ok := Parse(file.Path)
+		if !ok || !episode.Matches(target) {
+			continue
+		}
+
+		count := len(episode.Episodes)
+		if best < 0 || count < bestEpisodes || (count == bestEpisodes && file.Size > bestSize) {
+			best, bestEpisodes, bestSize = i, count, file.Size
+		}
+	}
+
+	return best, best >= 0
+}
+
+// isSample reports whether the file is a preview sample.
+func isSample(filePath string) bool {
+	return strings.Contains(strings.ToLower(filePath), "sample")
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,77 @@
+package filematch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		path string
+		want Episode
+		ok   bool
+	}{
+		{"Show.S02E05.1080p.WEB-DL.x264-GRP.mkv", Episode{Season: 2, Episodes: []int{5}}, true},
+		{"Show.S01E01E02.720p.mkv", Episode{Season: 1, Episodes: []int{1, 2}}, true},
+		{"Show.S01E01-E03.1080p.mkv", Episode{Season: 1, Episodes: []int{1, 2, 3}}, true},
+		{"Show.S01E04-05.1080p.mkv", Episode{Season: 1, Episodes: []int{4, 5}}, true},
+		{"Show.S01.E07.1080p-1080p.mkv", Episode{Season: 1, Episodes: []int{7}}, true},
+		{"Show 3x12 HDTV.avi", Episode{Season: 3, Episodes: []int{12}}, true},
+		{"Show Season 2 Episode 5.mp4", Episode{Season: 2, Episodes: []int{5}}, true},
+		{"Série Saison 2 Épisode 5 VFF.mkv", Episode{Season: 2, Episodes: []int{5}}, true},
+		{"Show.Complete/Saison 3/Épisode 08.mkv", Episode{Season: 3, Episodes: []int{8}}, true},
+		{"Show/Season 02/05 - Pilot.mkv", Episode{Season: 2, Episodes: []int{5}}, true},
+		{"Show.Integrale/Show.S04.FRENCH.1080p/E11.mkv", Episode{Season: 4, Episodes: []int{11}}, true},
+		{"[SubsPlease] Anime - 123 (1080p) [ABCD1234].mkv", Episode{Episodes: []int{123}, Absolute: 123}, true},
+		{"[Group] Anime - 07v2 [720p].mkv", Episode{Episodes: []int{7}, Absolute: 7}, true},
+		{"[SubsPlease] Anime S3 - 05 (1080p).mkv", Episode{Season: 3, Episodes: []int{5}}, true},
+		{"[Group] Anime 2nd Season - 11 [1080p].mkv", Episode{Season: 2, Episodes: []int{11}}, true},
+		{"Movie.2019.1920x1080.mkv", Episode{}, false},
+		{"Show/Extras/Behind the scenes.mkv", Episode{}, false},
+		{"05 - Track.mkv", Episode{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Parse(tt.path)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, %t; want %+v, %t", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	pack := []File{
+		{Path: "Show.S02/Show.S02E04.1080p.mkv", Size: 2000},
+		{Path: "Show.S02/Show.S02E05E06.1080p.mkv", Size: 4000},
+		{Path: "Show.S02/Show.S02E05.1080p.mkv", Size: 1900},
+		{Path: "Show.S02/Sample/Show.S02E05.sample.mkv", Size: 50},
+		{Path: "Show.S02/Show.S02E05.1080p.nfo", Size: 1},
+		{Path: "Show.S02/Bonus.mkv", Size: 9000},
+	}
+	anime := []File{
+		{Path: "[Group] Anime - 12 [1080p].mkv", Size: 1000},
+		{Path: "[Group] Anime - 13 [1080p].mkv", Size: 1000},
+	}
+
+	tests := []struct {
+		name   string
+		files  []File
+		target Target
+		want   int
+		ok     bool
+	}{
+		{"single episode file", pack, Target{Season: 2, Episode: 5}, 2, true},
+		{"multi-episode file", pack, Target{Season: 2, Episode: 6}, 1, true},
+		{"missing episode is refused", pack, Target{Season: 2, Episode: 9}, -1, false},
+		{"other season is refused", pack, Target{Season: 1, Episode: 4}, -1, false},
+		{"absolute numbering", anime, Target{Season: 1, Episode: 13, Absolute: 13}, 1, true},
+		{"absolute numbering unknown", anime, Target{Season: 2, Episode: 1}, -1, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Match(tt.files, tt.target)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: Match = %d, %t; want %d, %t", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -16,6 +16,7 @@ type TorrentInfo struct {
 	Size             int64
 	Seeders          int
 	Leechers         int
+	UploadDate       time.Time // Zero when the provider does not report it
 	Type             string
 	Season           int
 	Episode          int
@@ -35,6 +36,7 @@ type SearchResults struct {
 type CombinedSearchResults struct {
 	Results   map[string]*SearchResults // Provider name -> results
 	DebugInfo map[string]string         // Provider name -> debug info (e.g., API URLs)
+	Weights   map[string]float64        // Provider name -> ranking weight
 }
 
 // ParsedFileName contains parsed information from torrent file names.
@@ -60,6 +62,8 @@ type SearchOptions struct {
 	Year            int
 	Language        string
 	SpecificEpisode bool
+	CompleteSeries  bool // Search packs of every season rather than a season or episode
+	AbsoluteEpisode int  // Anime episode number, searched instead of s01e05 for specific episodes
 	ResolutionFilter []string
 	MaxResults      int
 }
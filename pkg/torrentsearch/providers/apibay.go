@@ -2,6 +2,7 @@
 package providers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -33,9 +34,17 @@ type Cache interface {
 type ApiBayProvider struct {
 	httpClient    *http.Client
 	cache         Cache
+	baseURL       string
+	categories    map[string][]string
 	yearExtractor *yearExtractor
 }
 
+// apibayDefaultCategories are the ApiBay categories searched for each media type.
+var apibayDefaultCategories = map[string][]string{
+	"movie":  {apibayVideoCategory},
+	"series": {apibayVideoCategory},
+}
+
 type yearExtractor struct {
 	patterns []*regexp.Regexp
 }
@@ -58,10 +67,15 @@ type ApiBayResponse []ApiBayTorrent
 
 // NewApiBayProvider creates a new ApiBay provider instance.
 func NewApiBayProvider() *ApiBayProvider {
+	return NewApiBayProviderWithOptions(Options{})
+}
+
+// NewApiBayProviderWithOptions creates an ApiBay provider, e.g. pointing at a mirror.
+func NewApiBayProviderWithOptions(opts Options) *ApiBayProvider {
 	return &ApiBayProvider{
-		httpClient: &http.Client{
-			Timeout: apibayTimeout,
-		},
+		httpClient:    opts.httpClient(apibayTimeout),
+		baseURL:       opts.baseURLOrDefault(apibayAPIBase),
+		categories:    opts.categoriesOrDefault(apibayDefaultCategories),
 		yearExtractor: newYearExtractor(),
 	}
 }
@@ -90,15 +104,14 @@ func (a *ApiBayProvider) SetCache(cache interface{}) {
 }
 
 // Search searches for torrents using ApiBay API.
-func (a *ApiBayProvider) Search(options models.SearchOptions) (*models.SearchResults, error) {
+func (a *ApiBayProvider) Search(ctx context.Context, options models.SearchOptions) (*models.SearchResults, error) {
 	cacheKey := a.buildCacheKey(options)
 
 	if cached := a.getCachedResults(cacheKey); cached != nil {
 		return cached, nil
 	}
 
-	query := utils.BuildSearchQuery(options.Query, options.MediaType, options.Season, options.Episode, options.SpecificEpisode)
-	torrents, err := a.fetchTorrents(a.buildAPIURL(query))
+	torrents, err := a.fetchTorrents(ctx, a.SearchURL(options))
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +124,7 @@ func (a *ApiBayProvider) Search(options models.SearchOptions) (*models.SearchRes
 
 // buildCacheKey creates a cache key for the search options.
 func (a *ApiBayProvider) buildCacheKey(options models.SearchOptions) string {
-	return fmt.Sprintf("apibay_search:%s:%s:%d:%d", options.Query, options.MediaType, options.Season, options.Episode)
+	return fmt.Sprintf("apibay_search:%s:%s:%d:%d:%t:%t:%d", options.Query, options.MediaType, options.Season, options.Episode, options.SpecificEpisode, options.CompleteSeries, options.AbsoluteEpisode)
 }
 
 // getCachedResults retrieves cached search results.
@@ -135,16 +148,26 @@ func (a *ApiBayProvider) cacheResults(cacheKey string, results *models.SearchRes
 	}
 }
 
+// SearchURL returns the ApiBay API URL queried for the given options.
+func (a *ApiBayProvider) SearchURL(options models.SearchOptions) string {
+	query := searchQuery(options)
+	return a.buildAPIURL(query, options.MediaType)
+}
+
 // buildAPIURL constructs the ApiBay API URL with query parameters.
-func (a *ApiBayProvider) buildAPIURL(query string) string {
+func (a *ApiBayProvider) buildAPIURL(query, mediaType string) string {
 	encodedQuery := url.QueryEscape(query)
+	category := apibayVideoCategory
+	if categories := a.categories[mediaType]; len(categories) > 0 {
+		category = strings.Join(categories, ",")
+	}
 	return fmt.Sprintf("%s%s?q=%s&cat=%s",
-		apibayAPIBase, apibaySearchEndpoint, encodedQuery, apibayVideoCategory)
+		a.baseURL, apibaySearchEndpoint, encodedQuery, category)
 }
 
 // fetchTorrents makes HTTP request to ApiBay API and returns torrent list.
-func (a *ApiBayProvider) fetchTorrents(apiURL string) ([]ApiBayTorrent, error) {
-	resp, err := a.httpClient.Get(apiURL)
+func (a *ApiBayProvider) fetchTorrents(ctx context.Context, apiURL string) ([]ApiBayTorrent, error) {
+	resp, err := httpGet(ctx, a.httpClient, apiURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search ApiBay: %w", err)
 	}
@@ -203,15 +226,17 @@ func (a *ApiBayProvider) buildTorrentInfo(torrent ApiBayTorrent) models.TorrentI
 	seeders, _ := strconv.Atoi(torrent.Seeders)
 	leechers, _ := strconv.Atoi(torrent.Leechers)
 	size, _ := strconv.ParseInt(torrent.Size, 10, 64)
+	added, _ := strconv.ParseInt(torrent.Added, 10, 64)
 
 	return models.TorrentInfo{
-		ID:       torrent.ID,
-		Title:    torrent.Name,
-		Hash:     strings.ToLower(torrent.InfoHash),
-		Source:   ProviderApiBay,
-		Size:     size,
-		Seeders:  seeders,
-		Leechers: leechers,
+		ID:         torrent.ID,
+		Title:      torrent.Name,
+		Hash:       strings.ToLower(torrent.InfoHash),
+		Source:     ProviderApiBay,
+		Size:       size,
+		Seeders:    seeders,
+		Leechers:   leechers,
+		UploadDate: unixTime(added),
 	}
 }
 
@@ -225,13 +250,18 @@ func (a *ApiBayProvider) classifyAndAdd(info models.TorrentInfo, torrent ApiBayT
 	}
 }
 
-// classifySeries classifies series torrents by episode or season.
+// classifySeries classifies series torrents by episode, complete series or season.
 func (a *ApiBayProvider) classifySeries(info models.TorrentInfo, torrent ApiBayTorrent, options models.SearchOptions, results *models.SearchResults) {
-	if options.Episode > 0 && utils.MatchesEpisode(torrent.Name, options.Season, options.Episode) {
+	if options.Episode > 0 && (utils.MatchesEpisode(torrent.Name, options.Season, options.Episode) || utils.MatchesAbsoluteEpisode(torrent.Name, options.AbsoluteEpisode)) {
 		results.EpisodeTorrents = append(results.EpisodeTorrents, info)
 		return
 	}
 
+	if utils.MatchesCompleteSeries(torrent.Name, options.Season) {
+		results.CompleteSeriesTorrents = append(results.CompleteSeriesTorrents, info)
+		return
+	}
+
 	if options.Season > 0 && utils.MatchesSeason(torrent.Name, options.Season) {
 		results.CompleteSeasonTorrents = append(results.CompleteSeasonTorrents, info)
 		return
@@ -242,7 +272,7 @@ func (a *ApiBayProvider) classifySeries(info models.TorrentInfo, torrent ApiBayT
 
 // GetTorrentHash returns the torrent hash for a given ID.
 // ApiBay includes hashes in search results, so this method is not needed.
-func (a *ApiBayProvider) GetTorrentHash(torrentID string) (string, error) {
+func (a *ApiBayProvider) GetTorrentHash(ctx context.Context, torrentID string) (string, error) {
 	return "", fmt.Errorf("ApiBay provider: hash already included in search results")
 }
 
@@ -5,4 +5,5 @@ const (
 	ProviderYGG        = "ygg"
 	ProviderApiBay     = "apibay"
 	ProviderTorrentsCSV = "torrentscsv"
+	ProviderTorznab     = "torznab"
 )
\ No newline at end of file
@@ -0,0 +1,37 @@
+package providers
+
+import (
+	"strings"
+	"time"
+)
+
+// uploadDateLayouts are the date formats used by the provider APIs, RSS feeds included.
+var uploadDateLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseUploadDate parses a provider upload date, returning the zero time if it is missing or unknown.
+func parseUploadDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range uploadDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// unixTime converts a Unix timestamp in seconds, returning the zero time for unset values.
+func unixTime(seconds int64) time.Time {
+	if seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0).UTC()
+}
@@ -0,0 +1,15 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+)
+
+// httpGet performs a GET request that is abandoned as soon as ctx is cancelled.
+func httpGet(ctx context.Context, client *http.Client, apiURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
@@ -0,0 +1,40 @@
+package providers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Options configures a provider instance. Zero values select the provider defaults.
+type Options struct {
+	BaseURL    string              // API base URL, e.g. a mirror of the public instance
+	Timeout    time.Duration       // HTTP client timeout
+	Categories map[string][]string // Category IDs per media type ("movie", "series"), replacing the defaults
+	APIKey     string              // API key, used by Torznab indexers
+}
+
+// baseURLOrDefault returns the configured base URL without trailing slash, or the default.
+func (o Options) baseURLOrDefault(defaultURL string) string {
+	if o.BaseURL == "" {
+		return defaultURL
+	}
+	return strings.TrimSuffix(o.BaseURL, "/")
+}
+
+// categoriesOrDefault returns the configured categories, or the defaults when none are set.
+func (o Options) categoriesOrDefault(defaults map[string][]string) map[string][]string {
+	if len(o.Categories) == 0 {
+		return defaults
+	}
+	return o.Categories
+}
+
+// httpClient returns an HTTP client using the configured timeout, or the default.
+func (o Options) httpClient(fallback time.Duration) *http.Client {
+	timeout := o.Timeout
+	if timeout <= 0 {
+		timeout = fallback
+	}
+	return &http.Client{Timeout: timeout}
+}
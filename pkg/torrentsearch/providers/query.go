@@ -0,0 +1,17 @@
+package providers
+
+import (
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/models"
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/utils"
+)
+
+// searchQuery builds the query sent to "+" separated search APIs.
+func searchQuery(options models.SearchOptions) string {
+	if options.MediaType == "series" && options.CompleteSeries {
+		return utils.BuildCompleteSeriesQuery(options.Query, options.Language)
+	}
+	if options.MediaType == "series" && options.SpecificEpisode && options.AbsoluteEpisode > 0 {
+		return utils.BuildAnimeQuery(options.Query, options.AbsoluteEpisode)
+	}
+	return utils.BuildSearchQuery(options.Query, options.MediaType, options.Season, options.Episode, options.SpecificEpisode)
+}
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/amaumene/gostremiofr/pkg/torrentsearch/models"
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/utils"
 	"github.com/cehbz/torrentname"
 )
 
@@ -35,6 +37,7 @@ var (
 type TorrentsCSVProvider struct {
 	httpClient *http.Client
 	cache      interface{}
+	baseURL    string
 }
 
 type torrentsCSVResponse struct {
@@ -54,10 +57,15 @@ type torrentsCSVTorrent struct {
 }
 
 func NewTorrentsCSVProvider() *TorrentsCSVProvider {
+	return NewTorrentsCSVProviderWithOptions(Options{})
+}
+
+// NewTorrentsCSVProviderWithOptions creates a TorrentsCSV provider, e.g. pointing at a self-hosted instance.
+// TorrentsCSV has no categories, so Options.Categories is ignored.
+func NewTorrentsCSVProviderWithOptions(opts Options) *TorrentsCSVProvider {
 	return &TorrentsCSVProvider{
-		httpClient: &http.Client{
-			Timeout: defaultTimeout,
-		},
+		httpClient: opts.httpClient(defaultTimeout),
+		baseURL:    opts.baseURLOrDefault(torrentsCSVAPIBase),
 	}
 }
 
@@ -65,10 +73,8 @@ func (p *TorrentsCSVProvider) SetCache(cache interface{}) {
 	p.cache = cache
 }
 
-func (p *TorrentsCSVProvider) Search(options models.SearchOptions) (*models.SearchResults, error) {
-	query := buildSearchQuery(options)
-	
-	torrents, err := p.fetchTorrents(query)
+func (p *TorrentsCSVProvider) Search(ctx context.Context, options models.SearchOptions) (*models.SearchResults, error) {
+	torrents, err := p.fetchTorrents(ctx, p.SearchURL(options))
 	if err != nil {
 		return nil, err
 	}
@@ -81,16 +87,19 @@ func (p *TorrentsCSVProvider) Search(options models.SearchOptions) (*models.Sear
 	return p.processResults(torrents, options), nil
 }
 
-func (p *TorrentsCSVProvider) GetTorrentHash(torrentID string) (string, error) {
+func (p *TorrentsCSVProvider) GetTorrentHash(ctx context.Context, torrentID string) (string, error) {
 	// TorrentsCSV returns hashes directly in search results
 	return torrentID, nil
 }
 
-func (p *TorrentsCSVProvider) fetchTorrents(query string) ([]torrentsCSVTorrent, error) {
-	encodedQuery := url.QueryEscape(query)
-	apiURL := fmt.Sprintf("%s%s?q=%s", torrentsCSVAPIBase, torrentsCSVSearchEndpoint, encodedQuery)
+// SearchURL returns the TorrentsCSV API URL queried for the given options.
+func (p *TorrentsCSVProvider) SearchURL(options models.SearchOptions) string {
+	encodedQuery := url.QueryEscape(buildSearchQuery(options))
+	return fmt.Sprintf("%s%s?q=%s", p.baseURL, torrentsCSVSearchEndpoint, encodedQuery)
+}
 
-	resp, err := p.httpClient.Get(apiURL)
+func (p *TorrentsCSVProvider) fetchTorrents(ctx context.Context, apiURL string) ([]torrentsCSVTorrent, error) {
+	resp, err := httpGet(ctx, p.httpClient, apiURL)
 	if err != nil {
 		return nil, fmt.Errorf("API request failed: %w", err)
 	}
@@ -142,17 +151,22 @@ func (p *TorrentsCSVProvider) processResults(torrents []torrentsCSVTorrent, opti
 
 func (p *TorrentsCSVProvider) convertToTorrentInfo(torrent torrentsCSVTorrent) models.TorrentInfo {
 	return models.TorrentInfo{
-		ID:       fmt.Sprintf("%d", torrent.RowID),
-		Title:    torrent.Name,
-		Hash:     torrent.InfoHash,
-		Source:   ProviderTorrentsCSV,
-		Size:     torrent.SizeBytes,
-		Seeders:  torrent.Seeders,
-		Leechers: torrent.Leechers,
+		ID:         fmt.Sprintf("%d", torrent.RowID),
+		Title:      torrent.Name,
+		Hash:       torrent.InfoHash,
+		Source:     ProviderTorrentsCSV,
+		Size:       torrent.SizeBytes,
+		Seeders:    torrent.Seeders,
+		Leechers:   torrent.Leechers,
+		UploadDate: unixTime(torrent.CreatedUnix),
 	}
 }
 
 func (p *TorrentsCSVProvider) classifyTorrent(info models.TorrentInfo, options models.SearchOptions) string {
+	if options.MediaType == "series" && utils.MatchesAbsoluteEpisode(info.Title, options.AbsoluteEpisode) {
+		return "episode"
+	}
+
 	parsed := torrentname.Parse(info.Title)
 	if parsed == nil {
 		if options.MediaType == "movie" {
@@ -167,7 +181,7 @@ func (p *TorrentsCSVProvider) classifyTorrent(info models.TorrentInfo, options m
 	}
 
 	if options.MediaType == "series" {
-		if parsed.IsComplete {
+		if utils.MatchesCompleteSeries(info.Title, options.Season) {
 			return "complete_series"
 		}
 		if options.Season > 0 && parsed.Season == options.Season {
@@ -214,7 +228,11 @@ func buildSearchQuery(options models.SearchOptions) string {
 	
 	// Build query based on media type
 	if options.MediaType == "series" {
-		if options.SpecificEpisode && options.Episode > 0 {
+		if options.CompleteSeries {
+			query = fmt.Sprintf("%s %s", query, utils.CompleteSeriesKeyword(options.Language))
+		} else if options.SpecificEpisode && options.AbsoluteEpisode > 0 {
+			query = fmt.Sprintf("%s %02d", query, options.AbsoluteEpisode)
+		} else if options.SpecificEpisode && options.Episode > 0 {
 			query = fmt.Sprintf("%s s%02de%02d", query, options.Season, options.Episode)
 		} else if options.Season > 0 {
 			query = fmt.Sprintf("%s s%02d", query, options.Season)
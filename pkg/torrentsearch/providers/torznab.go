@@ -0,0 +1,312 @@
+package providers
+
+import (
+	"context"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/models"
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/utils"
+)
+
+const (
+	torznabTimeout          = 30 * time.Second
+	torznabMovieCategory    = "2000"
+	torznabTVCategory       = "5000"
+	torznabRedactedAPIKey   = "REDACTED"
+	torznabMaxResponseBytes = 10 << 20
+)
+
+// torznabDefaultCategories are the standard Newznab categories searched for each media type.
+var torznabDefaultCategories = map[string][]string{
+	"movie":  {torznabMovieCategory},
+	"series": {torznabTVCategory},
+}
+
+var btihPattern = regexp.MustCompile(`(?i)urn:btih:([a-f0-9]{40}|[a-z2-7]{32})`)
+
+// TorznabProvider implements the TorrentProvider interface for any Torznab indexer,
+// such as a self-hosted Jackett or Prowlarr instance.
+type TorznabProvider struct {
+	name       string
+	baseURL    string
+	apiKey     string
+	categories map[string][]string
+	httpClient *http.Client
+	cache      Cache
+}
+
+// torznabFeed is the RSS document returned by a Torznab search, or an <error> element.
+type torznabFeed struct {
+	XMLName     xml.Name
+	Code        string        `xml:"code,attr"`
+	Description string        `xml:"description,attr"`
+	Items       []torznabItem `xml:"channel>item"`
+}
+
+// torznabItem represents a single release in a Torznab feed.
+type torznabItem struct {
+	Title     string `xml:"title"`
+	GUID      string `xml:"guid"`
+	Link      string `xml:"link"`
+	Size      int64  `xml:"size"`
+	PubDate   string `xml:"pubDate"`
+	Enclosure struct {
+		URL    string `xml:"url,attr"`
+		Length int64  `xml:"length,attr"`
+	} `xml:"enclosure"`
+	Attrs []torznabAttr `xml:"attr"`
+}
+
+// torznabAttr is a torznab:attr (or newznab:attr) name/value pair.
+type torznabAttr struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// NewTorznabProvider creates a provider for the Torznab endpoint at baseURL.
+// The name is used as the torrent source and should match the name the provider is registered under.
+// baseURL may point either at the indexer feed or at its /api endpoint, e.g.
+// http://jackett:9117/api/v2.0/indexers/all/results/torznab.
+func NewTorznabProvider(name, baseURL, apiKey string) *TorznabProvider {
+	return NewTorznabProviderWithOptions(name, Options{BaseURL: baseURL, APIKey: apiKey})
+}
+
+// NewTorznabProviderWithOptions creates a provider for the Torznab endpoint at opts.BaseURL.
+func NewTorznabProviderWithOptions(name string, opts Options) *TorznabProvider {
+	return &TorznabProvider{
+		name:       name,
+		baseURL:    strings.TrimSuffix(opts.baseURLOrDefault(""), "/api"),
+		apiKey:     opts.APIKey,
+		categories: opts.categoriesOrDefault(torznabDefaultCategories),
+		httpClient: opts.httpClient(torznabTimeout),
+	}
+}
+
+// SetCache sets the cache for the Torznab provider.
+func (t *TorznabProvider) SetCache(cache interface{}) {
+	if c, ok := cache.(Cache); ok {
+		t.cache = c
+	}
+}
+
+// Search searches the Torznab indexer.
+func (t *TorznabProvider) Search(ctx context.Context, options models.SearchOptions) (*models.SearchResults, error) {
+	cacheKey := t.buildCacheKey(options)
+
+	if cached := t.getCachedResults(cacheKey); cached != nil {
+		return cached, nil
+	}
+
+	items, err := t.fetchItems(ctx, t.buildAPIURL(options, t.apiKey))
+	if err != nil {
+		return nil, err
+	}
+
+	results := t.classifyItems(items, options)
+	t.cacheResults(cacheKey, results)
+
+	return results, nil
+}
+
+// SearchURL returns the URL queried for the given options, with the API key redacted.
+func (t *TorznabProvider) SearchURL(options models.SearchOptions) string {
+	return t.buildAPIURL(options, torznabRedactedAPIKey)
+}
+
+// GetTorrentHash returns the torrent hash for a given ID.
+// Releases without an infohash or magnet link are dropped from search results, so this method is not needed.
+func (t *TorznabProvider) GetTorrentHash(ctx context.Context, torrentID string) (string, error) {
+	return "", fmt.Errorf("Torznab provider %s: hash already included in search results", t.name)
+}
+
+// buildCacheKey creates a cache key for the search options.
+func (t *TorznabProvider) buildCacheKey(options models.SearchOptions) string {
+	return fmt.Sprintf("torznab_search:%s:%s:%s:%d:%d:%t:%t:%d", t.name, options.Query, options.MediaType, options.Season, options.Episode, options.SpecificEpisode, options.CompleteSeries, options.AbsoluteEpisode)
+}
+
+// getCachedResults retrieves cached search results.
+func (t *TorznabProvider) getCachedResults(cacheKey string) *models.SearchResults {
+	if t.cache == nil {
+		return nil
+	}
+
+	if cached, found := t.cache.Get(cacheKey); found {
+		if results, ok := cached.(*models.SearchResults); ok {
+			return results
+		}
+	}
+	return nil
+}
+
+// cacheResults stores search results in cache.
+func (t *TorznabProvider) cacheResults(cacheKey string, results *models.SearchResults) {
+	if t.cache != nil {
+		t.cache.Set(cacheKey, results)
+	}
+}
+
+// buildAPIURL constructs the Torznab search URL for the given options.
+func (t *TorznabProvider) buildAPIURL(options models.SearchOptions, apiKey string) string {
+	query := searchQuery(options)
+
+	params := url.Values{}
+	params.Set("t", "search")
+	params.Set("apikey", apiKey)
+	params.Set("q", strings.ReplaceAll(query, "+", " "))
+	if categories := t.categories[options.MediaType]; len(categories) > 0 {
+		params.Set("cat", strings.Join(categories, ","))
+	}
+
+	return t.baseURL + "/api?" + params.Encode()
+}
+
+// fetchItems makes the HTTP request to the indexer and returns the feed items.
+func (t *TorznabProvider) fetchItems(ctx context.Context, apiURL string) ([]torznabItem, error) {
+	resp, err := httpGet(ctx, t.httpClient, apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", t.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s Torznab API returned status %d", t.name, resp.StatusCode)
+	}
+
+	var feed torznabFeed
+	decoder := xml.NewDecoder(io.LimitReader(resp.Body, torznabMaxResponseBytes))
+	if err := decoder.Decode(&feed); err != nil {
+		return nil, fmt.Errorf("failed to parse %s Torznab feed: %w", t.name, err)
+	}
+
+	if feed.XMLName.Local == "error" {
+		return nil, fmt.Errorf("%s Torznab error %s: %s", t.name, feed.Code, feed.Description)
+	}
+
+	return feed.Items, nil
+}
+
+// classifyItems converts feed items to SearchResults, skipping releases without an infohash.
+func (t *TorznabProvider) classifyItems(items []torznabItem, options models.SearchOptions) *models.SearchResults {
+	results := &models.SearchResults{
+		MovieTorrents:          []models.TorrentInfo{},
+		CompleteSeriesTorrents: []models.TorrentInfo{},
+		CompleteSeasonTorrents: []models.TorrentInfo{},
+		EpisodeTorrents:        []models.TorrentInfo{},
+	}
+
+	for _, item := range items {
+		info, ok := t.buildTorrentInfo(item)
+		if !ok {
+			continue
+		}
+		t.classifyAndAdd(info, options, results)
+	}
+
+	return results
+}
+
+// buildTorrentInfo converts a feed item to TorrentInfo. It reports false when no infohash can be found.
+func (t *TorznabProvider) buildTorrentInfo(item torznabItem) (models.TorrentInfo, bool) {
+	attrs := make(map[string]string, len(item.Attrs))
+	for _, attr := range item.Attrs {
+		attrs[strings.ToLower(attr.Name)] = attr.Value
+	}
+
+	hash := normalizeInfoHash(attrs["infohash"])
+	for _, link := range []string{attrs["magneturl"], item.Link, item.Enclosure.URL, item.GUID} {
+		if hash != "" {
+			break
+		}
+		hash = infoHashFromMagnet(link)
+	}
+	if hash == "" {
+		return models.TorrentInfo{}, false
+	}
+
+	size := item.Size
+	if size == 0 {
+		size, _ = strconv.ParseInt(attrs["size"], 10, 64)
+	}
+	if size == 0 {
+		size = item.Enclosure.Length
+	}
+
+	seeders, _ := strconv.Atoi(attrs["seeders"])
+	peers, _ := strconv.Atoi(attrs["peers"])
+	leechers := peers - seeders
+	if leechers < 0 {
+		leechers = 0
+	}
+
+	id := item.GUID
+	if id == "" {
+		id = hash
+	}
+
+	return models.TorrentInfo{
+		ID:         id,
+		Title:      item.Title,
+		Hash:       hash,
+		Source:     t.name,
+		Size:       size,
+		Seeders:    seeders,
+		Leechers:   leechers,
+		UploadDate: parseUploadDate(item.PubDate),
+	}, true
+}
+
+// classifyAndAdd classifies torrent and adds to appropriate result category.
+func (t *TorznabProvider) classifyAndAdd(info models.TorrentInfo, options models.SearchOptions, results *models.SearchResults) {
+	switch options.MediaType {
+	case "movie":
+		results.MovieTorrents = append(results.MovieTorrents, info)
+	case "series":
+		if options.Episode > 0 && (utils.MatchesEpisode(info.Title, options.Season, options.Episode) || utils.MatchesAbsoluteEpisode(info.Title, options.AbsoluteEpisode)) {
+			results.EpisodeTorrents = append(results.EpisodeTorrents, info)
+		} else if utils.MatchesCompleteSeries(info.Title, options.Season) {
+			results.CompleteSeriesTorrents = append(results.CompleteSeriesTorrents, info)
+		} else if options.Season > 0 && utils.MatchesSeason(info.Title, options.Season) {
+			results.CompleteSeasonTorrents = append(results.CompleteSeasonTorrents, info)
+		} else {
+			results.EpisodeTorrents = append(results.EpisodeTorrents, info)
+		}
+	}
+}
+
+// infoHashFromMagnet extracts the infohash of a magnet URI, or returns "" if there is none.
+func infoHashFromMagnet(link string) string {
+	if !strings.HasPrefix(link, "magnet:") {
+		return ""
+	}
+	match := btihPattern.FindStringSubmatch(link)
+	if match == nil {
+		return ""
+	}
+	return normalizeInfoHash(match[1])
+}
+
+// normalizeInfoHash returns a lowercase hex infohash, converting base32 hashes, or "" if invalid.
+func normalizeInfoHash(hash string) string {
+	hash = strings.TrimSpace(hash)
+	switch len(hash) {
+	case 40:
+		if _, err := hex.DecodeString(hash); err == nil {
+			return strings.ToLower(hash)
+		}
+	case 32:
+		if decoded, err := base32.StdEncoding.DecodeString(strings.ToUpper(hash)); err == nil {
+			return hex.EncodeToString(decoded)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/models"
+)
+
+const torznabStubFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:torznab="http://torznab.com/schemas/2015/feed">
+  <channel>
+    <item>
+      <title>Le.Film.2020.MULTi.1080p.BluRay.x264</title>
+      <guid>https://tracker.example/details/1</guid>
+      <link>https://jackett.example/dl/1.torrent</link>
+      <size>4294967296</size>
+      <pubDate>Sat, 14 Mar 2020 09:26:53 +0000</pubDate>
+      <torznab:attr name="seeders" value="42" />
+      <torznab:attr name="peers" value="50" />
+      <torznab:attr name="infohash" value="0123456789ABCDEF0123456789ABCDEF01234567" />
+    </item>
+    <item>
+      <title>Le.Film.2020.FRENCH.720p.WEB</title>
+      <guid>https://tracker.example/details/2</guid>
+      <enclosure url="https://jackett.example/dl/2.torrent" length="1073741824" type="application/x-bittorrent" />
+      <torznab:attr name="seeders" value="7" />
+      <torznab:attr name="magneturl" value="magnet:?xt=urn:btih:AERUKZ4JVPG66AJDIVSYCI3NMUWQ2NSF&amp;dn=Le.Film" />
+    </item>
+    <item>
+      <title>Le.Film.2020.FRENCH.2160p.WEB</title>
+      <guid>https://tracker.example/details/3</guid>
+      <link>https://jackett.example/dl/3.torrent</link>
+    </item>
+  </channel>
+</rss>`
+
+func newTorznabStub(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/torznab/api" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("apikey") != "secret" || query.Get("t") != "search" || query.Get("cat") != torznabMovieCategory {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTorznabProviderSearch(t *testing.T) {
+	server := newTorznabStub(t, torznabStubFeed)
+	provider := NewTorznabProvider("jackett", server.URL+"/torznab/", "secret")
+
+	results, err := provider.Search(context.Background(), models.SearchOptions{Query: "Le Film 2020", MediaType: "movie"})
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+
+	torrents := results.MovieTorrents
+	if len(torrents) != 2 {
+		t.Fatalf("expected 2 torrents with a hash, got %d: %+v", len(torrents), torrents)
+	}
+
+	first := torrents[0]
+	if first.Hash != "0123456789abcdef0123456789abcdef01234567" || first.Size != 4294967296 ||
+		first.Seeders != 42 || first.Leechers != 8 || first.Source != "jackett" || first.ID != "https://tracker.example/details/1" {
+		t.Errorf("unexpected first torrent: %+v", first)
+	}
+	if !first.UploadDate.Equal(time.Date(2020, 3, 14, 9, 26, 53, 0, time.UTC)) {
+		t.Errorf("unexpected upload date %v", first.UploadDate)
+	}
+
+	second := torrents[1]
+	if second.Hash != "0123456789abcdef0123456581236d652d0d3645" {
+		t.Errorf("expected base32 magnet hash converted to hex, got %q", second.Hash)
+	}
+	if second.Size != 1073741824 || second.Seeders != 7 {
+		t.Errorf("unexpected second torrent: %+v", second)
+	}
+}
+
+func TestTorznabProviderError(t *testing.T) {
+	server := newTorznabStub(t, `<?xml version="1.0" encoding="UTF-8"?><error code="100" description="Invalid API Key" />`)
+	provider := NewTorznabProvider("jackett", server.URL+"/torznab/api", "secret")
+
+	_, err := provider.Search(context.Background(), models.SearchOptions{Query: "Le Film", MediaType: "movie"})
+	if err == nil || !strings.Contains(err.Error(), "Invalid API Key") {
+		t.Fatalf("expected Torznab error, got %v", err)
+	}
+}
+
+func TestTorznabSearchURLRedactsAPIKey(t *testing.T) {
+	provider := NewTorznabProvider("jackett", "http://jackett:9117/api/v2.0/indexers/all/results/torznab", "secret")
+
+	searchURL := provider.SearchURL(models.SearchOptions{Query: "Show", MediaType: "series", Season: 1})
+	if strings.Contains(searchURL, "secret") || !strings.Contains(searchURL, "cat="+torznabTVCategory) {
+		t.Errorf("unexpected search URL %s", searchURL)
+	}
+}
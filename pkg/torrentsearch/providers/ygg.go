@@ -1,10 +1,12 @@
 package providers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/amaumene/gostremiofr/pkg/torrentsearch/models"
@@ -15,27 +17,34 @@ const (
 	yggAPIBase         = "https://yggapi.eu"
 	yggSearchEndpoint  = "/torrents"
 	yggTorrentEndpoint = "/torrent"
-	movieCategories    = "&category_id=2178&category_id=2181&category_id=2183"
-	seriesCategories   = "&category_id=2179&category_id=2181&category_id=2182&category_id=2184"
 	defaultPage        = 1
 	defaultPerPage     = 100
 	yggTimeout         = 30 * time.Second
 )
 
+// yggDefaultCategories are the YGG category IDs searched for each media type.
+var yggDefaultCategories = map[string][]string{
+	"movie":  {"2178", "2181", "2183"},
+	"series": {"2179", "2181", "2182", "2184"},
+}
+
 // YGGProvider implements the TorrentProvider interface for YGG API.
 type YGGProvider struct {
 	httpClient *http.Client
 	cache      Cache
+	baseURL    string
+	categories map[string][]string
 }
 
 // YGGTorrent represents a torrent from YGG API.
 type YGGTorrent struct {
-	ID       int    `json:"id"`
-	Title    string `json:"title"`
-	Size     int64  `json:"size"`
-	Seeders  int    `json:"seeders"`
-	Leechers int    `json:"leechers"`
-	Hash     string `json:"hash,omitempty"`
+	ID         int    `json:"id"`
+	Title      string `json:"title"`
+	Size       int64  `json:"size"`
+	Seeders    int    `json:"seeders"`
+	Leechers   int    `json:"leechers"`
+	Hash       string `json:"hash,omitempty"`
+	UploadedAt string `json:"uploaded_at,omitempty"`
 }
 
 // YGGTorrentDetail represents detailed torrent information from YGG API.
@@ -46,10 +55,15 @@ type YGGTorrentDetail struct {
 
 // NewYGGProvider creates a new YGG provider instance.
 func NewYGGProvider() *YGGProvider {
+	return NewYGGProviderWithOptions(Options{})
+}
+
+// NewYGGProviderWithOptions creates a YGG provider, e.g. pointing at a mirror.
+func NewYGGProviderWithOptions(opts Options) *YGGProvider {
 	return &YGGProvider{
-		httpClient: &http.Client{
-			Timeout: yggTimeout,
-		},
+		httpClient: opts.httpClient(yggTimeout),
+		baseURL:    opts.baseURLOrDefault(yggAPIBase),
+		categories: opts.categoriesOrDefault(yggDefaultCategories),
 	}
 }
 
@@ -61,15 +75,14 @@ func (y *YGGProvider) SetCache(cache interface{}) {
 }
 
 // Search searches for torrents using YGG API.
-func (y *YGGProvider) Search(options models.SearchOptions) (*models.SearchResults, error) {
+func (y *YGGProvider) Search(ctx context.Context, options models.SearchOptions) (*models.SearchResults, error) {
 	cacheKey := y.buildCacheKey(options)
 
 	if cached := y.getCachedResults(cacheKey); cached != nil {
 		return cached, nil
 	}
 
-	query := utils.BuildSearchQuery(options.Query, options.MediaType, options.Season, options.Episode, options.SpecificEpisode)
-	torrents, err := y.fetchTorrents(y.buildAPIURL(query, options.MediaType))
+	torrents, err := y.fetchTorrents(ctx, y.SearchURL(options))
 	if err != nil {
 		return nil, err
 	}
@@ -82,7 +95,7 @@ func (y *YGGProvider) Search(options models.SearchOptions) (*models.SearchResult
 
 // buildCacheKey creates a cache key for the search options.
 func (y *YGGProvider) buildCacheKey(options models.SearchOptions) string {
-	return fmt.Sprintf("ygg_search:%s:%s:%d:%d", options.Query, options.MediaType, options.Season, options.Episode)
+	return fmt.Sprintf("ygg_search:%s:%s:%d:%d:%t:%t:%d", options.Query, options.MediaType, options.Season, options.Episode, options.SpecificEpisode, options.CompleteSeries, options.AbsoluteEpisode)
 }
 
 // getCachedResults retrieves cached search results.
@@ -106,30 +119,34 @@ func (y *YGGProvider) cacheResults(cacheKey string, results *models.SearchResult
 	}
 }
 
+// SearchURL returns the YGG API URL queried for the given options.
+func (y *YGGProvider) SearchURL(options models.SearchOptions) string {
+	query := searchQuery(options)
+	return y.buildAPIURL(query, options.MediaType)
+}
+
 // buildAPIURL constructs the YGG API URL with query parameters.
 func (y *YGGProvider) buildAPIURL(query, mediaType string) string {
 	// Query already has + for spaces from BuildSearchQuery, no need to escape
 	categories := y.getCategoryParams(mediaType)
 	return fmt.Sprintf("%s%s?q=%s&page=%d&per_page=%d%s",
-		yggAPIBase, yggSearchEndpoint, query, defaultPage, defaultPerPage, categories)
+		y.baseURL, yggSearchEndpoint, query, defaultPage, defaultPerPage, categories)
 }
 
 // getCategoryParams returns category parameters for the given media type.
 func (y *YGGProvider) getCategoryParams(mediaType string) string {
-	switch mediaType {
-	case "movie":
-		return movieCategories
-	case "series":
-		return seriesCategories
-	default:
-		return ""
+	var params strings.Builder
+	for _, category := range y.categories[mediaType] {
+		params.WriteString("&category_id=")
+		params.WriteString(category)
 	}
+	return params.String()
 }
 
 // fetchTorrents makes HTTP request to YGG API and returns torrent list.
-func (y *YGGProvider) fetchTorrents(apiURL string) ([]YGGTorrent, error) {
+func (y *YGGProvider) fetchTorrents(ctx context.Context, apiURL string) ([]YGGTorrent, error) {
 	// Debug log the API URL (this will be captured by the parent handler)
-	resp, err := y.httpClient.Get(apiURL)
+	resp, err := httpGet(ctx, y.httpClient, apiURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search YGG: %w", err)
 	}
@@ -178,13 +195,14 @@ func (y *YGGProvider) classifyTorrents(torrents []YGGTorrent, options models.Sea
 // buildTorrentInfo converts YGGTorrent to TorrentInfo.
 func (y *YGGProvider) buildTorrentInfo(torrent YGGTorrent) models.TorrentInfo {
 	return models.TorrentInfo{
-		ID:       fmt.Sprintf("%d", torrent.ID),
-		Title:    torrent.Title,
-		Hash:     torrent.Hash,
-		Source:   ProviderYGG,
-		Size:     torrent.Size,
-		Seeders:  torrent.Seeders,
-		Leechers: torrent.Leechers,
+		ID:         fmt.Sprintf("%d", torrent.ID),
+		Title:      torrent.Title,
+		Hash:       torrent.Hash,
+		Source:     ProviderYGG,
+		Size:       torrent.Size,
+		Seeders:    torrent.Seeders,
+		Leechers:   torrent.Leechers,
+		UploadDate: parseUploadDate(torrent.UploadedAt),
 	}
 }
 
@@ -198,13 +216,18 @@ func (y *YGGProvider) classifyAndAdd(info models.TorrentInfo, torrent YGGTorrent
 	}
 }
 
-// classifySeries classifies series torrents by episode or season.
+// classifySeries classifies series torrents by episode, complete series or season.
 func (y *YGGProvider) classifySeries(info models.TorrentInfo, torrent YGGTorrent, options models.SearchOptions, results *models.SearchResults) {
-	if options.Episode > 0 && utils.MatchesEpisode(torrent.Title, options.Season, options.Episode) {
+	if options.Episode > 0 && (utils.MatchesEpisode(torrent.Title, options.Season, options.Episode) || utils.MatchesAbsoluteEpisode(torrent.Title, options.AbsoluteEpisode)) {
 		results.EpisodeTorrents = append(results.EpisodeTorrents, info)
 		return
 	}
 
+	if utils.MatchesCompleteSeries(torrent.Title, options.Season) {
+		results.CompleteSeriesTorrents = append(results.CompleteSeriesTorrents, info)
+		return
+	}
+
 	if options.Season > 0 && utils.MatchesSeason(torrent.Title, options.Season) {
 		results.CompleteSeasonTorrents = append(results.CompleteSeasonTorrents, info)
 		return
@@ -215,14 +238,14 @@ func (y *YGGProvider) classifySeries(info models.TorrentInfo, torrent YGGTorrent
 
 
 // GetTorrentHash fetches the torrent hash for a given ID from YGG API.
-func (y *YGGProvider) GetTorrentHash(torrentID string) (string, error) {
+func (y *YGGProvider) GetTorrentHash(ctx context.Context, torrentID string) (string, error) {
 	cacheKey := fmt.Sprintf("ygg_hash:%s", torrentID)
 
 	if cached := y.getCachedHash(cacheKey); cached != "" {
 		return cached, nil
 	}
 
-	hash, err := y.fetchTorrentHash(torrentID)
+	hash, err := y.fetchTorrentHash(ctx, torrentID)
 	if err != nil {
 		return "", err
 	}
@@ -253,11 +276,11 @@ func (y *YGGProvider) cacheHash(cacheKey, hash string) {
 }
 
 // fetchTorrentHash makes HTTP request to get torrent hash.
-func (y *YGGProvider) fetchTorrentHash(torrentID string) (string, error) {
-	apiURL := fmt.Sprintf("%s%s/%s", yggAPIBase, yggTorrentEndpoint, torrentID)
+func (y *YGGProvider) fetchTorrentHash(ctx context.Context, torrentID string) (string, error) {
+	apiURL := fmt.Sprintf("%s%s/%s", y.baseURL, yggTorrentEndpoint, torrentID)
 	// Debug log the hash fetch URL (will be captured by parent handler)
 	
-	resp, err := y.httpClient.Get(apiURL)
+	resp, err := httpGet(ctx, y.httpClient, apiURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to get YGG hash: %w", err)
 	}
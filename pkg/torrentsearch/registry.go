@@ -0,0 +1,189 @@
+package torrentsearch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/providers"
+)
+
+// LanguageFrench marks providers searched with the French title. They are skipped
+// for English-original content, like YGG.
+const LanguageFrench = "fr"
+
+// ProviderConfig describes a torrent provider instance, typically loaded from a config file.
+// Zero values select the defaults of the provider type.
+type ProviderConfig struct {
+	Name       string              `json:"name"`                 // Registration name, also used as torrent source
+	Type       string              `json:"type,omitempty"`       // ygg, apibay, torrentscsv or torznab; defaults to Name
+	Enabled    *bool               `json:"enabled,omitempty"`    // nil means enabled
+	BaseURL    string              `json:"base_url,omitempty"`   // API base URL, e.g. a mirror
+	APIKey     string              `json:"api_key,omitempty"`    // Torznab API key
+	Timeout    string              `json:"timeout,omitempty"`    // Go duration, e.g. "15s"
+	Categories map[string][]string `json:"categories,omitempty"` // Category IDs per media type
+	Language   string              `json:"language,omitempty"`   // "fr" to search with the French title
+	Weight     float64             `json:"weight,omitempty"`     // Ranking multiplier for this provider's torrents, 0 means 1
+}
+
+// IsEnabled reports whether the provider should be registered.
+func (c ProviderConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// providerType returns the provider implementation to use.
+func (c ProviderConfig) providerType() string {
+	if c.Type != "" {
+		return strings.ToLower(c.Type)
+	}
+	return strings.ToLower(c.Name)
+}
+
+// providerSettings holds the routing and ranking settings of a registered provider.
+type providerSettings struct {
+	language string
+	weight   float64
+}
+
+// settings returns the routing and ranking settings of the configured provider.
+func (c ProviderConfig) settings() providerSettings {
+	weight := c.Weight
+	if weight <= 0 {
+		weight = 1
+	}
+	return providerSettings{language: strings.ToLower(c.Language), weight: weight}
+}
+
+// DefaultProviderConfigs returns the built-in providers: YGG for French releases,
+// TorrentsCSV and ApiBay for international ones.
+func DefaultProviderConfigs() []ProviderConfig {
+	return []ProviderConfig{
+		{Name: providers.ProviderYGG, Language: LanguageFrench},
+		{Name: providers.ProviderTorrentsCSV},
+		{Name: providers.ProviderApiBay},
+	}
+}
+
+// MergeProviderConfigs applies overrides to base by provider name. Non-zero override fields
+// replace the base values; overrides for unknown names are appended.
+func MergeProviderConfigs(base, overrides []ProviderConfig) []ProviderConfig {
+	merged := append([]ProviderConfig{}, base...)
+	index := make(map[string]int, len(merged))
+	for i, cfg := range merged {
+		index[strings.ToLower(cfg.Name)] = i
+	}
+
+	for _, override := range overrides {
+		i, exists := index[strings.ToLower(override.Name)]
+		if !exists {
+			index[strings.ToLower(override.Name)] = len(merged)
+			merged = append(merged, override)
+			continue
+		}
+		merged[i] = merged[i].mergedWith(override)
+	}
+
+	return merged
+}
+
+// mergedWith returns c with the non-zero fields of override applied.
+func (c ProviderConfig) mergedWith(override ProviderConfig) ProviderConfig {
+	if override.Type != "" {
+		c.Type = override.Type
+	}
+	if override.Enabled != nil {
+		c.Enabled = override.Enabled
+	}
+	if override.BaseURL != "" {
+		c.BaseURL = override.BaseURL
+	}
+	if override.APIKey != "" {
+		c.APIKey = override.APIKey
+	}
+	if override.Timeout != "" {
+		c.Timeout = override.Timeout
+	}
+	if len(override.Categories) > 0 {
+		c.Categories = override.Categories
+	}
+	if override.Language != "" {
+		c.Language = override.Language
+	}
+	if override.Weight != 0 {
+		c.Weight = override.Weight
+	}
+	return c
+}
+
+// NewProvider creates the provider described by cfg.
+func NewProvider(cfg ProviderConfig) (TorrentProvider, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("provider name is required")
+	}
+
+	opts := providers.Options{
+		BaseURL:    cfg.BaseURL,
+		Categories: cfg.Categories,
+		APIKey:     cfg.APIKey,
+	}
+	if cfg.Timeout != "" {
+		timeout, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: invalid timeout %q: %w", cfg.Name, cfg.Timeout, err)
+		}
+		opts.Timeout = timeout
+	}
+
+	switch cfg.providerType() {
+	case providers.ProviderYGG:
+		return providers.NewYGGProviderWithOptions(opts), nil
+	case providers.ProviderApiBay:
+		return providers.NewApiBayProviderWithOptions(opts), nil
+	case providers.ProviderTorrentsCSV:
+		return providers.NewTorrentsCSVProviderWithOptions(opts), nil
+	case providers.ProviderTorznab:
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("provider %s: base_url is required for torznab", cfg.Name)
+		}
+		return providers.NewTorznabProviderWithOptions(cfg.Name, opts), nil
+	default:
+		return nil, fmt.Errorf("provider %s: unknown type %q", cfg.Name, cfg.providerType())
+	}
+}
+
+// RegisterProviders creates and registers every enabled provider in configs.
+// Disabled providers are removed if they were registered before.
+func (ts *TorrentSearch) RegisterProviders(configs []ProviderConfig) error {
+	for _, cfg := range configs {
+		if !cfg.IsEnabled() {
+			ts.UnregisterProvider(cfg.Name)
+			continue
+		}
+
+		provider, err := NewProvider(cfg)
+		if err != nil {
+			return err
+		}
+		ts.registerProvider(cfg.Name, provider, cfg.settings())
+	}
+	return nil
+}
+
+// UnregisterProvider removes a provider from the search engine.
+func (ts *TorrentSearch) UnregisterProvider(name string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	delete(ts.providers, name)
+	delete(ts.settings, name)
+}
+
+// ProviderNames returns the names of the registered providers.
+func (ts *TorrentSearch) ProviderNames() []string {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	names := make([]string, 0, len(ts.providers))
+	for name := range ts.providers {
+		names = append(names, name)
+	}
+	return names
+}
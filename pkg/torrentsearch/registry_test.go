@@ -0,0 +1,56 @@
+package torrentsearch
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/models"
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/providers"
+)
+
+func TestRegisterProvidersFromConfig(t *testing.T) {
+	disabled := false
+	configs := MergeProviderConfigs(DefaultProviderConfigs(), []ProviderConfig{
+		{Name: providers.ProviderApiBay, Enabled: &disabled},
+		{Name: providers.ProviderYGG, BaseURL: "https://ygg.mirror.example/", Timeout: "5s"},
+		{Name: "jackett", Type: providers.ProviderTorznab, BaseURL: "http://jackett:9117/torznab", Language: LanguageFrench, Weight: 2},
+	})
+
+	ts := New(nil)
+	ts.RegisterProvider(providers.ProviderApiBay, providers.NewApiBayProvider())
+	if err := ts.RegisterProviders(configs); err != nil {
+		t.Fatalf("RegisterProviders returned error: %v", err)
+	}
+
+	names := ts.ProviderNames()
+	sort.Strings(names)
+	if got := strings.Join(names, ","); got != "jackett,torrentscsv,ygg" {
+		t.Fatalf("unexpected providers: %s", got)
+	}
+
+	ygg, _ := ts.getProvider(providers.ProviderYGG)
+	if url := ygg.SearchURL(models.SearchOptions{Query: "Film", MediaType: "movie"}); !strings.HasPrefix(url, "https://ygg.mirror.example/torrents?") {
+		t.Errorf("YGG should use the mirror, got %s", url)
+	}
+
+	_, settings := ts.snapshotProviders()
+	if settings["ygg"].language != LanguageFrench || settings["jackett"].language != LanguageFrench || settings["torrentscsv"].language != "" {
+		t.Errorf("unexpected language routing: %+v", settings)
+	}
+	if settings["jackett"].weight != 2 || settings["ygg"].weight != 1 {
+		t.Errorf("unexpected weights: %+v", settings)
+	}
+}
+
+func TestNewProviderRejectsInvalidConfig(t *testing.T) {
+	for _, cfg := range []ProviderConfig{
+		{Name: "unknown"},
+		{Name: "jackett", Type: providers.ProviderTorznab},
+		{Name: providers.ProviderYGG, Timeout: "soon"},
+	} {
+		if _, err := NewProvider(cfg); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+}
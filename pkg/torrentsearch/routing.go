@@ -0,0 +1,124 @@
+package torrentsearch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/translator"
+)
+
+// Title variants a routing rule can select.
+const (
+	TitleEnglish  = "english"  // English title from TMDB, falling back to the default title
+	TitleFrench   = "french"   // French title from TMDB; the provider is skipped when there is none
+	TitleOriginal = "original" // Title in the original language, e.g. Japanese for anime
+	TitleSkip     = "skip"     // Do not search the provider
+)
+
+// RoutingRule selects the title variant used to query a provider. Empty match fields
+// match anything; rules are evaluated in order and the first match wins.
+type RoutingRule struct {
+	Languages        []string `json:"languages,omitempty"`         // TMDB original languages, e.g. ["en"] or ["ja", "ko"]
+	MediaType        string   `json:"media_type,omitempty"`        // movie or series
+	Provider         string   `json:"provider,omitempty"`          // Provider registration name, e.g. ygg
+	ProviderLanguage string   `json:"provider_language,omitempty"` // Language of the provider configuration, e.g. fr
+	Title            string   `json:"title"`                       // english, french, original or skip
+}
+
+// DefaultRoutingRules returns the built-in routing: French providers are skipped for
+// English-original content and searched with the French title otherwise; every other
+// provider is searched with the English title.
+func DefaultRoutingRules() []RoutingRule {
+	return []RoutingRule{
+		{Languages: []string{"en"}, ProviderLanguage: LanguageFrench, Title: TitleSkip},
+		{ProviderLanguage: LanguageFrench, Title: TitleFrench},
+		{Title: TitleEnglish},
+	}
+}
+
+// Validate reports whether the rule selects a known title variant.
+func (r RoutingRule) Validate() error {
+	switch strings.ToLower(r.Title) {
+	case TitleEnglish, TitleFrench, TitleOriginal, TitleSkip:
+		return nil
+	default:
+		return fmt.Errorf("routing rule: unknown title %q", r.Title)
+	}
+}
+
+// matches reports whether the rule applies to the given content and provider.
+func (r RoutingRule) matches(language, mediaType, provider string, settings providerSettings) bool {
+	if len(r.Languages) > 0 && !containsFold(r.Languages, language) {
+		return false
+	}
+	if r.MediaType != "" && normalizeMediaType(r.MediaType) != normalizeMediaType(mediaType) {
+		return false
+	}
+	if r.Provider != "" && !strings.EqualFold(r.Provider, provider) {
+		return false
+	}
+	if r.ProviderLanguage != "" && !strings.EqualFold(r.ProviderLanguage, settings.language) {
+		return false
+	}
+	return true
+}
+
+// SetRoutingRules replaces the language routing rules used by SearchSmart.
+// Content matching no rule is searched with the English title.
+func (ts *TorrentSearch) SetRoutingRules(rules []RoutingRule) error {
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.rules = append([]RoutingRule{}, rules...)
+	return nil
+}
+
+// routeTitle returns the title variant and query to use for a provider.
+// An empty query means the provider is not searched.
+func routeTitle(rules []RoutingRule, metadata *translator.ContentMetadata, mediaType, provider string, settings providerSettings) (string, string) {
+	variant := TitleEnglish
+	for _, rule := range rules {
+		if rule.matches(metadata.OriginalLanguage, mediaType, provider, settings) {
+			variant = strings.ToLower(rule.Title)
+			break
+		}
+	}
+
+	switch variant {
+	case TitleFrench:
+		return variant, metadata.FrenchTitle
+	case TitleOriginal:
+		if metadata.OriginalTitle != "" {
+			return variant, metadata.OriginalTitle
+		}
+		return TitleEnglish, metadata.EnglishTitle
+	case TitleSkip:
+		return variant, ""
+	default:
+		return TitleEnglish, metadata.EnglishTitle
+	}
+}
+
+// normalizeMediaType maps the TMDB "tv" media type to "series".
+func normalizeMediaType(mediaType string) string {
+	mediaType = strings.ToLower(mediaType)
+	if mediaType == "tv" {
+		return "series"
+	}
+	return mediaType
+}
+
+// containsFold reports whether values contains s, ignoring case.
+func containsFold(values []string, s string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package torrentsearch
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/translator"
+)
+
+// routingFixture holds custom rules and the expected query for each content/provider pair.
+type routingFixture struct {
+	Rules    []RoutingRule                         `json:"rules"`
+	Metadata map[string]translator.ContentMetadata `json:"metadata"`
+	Cases    []struct {
+		Metadata         string `json:"metadata"`
+		MediaType        string `json:"media_type"`
+		Provider         string `json:"provider"`
+		ProviderLanguage string `json:"provider_language"`
+		Title            string `json:"title"`
+	} `json:"cases"`
+}
+
+func TestRouteTitleFixtures(t *testing.T) {
+	data, err := os.ReadFile("testdata/routing_rules.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixture routingFixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := New(nil)
+	if err := ts.SetRoutingRules(append(fixture.Rules, DefaultRoutingRules()...)); err != nil {
+		t.Fatalf("SetRoutingRules returned error: %v", err)
+	}
+	rules := ts.routingRules()
+
+	for _, c := range fixture.Cases {
+		metadata := fixture.Metadata[c.Metadata]
+		settings := providerSettings{language: c.ProviderLanguage, weight: 1}
+		if _, title := routeTitle(rules, &metadata, c.MediaType, c.Provider, settings); title != c.Title {
+			t.Errorf("%s %s on %s: expected %q, got %q", c.Metadata, c.MediaType, c.Provider, c.Title, title)
+		}
+	}
+}
+
+func TestSetRoutingRulesRejectsUnknownTitle(t *testing.T) {
+	ts := New(nil)
+	if err := ts.SetRoutingRules([]RoutingRule{{Title: "german"}}); err == nil {
+		t.Fatal("expected error for unknown title variant")
+	}
+	if len(ts.routingRules()) != len(DefaultRoutingRules()) {
+		t.Error("invalid rules should leave the current rules in place")
+	}
+}
@@ -0,0 +1,221 @@
+// Package scoring ranks torrents by combining release quality, swarm health and size
+// into a single weighted score.
+package scoring
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	bytesPerGB = 1024 * 1024 * 1024
+	// maxSizeGB is the size at which the size component is maxed out
+	maxSizeGB = 60.0
+	// maxSeedersLog10 is log10 of the seeder count at which the seeders component is maxed out
+	maxSeedersLog10 = 3.0
+)
+
+// Weights sets how much each component contributes to the score. Components are normalised
+// to 0-1 before weighting, so a weight is the number of points a perfect match earns.
+type Weights struct {
+	Confidence float64 `json:"confidence"` // torrentname parser confidence
+	Resolution float64 `json:"resolution"` // 2160p > 1080p > 720p > SD
+	Source     float64 `json:"source"`     // REMUX > BluRay > WEB-DL > WEBRip > HDTV > DVD
+	Codec      float64 `json:"codec"`      // AV1 > HEVC > H.264
+	HDR        float64 `json:"hdr"`        // Dolby Vision > HDR10+ > HDR
+	Audio      float64 `json:"audio"`      // Atmos > TrueHD > DTS-HD > DTS > DD+ > AC3
+	French     float64 `json:"french"`     // VFF > MULTI > VFQ > FRENCH > VOSTFR
+	Seeders    float64 `json:"seeders"`    // Logarithmic, maxed out at 1000 seeders
+	Size       float64 `json:"size"`       // Linear, maxed out at 60 GB
+	Dead       float64 `json:"dead"`       // Penalty for torrents without seeders
+
+	// MinConfidence drops torrents below this parser confidence (0-100), unless none reach it
+	MinConfidence float64 `json:"min_confidence"`
+}
+
+// DefaultWeights favours resolution and source quality, then French audio, swarm health and size.
+func DefaultWeights() Weights {
+	return Weights{
+		Confidence:    15,
+		Resolution:    25,
+		Source:        15,
+		Codec:         5,
+		HDR:           5,
+		Audio:         5,
+		French:        10,
+		Seeders:       10,
+		Size:          10,
+		Dead:          40,
+		MinConfidence: 75,
+	}
+}
+
+// Torrent is the information the engine scores.
+type Torrent struct {
+	Title      string
+	Size       int64   // Size in bytes
+	Seeders    int     // Seeders reported by the provider
+	Confidence float64 // torrentname confidence, 0-100
+	Weight     float64 // Ranking weight of the source provider, 0 means 1
+}
+
+// Breakdown holds the weighted contribution of each component to a torrent's score.
+type Breakdown struct {
+	Confidence     float64
+	Resolution     float64
+	Source         float64
+	Codec          float64
+	HDR            float64
+	Audio          float64
+	French         float64
+	Seeders        float64
+	Size           float64
+	Dead           float64 // Penalty, subtracted from the total
+	ProviderWeight float64 // Multiplier applied to the sum of the components
+	Total          float64
+}
+
+// String formats the breakdown for debug logs.
+func (b Breakdown) String() string {
+	return fmt.Sprintf("score %.1f = (confidence %.1f + resolution %.1f + source %.1f + codec %.1f + hdr %.1f + audio %.1f + french %.1f + seeders %.1f + size %.1f) x %.2g - dead %.1f",
+		b.Total, b.Confidence, b.Resolution, b.Source, b.Codec, b.HDR, b.Audio, b.French, b.Seeders, b.Size, b.ProviderWeight, b.Dead)
+}
+
+// tier maps a release tag pattern to its normalised value.
+type tier struct {
+	pattern *regexp.Regexp
+	value   float64
+}
+
+// newTier matches pattern as a whole release tag, e.g. "WEB" in "Film.2020.WEB.x264" but not in "WEBRip".
+// Audio channels may follow the tag, as in "DDP5.1".
+func newTier(pattern string, value float64) tier {
+	return tier{
+		pattern: regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(?:` + pattern + `)(?:\d\.\d)?(?:[^a-z0-9]|$)`),
+		value:   value,
+	}
+}
+
+// Release tags from best to worst; the first match wins.
+var (
+	resolutionTiers = []tier{
+		newTier(`2160p|4k|uhd`, 1.0),
+		newTier(`1080p|1080i`, 0.75),
+		newTier(`720p`, 0.5),
+		newTier(`576p|480p|sd`, 0.25),
+	}
+	sourceTiers = []tier{
+		newTier(`remux|bdremux`, 1.0),
+		newTier(`blu-?ray|bd`, 0.8),
+		newTier(`web-?dl`, 0.7),
+		newTier(`web-?rip|web`, 0.55),
+		newTier(`bdrip|brrip`, 0.5),
+		newTier(`hdtv|hdlight|4klight`, 0.4),
+		newTier(`dvdrip|dvd`, 0.2),
+	}
+	codecTiers = []tier{
+		newTier(`av1`, 1.0),
+		newTier(`x265|h\.?265|hevc`, 0.8),
+		newTier(`x264|h\.?264|avc`, 0.5),
+		newTier(`xvid|divx`, 0.1),
+	}
+	hdrTiers = []tier{
+		newTier(`dv|dovi|dolby\.?vision`, 1.0),
+		newTier(`hdr10\+|hdr10plus`, 0.9),
+		newTier(`hdr10|hdr`, 0.8),
+	}
+	audioTiers = []tier{
+		newTier(`atmos`, 1.0),
+		newTier(`truehd`, 0.9),
+		newTier(`dts-?hd|dts-?x|dts-?ma`, 0.8),
+		newTier(`dts`, 0.6),
+		newTier(`ddp|dd\+|e-?ac-?3`, 0.5),
+		newTier(`ac-?3|dd`, 0.3),
+		newTier(`aac|mp3`, 0.2),
+	}
+	frenchTiers = []tier{
+		newTier(`vff|truefrench`, 1.0),
+		newTier(`multi`, 0.9),
+		newTier(`vfq|vf2|vfi`, 0.8),
+		newTier(`vf|french`, 0.7),
+		newTier(`vostfr|subfrench`, 0.2),
+	}
+)
+
+// Engine scores torrents with a fixed set of weights. It is safe for concurrent use.
+type Engine struct {
+	weights Weights
+}
+
+// New creates an engine using the given weights.
+func New(weights Weights) *Engine {
+	return &Engine{weights: weights}
+}
+
+// Weights returns the weights used by the engine.
+func (e *Engine) Weights() Weights {
+	return e.weights
+}
+
+// Score computes the score of a torrent and the contribution of each component.
+func (e *Engine) Score(t Torrent) Breakdown {
+	w := e.weights
+	title := strings.ReplaceAll(t.Title, "_", ".")
+
+	b := Breakdown{
+		Confidence:     w.Confidence * math.Min(math.Max(t.Confidence, 0)/100, 1),
+		Resolution:     w.Resolution * matchTier(resolutionTiers, title),
+		Source:         w.Source * matchTier(sourceTiers, title),
+		Codec:          w.Codec * matchTier(codecTiers, title),
+		HDR:            w.HDR * matchTier(hdrTiers, title),
+		Audio:          w.Audio * matchTier(audioTiers, title),
+		French:         w.French * matchTier(frenchTiers, title),
+		Seeders:        w.Seeders * math.Min(math.Log10(float64(t.Seeders)+1)/maxSeedersLog10, 1),
+		Size:           w.Size * math.Min(float64(t.Size)/bytesPerGB/maxSizeGB, 1),
+		ProviderWeight: t.Weight,
+	}
+	if t.Seeders <= 0 {
+		b.Dead = w.Dead
+	}
+	if b.ProviderWeight <= 0 {
+		b.ProviderWeight = 1
+	}
+
+	sum := b.Confidence + b.Resolution + b.Source + b.Codec + b.HDR + b.Audio + b.French + b.Seeders + b.Size
+	b.Total = sum*b.ProviderWeight - b.Dead
+	return b
+}
+
+// Sort orders items by descending score, keeping the original order for equal scores.
+func Sort[T any](e *Engine, items []T, torrent func(T) Torrent) {
+	type scored struct {
+		item  T
+		score float64
+	}
+
+	scoredItems := make([]scored, len(items))
+	for i, item := range items {
+		scoredItems[i] = scored{item: item, score: e.Score(torrent(item)).Total}
+	}
+
+	sort.SliceStable(scoredItems, func(i, j int) bool {
+		return scoredItems[i].score > scoredItems[j].score
+	})
+
+	for i, s := range scoredItems {
+		items[i] = s.item
+	}
+}
+
+// matchTier returns the value of the best tier matching the title, or 0.
+func matchTier(tiers []tier, title string) float64 {
+	for _, t := range tiers {
+		if t.pattern.MatchString(title) {
+			return t.value
+		}
+	}
+	return 0
+}
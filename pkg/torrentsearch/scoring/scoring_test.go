@@ -0,0 +1,71 @@
+package scoring
+
+import (
+	"strings"
+	"testing"
+)
+
+const gigabyte = 1024 * 1024 * 1024
+
+func TestScoreComponents(t *testing.T) {
+	engine := New(DefaultWeights())
+	weights := engine.Weights()
+
+	b := engine.Score(Torrent{
+		Title:      "Film.2020.MULTi.VFF.2160p.BluRay.REMUX.HEVC.DV.HDR10.TrueHD.Atmos.7.1-GRP",
+		Size:       60 * gigabyte,
+		Seeders:    999,
+		Confidence: 100,
+	})
+	for name, got := range map[string][2]float64{
+		"confidence": {b.Confidence, weights.Confidence},
+		"resolution": {b.Resolution, weights.Resolution},
+		"source":     {b.Source, weights.Source},
+		"codec":      {b.Codec, weights.Codec * 0.8},
+		"hdr":        {b.HDR, weights.HDR},
+		"audio":      {b.Audio, weights.Audio},
+		"french":     {b.French, weights.French},
+		"seeders":    {b.Seeders, weights.Seeders},
+		"size":       {b.Size, weights.Size},
+	} {
+		if diff := got[0] - got[1]; diff > 0.01 || diff < -0.01 {
+			t.Errorf("%s: expected %.2f, got %.2f", name, got[1], got[0])
+		}
+	}
+	if b.Dead != 0 || !strings.HasPrefix(b.String(), "score ") {
+		t.Errorf("unexpected breakdown: %s", b)
+	}
+
+	dead := engine.Score(Torrent{Title: "Film.2020.FRENCH.720p.WEB.H264-GRP", Size: 2 * gigabyte})
+	if dead.Dead != weights.Dead || dead.Total >= 0 {
+		t.Errorf("expected dead torrent penalty, got %s", dead)
+	}
+	if dead.Source != weights.Source*0.55 || dead.Audio != 0 {
+		t.Errorf("WEB should not match WEB-DL and no audio tag is present: %s", dead)
+	}
+}
+
+func TestSortOrdersByScore(t *testing.T) {
+	torrents := []Torrent{
+		{Title: "Film.2020.FRENCH.720p.HDTV.x264", Size: 1 * gigabyte, Seeders: 500, Confidence: 90},
+		{Title: "Film.2020.MULTi.1080p.WEB-DL.DDP5.1.x265", Size: 8 * gigabyte, Seeders: 0, Confidence: 90},
+		{Title: "Film.2020.MULTi.2160p.WEB-DL.DDP5.1.HDR.x265", Size: 20 * gigabyte, Seeders: 40, Confidence: 90},
+		{Title: "Film.2020.MULTi.1080p.WEB-DL.DDP5.1.x265", Size: 8 * gigabyte, Seeders: 40, Confidence: 90, Weight: 2},
+	}
+
+	Sort(New(DefaultWeights()), torrents, func(t Torrent) Torrent { return t })
+
+	if torrents[0].Weight != 2 || !strings.Contains(torrents[1].Title, "2160p") || torrents[3].Seeders != 0 {
+		t.Errorf("unexpected order: %+v", torrents)
+	}
+}
+
+func TestCustomWeights(t *testing.T) {
+	// Size only: the largest torrent wins regardless of tags
+	engine := New(Weights{Size: 1})
+	small := engine.Score(Torrent{Title: "Film.2160p.REMUX", Size: gigabyte, Seeders: 10})
+	large := engine.Score(Torrent{Title: "Film.480p", Size: 10 * gigabyte, Seeders: 10})
+	if small.Total >= large.Total {
+		t.Errorf("expected size-only weights to prefer the larger torrent: %s vs %s", small, large)
+	}
+}
@@ -3,23 +3,29 @@
 package torrentsearch
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/amaumene/gostremiofr/pkg/torrentsearch/models"
 	"github.com/amaumene/gostremiofr/pkg/torrentsearch/providers"
 	"github.com/amaumene/gostremiofr/pkg/torrentsearch/sorter"
 	"github.com/amaumene/gostremiofr/pkg/torrentsearch/translator"
-	"github.com/amaumene/gostremiofr/pkg/torrentsearch/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TorrentProvider defines the interface for torrent search providers.
 type TorrentProvider interface {
-	Search(options models.SearchOptions) (*models.SearchResults, error)
-	GetTorrentHash(torrentID string) (string, error)
+	Search(ctx context.Context, options models.SearchOptions) (*models.SearchResults, error)
+	GetTorrentHash(ctx context.Context, torrentID string) (string, error)
 	SetCache(cache interface{})
+	// SearchURL returns the URL queried for the given options, for diagnostics
+	SearchURL(options models.SearchOptions) string
 }
 
 // Cache defines the interface for caching search results.
@@ -28,20 +34,71 @@ type Cache interface {
 	Set(key string, value interface{})
 }
 
+// metadataSource looks up the original language and localized titles of the searched content.
+type metadataSource interface {
+	FetchMetadata(ctx context.Context, query string, mediaType string) (*translator.ContentMetadata, error)
+	FetchMetadataByIMDBID(ctx context.Context, imdbID string, mediaType string) (*translator.ContentMetadata, error)
+}
+
 // TorrentSearch orchestrates search across multiple torrent providers.
+// It is safe for concurrent use; per-search state lives in the returned SearchResult.
 type TorrentSearch struct {
-	providers       map[string]TorrentProvider
-	metadataFetcher *translator.MetadataFetcher
-	sorter          *sorter.TorrentSorter
-	cache           Cache
-	tmdbAPIKey      string
-	providerErrors  map[string]error
-	providerURLs    map[string]string
+	mu                sync.RWMutex
+	providers         map[string]TorrentProvider
+	settings          map[string]providerSettings
+	rules             []RoutingRule
+	sorter            *sorter.TorrentSorter
+	cache             Cache
+	newMetadataSource func(apiKey string) metadataSource
+	tracer            trace.Tracer // Global tracer provider's tracer; spans are dropped until the application installs one
+}
+
+// SearchRequest describes a single smart search. It carries the caller's TMDB API key
+// so that concurrent searches for different users never share credentials.
+type SearchRequest struct {
+	TMDBAPIKey      string
+	Query           string
+	MediaType       string
+	Season          int
+	Episode         int
+	SpecificEpisode bool
+	CompleteSeries  bool   // Search packs of every season, with "integrale" or "complete" instead of the season
+	AbsoluteEpisode int    // Episode number of anime releases ("[Group] Title - 1043"), 0 for other content
+	Logger          Logger // Receives provider requests and errors, nil to discard them
+}
+
+// Logger receives the logs of a search. Callers typically pass a logger tagged with the
+// ID of the request being served so provider calls can be traced.
+type Logger interface {
+	Debugf(format string, v ...interface{})
+	Warnf(format string, v ...interface{})
+}
+
+// nopLogger discards the logs of searches without logger
+type nopLogger struct{}
+
+func (nopLogger) Debugf(string, ...interface{}) {}
+func (nopLogger) Warnf(string, ...interface{})  {}
+
+// SearchResult is the outcome of a single SearchSmart call.
+type SearchResult struct {
+	Results     *models.CombinedSearchResults
+	Metadata    *SearchMetadata                // nil when the TMDB lookup failed
+	Diagnostics map[string]ProviderDiagnostics // Provider name -> diagnostics
+}
+
+// ProviderDiagnostics describes how a provider behaved during one search.
+type ProviderDiagnostics struct {
+	URL      string        // API URL queried (for debugging)
+	Err      error         // Search error, nil on success
+	Duration time.Duration // Time spent waiting for the provider
+	Count    int           // Number of torrents returned
 }
 
 // SearchMetadata contains metadata about the searched content.
 type SearchMetadata struct {
 	OriginalLanguage string
+	OriginalTitle    string
 	EnglishTitle     string
 	FrenchTitle      string
 	Year             int
@@ -49,67 +106,137 @@ type SearchMetadata struct {
 
 // New creates a new TorrentSearch instance with the given cache.
 func New(cache Cache) *TorrentSearch {
-	return &TorrentSearch{
-		providers:      make(map[string]TorrentProvider),
-		sorter:         sorter.NewTorrentSorter(),
-		cache:          cache,
-		providerErrors: make(map[string]error),
-		providerURLs:   make(map[string]string),
+	ts := &TorrentSearch{
+		providers: make(map[string]TorrentProvider),
+		settings:  make(map[string]providerSettings),
+		rules:     DefaultRoutingRules(),
+		sorter:    sorter.NewTorrentSorter(),
+		cache:     cache,
+		tracer:    otel.Tracer(tracerName),
 	}
+	ts.newMetadataSource = func(apiKey string) metadataSource {
+		return translator.NewMetadataFetcher(apiKey, ts.cache)
+	}
+	return ts
 }
 
-// RegisterProvider adds a new torrent provider to the search engine.
+// RegisterProvider adds a new torrent provider to the search engine with default settings:
+// YGG is a French provider, every other provider is international.
+// Use RegisterProviders to configure language routing and weight.
 func (ts *TorrentSearch) RegisterProvider(name string, provider TorrentProvider) {
+	settings := providerSettings{weight: 1}
+	if name == providers.ProviderYGG {
+		settings.language = LanguageFrench
+	}
+	ts.registerProvider(name, provider, settings)
+}
+
+// registerProvider adds or replaces a provider and its settings.
+func (ts *TorrentSearch) registerProvider(name string, provider TorrentProvider, settings providerSettings) {
 	if ts.cache != nil {
 		provider.SetCache(ts.cache)
 	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
 	ts.providers[name] = provider
+	ts.settings[name] = settings
 }
 
-// SetTMDBAPIKey configures the TMDB API key for metadata fetching.
-func (ts *TorrentSearch) SetTMDBAPIKey(apiKey string) {
-	ts.tmdbAPIKey = apiKey
-	ts.metadataFetcher = translator.NewMetadataFetcher(apiKey, ts.cache)
+// getProvider returns a registered provider by name.
+func (ts *TorrentSearch) getProvider(name string) (TorrentProvider, bool) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	provider, exists := ts.providers[name]
+	return provider, exists
 }
 
-// SearchSmart performs intelligent routing based on content's original language.
-func (ts *TorrentSearch) SearchSmart(query string, mediaType string, season, episode int, specificEpisode bool) (*models.CombinedSearchResults, *SearchMetadata, error) {
-	if ts.metadataFetcher == nil {
-		return nil, nil, fmt.Errorf("TMDB API key not configured")
-	}
+// routingRules returns the routing rules for one search.
+func (ts *TorrentSearch) routingRules() []RoutingRule {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.rules
+}
 
-	if ts.isIMDBID(query) {
-		return ts.searchSmartByIMDBID(query, mediaType, season, episode, specificEpisode)
+// snapshotProviders returns a copy of the registered providers and their settings for one search.
+func (ts *TorrentSearch) snapshotProviders() (map[string]TorrentProvider, map[string]providerSettings) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	snapshot := make(map[string]TorrentProvider, len(ts.providers))
+	settings := make(map[string]providerSettings, len(ts.settings))
+	for name, provider := range ts.providers {
+		snapshot[name] = provider
+		settings[name] = ts.settings[name]
 	}
+	return snapshot, settings
+}
 
-	metadata, err := ts.metadataFetcher.FetchMetadata(query, mediaType)
-	if err != nil {
-		results, fallbackErr := ts.searchWithoutMetadata(query, mediaType, season, episode, specificEpisode)
-		return results, nil, fallbackErr
+// SearchSmart routes the query to each provider using the routing rules, based on the
+// content's original language and media type.
+// Provider errors do not fail the search; they are reported in the result diagnostics.
+// Cancelling ctx aborts the metadata lookup and every in-flight provider request.
+// Each search is traced with a SearchSmart span, parent of the metadata lookup and provider spans.
+func (ts *TorrentSearch) SearchSmart(ctx context.Context, req SearchRequest) (*SearchResult, error) {
+	ctx, span := ts.tracer.Start(ctx, "torrentsearch.SearchSmart", trace.WithAttributes(
+		attribute.String("search.query", req.Query),
+		attribute.String("search.media_type", req.MediaType),
+		attribute.Int("search.season", req.Season),
+		attribute.Int("search.episode", req.Episode),
+	))
+	result, err := ts.searchSmart(ctx, req)
+	endSpan(span, err)
+	return result, err
+}
+
+// searchSmart runs a smart search within its span.
+func (ts *TorrentSearch) searchSmart(ctx context.Context, req SearchRequest) (*SearchResult, error) {
+	if req.TMDBAPIKey == "" {
+		return nil, fmt.Errorf("TMDB API key not configured")
 	}
 
-	searchMeta := ts.buildSearchMetadata(metadata)
-	combined := ts.searchWithMetadata(metadata, mediaType, season, episode, specificEpisode)
+	metadata, err := ts.fetchMetadata(ctx, req)
 
-	return combined, searchMeta, nil
-}
-
-// searchSmartByIMDBID performs intelligent routing using direct IMDB ID lookup.
-func (ts *TorrentSearch) searchSmartByIMDBID(imdbID string, mediaType string, season, episode int, specificEpisode bool) (*models.CombinedSearchResults, *SearchMetadata, error) {
-	if ts.metadataFetcher == nil {
-		return nil, nil, fmt.Errorf("TMDB API key not configured")
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
 	}
 
-	metadata, err := ts.metadataFetcher.FetchMetadataByIMDBID(imdbID, mediaType)
+	snapshot, settings := ts.snapshotProviders()
+	run := newSearchRun(ctx, snapshot, settings)
+	if req.Logger != nil {
+		run.log = req.Logger
+	}
+	run.rules = ts.routingRules()
 	if err != nil {
-		results, fallbackErr := ts.searchWithoutMetadata(imdbID, mediaType, season, episode, specificEpisode)
-		return results, nil, fallbackErr
+		if fallbackErr := ts.searchWithoutMetadata(run, ts.buildSearchOptions(req, nil)); fallbackErr != nil {
+			return nil, fallbackErr
+		}
+		return run.result(nil), nil
 	}
 
-	searchMeta := ts.buildSearchMetadata(metadata)
-	combined := ts.searchWithMetadata(metadata, mediaType, season, episode, specificEpisode)
+	ts.searchWithMetadata(run, metadata, ts.buildSearchOptions(req, metadata))
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
 
-	return combined, searchMeta, nil
+	return run.result(ts.buildSearchMetadata(metadata)), nil
+}
+
+// fetchMetadata looks up the TMDB metadata of the searched content.
+func (ts *TorrentSearch) fetchMetadata(ctx context.Context, req SearchRequest) (*translator.ContentMetadata, error) {
+	ctx, span := ts.tracer.Start(ctx, "torrentsearch.fetchMetadata")
+	source := ts.newMetadataSource(req.TMDBAPIKey)
+
+	var metadata *translator.ContentMetadata
+	var err error
+	if ts.isIMDBID(req.Query) {
+		metadata, err = source.FetchMetadataByIMDBID(ctx, req.Query, req.MediaType)
+	} else {
+		metadata, err = source.FetchMetadata(ctx, req.Query, req.MediaType)
+	}
+	if metadata != nil {
+		span.SetAttributes(attribute.String("content.original_language", metadata.OriginalLanguage))
+	}
+	endSpan(span, err)
+	return metadata, err
 }
 
 // isIMDBID checks if the query is an IMDB ID.
@@ -121,171 +248,114 @@ func (ts *TorrentSearch) isIMDBID(query string) bool {
 func (ts *TorrentSearch) buildSearchMetadata(metadata *translator.ContentMetadata) *SearchMetadata {
 	return &SearchMetadata{
 		OriginalLanguage: metadata.OriginalLanguage,
+		OriginalTitle:    metadata.OriginalTitle,
 		EnglishTitle:     metadata.EnglishTitle,
 		FrenchTitle:      metadata.FrenchTitle,
 		Year:             metadata.Year,
 	}
 }
 
-// searchWithMetadata performs search using metadata for intelligent routing.
-func (ts *TorrentSearch) searchWithMetadata(metadata *translator.ContentMetadata, mediaType string, season, episode int, specificEpisode bool) *models.CombinedSearchResults {
-	// Clear provider errors and URLs for new search
-	ts.providerErrors = nil
-	ts.providerURLs = make(map[string]string)
-	
-	combined := &models.CombinedSearchResults{
-		Results:   make(map[string]*models.SearchResults),
-		DebugInfo: make(map[string]string),
-	}
-
-	searchOptions := ts.buildSearchOptions(metadata, mediaType, season, episode, specificEpisode)
-
-	if metadata.OriginalLanguage == "en" {
-		ts.searchEnglishProviders(searchOptions, combined, metadata.EnglishTitle)
-	} else {
-		ts.searchNonEnglishProviders(searchOptions, combined, metadata)
-	}
-
-	return combined
-}
-
-// buildSearchOptions creates SearchOptions from metadata and parameters.
-func (ts *TorrentSearch) buildSearchOptions(metadata *translator.ContentMetadata, mediaType string, season, episode int, specificEpisode bool) models.SearchOptions {
-	return models.SearchOptions{
-		MediaType:       mediaType,
-		Season:          season,
-		Episode:         episode,
-		SpecificEpisode: specificEpisode,
-		Year:            metadata.Year,
-	}
-}
-
-// searchEnglishProviders searches all providers except YGG for English content.
-func (ts *TorrentSearch) searchEnglishProviders(options models.SearchOptions, combined *models.CombinedSearchResults, title string) {
-	options.Query = title
-	
-	// Search providers in parallel (excluding YGG)
+// searchWithMetadata searches every provider with the title variant selected by the routing rules.
+func (ts *TorrentSearch) searchWithMetadata(run *searchRun, metadata *translator.ContentMetadata, searchOptions models.SearchOptions) {
 	var wg sync.WaitGroup
-	var mu sync.Mutex
-	
-	for name, provider := range ts.providers {
-		if name == providers.ProviderYGG {
+	for name, provider := range run.providers {
+		variant, title := routeTitle(run.rules, metadata, searchOptions.MediaType, name, run.settings[name])
+		if title == "" {
 			continue
 		}
-		
+
+		options := searchOptions
+		options.Query = title
+		if variant == TitleFrench {
+			options.Language = LanguageFrench
+		}
+
 		wg.Add(1)
 		go func(n string, p TorrentProvider) {
 			defer wg.Done()
-			ts.searchProviderConcurrent(n, p, options, combined, &mu)
+			ts.searchProvider(run, n, p, options)
 		}(name, provider)
 	}
-	
+
 	wg.Wait()
 }
 
-// searchNonEnglishProviders searches YGG with French title and others with English title.
-func (ts *TorrentSearch) searchNonEnglishProviders(options models.SearchOptions, combined *models.CombinedSearchResults, metadata *translator.ContentMetadata) {
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	
-	// Search YGG with French title in parallel
-	if metadata.FrenchTitle != "" {
-		if yggProvider, exists := ts.providers[providers.ProviderYGG]; exists {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				frenchOptions := options
-				frenchOptions.Query = metadata.FrenchTitle
-				frenchOptions.Language = "fr"
-				ts.searchProviderConcurrent(providers.ProviderYGG, yggProvider, frenchOptions, combined, &mu)
-			}()
-		}
+// buildSearchOptions creates SearchOptions from the request and, when the lookup succeeded, metadata.
+func (ts *TorrentSearch) buildSearchOptions(req SearchRequest, metadata *translator.ContentMetadata) models.SearchOptions {
+	options := models.SearchOptions{
+		Query:           req.Query,
+		MediaType:       req.MediaType,
+		Season:          req.Season,
+		Episode:         req.Episode,
+		SpecificEpisode: req.SpecificEpisode,
+		CompleteSeries:  req.CompleteSeries,
+		AbsoluteEpisode: req.AbsoluteEpisode,
 	}
-	
-	// Search other providers with English title in parallel
-	englishOptions := options
-	englishOptions.Query = metadata.EnglishTitle
-	englishOptions.Language = ""
-	
-	for name, provider := range ts.providers {
-		if name == providers.ProviderYGG {
-			continue
-		}
-		
-		wg.Add(1)
-		go func(n string, p TorrentProvider) {
-			defer wg.Done()
-			ts.searchProviderConcurrent(n, p, englishOptions, combined, &mu)
-		}(name, provider)
+	if metadata != nil {
+		options.Year = metadata.Year
 	}
-	
-	wg.Wait()
+	return options
 }
 
-// searchProvider executes search for a single provider and adds results (sequential version).
-func (ts *TorrentSearch) searchProvider(name string, provider TorrentProvider, options models.SearchOptions, combined *models.CombinedSearchResults) {
-	// Build and store the API URL for debugging
-	apiURL := ts.buildProviderURL(name, options)
-	if ts.providerURLs == nil {
-		ts.providerURLs = make(map[string]string)
-	}
-	ts.providerURLs[name] = apiURL
-	combined.DebugInfo[name] = apiURL
-	
-	results, err := provider.Search(options)
+// searchProvider executes search for a single provider and records its results and diagnostics.
+// It is safe to call concurrently for the same run.
+func (ts *TorrentSearch) searchProvider(run *searchRun, name string, provider TorrentProvider, options models.SearchOptions) {
+	// Build the API URL for debugging
+	diagnostics := ProviderDiagnostics{URL: provider.SearchURL(options)}
+
+	ctx, span := ts.tracer.Start(run.ctx, "torrentsearch.searchProvider", trace.WithAttributes(
+		attribute.String("provider", name),
+		attribute.String("search.query", options.Query),
+	))
+	start := time.Now()
+	results, err := provider.Search(ctx, options)
+	diagnostics.Duration = time.Since(start)
+	defer func() {
+		span.SetAttributes(attribute.Int("search.results", diagnostics.Count))
+		endSpan(span, err)
+	}()
+
 	if err != nil {
-		// Store the error for debugging
-		if ts.providerErrors == nil {
-			ts.providerErrors = make(map[string]error)
-		}
-		ts.providerErrors[name] = err
-		
+		run.log.Warnf("[%s] search error after %s: %v", name, diagnostics.Duration, err)
+		diagnostics.Err = err
 		// Return empty results so the provider appears in the output
-		combined.Results[name] = &models.SearchResults{
+		results = &models.SearchResults{
 			MovieTorrents:          []models.TorrentInfo{},
 			CompleteSeriesTorrents: []models.TorrentInfo{},
 			CompleteSeasonTorrents: []models.TorrentInfo{},
 			EpisodeTorrents:        []models.TorrentInfo{},
 		}
-		return
+	} else {
+		ts.sorter.SortResults(results)
+		diagnostics.Count = countTorrents(results)
+		run.log.Debugf("[%s] API URL: %s (%d results in %s)", name, diagnostics.URL, diagnostics.Count, diagnostics.Duration)
 	}
 
-	ts.sorter.SortResults(results)
-	combined.Results[name] = results
+	run.record(name, results, diagnostics)
 }
 
-func (ts *TorrentSearch) searchWithoutMetadata(query string, mediaType string, season, episode int, specificEpisode bool) (*models.CombinedSearchResults, error) {
-	combined := &models.CombinedSearchResults{
-		Results:   make(map[string]*models.SearchResults),
-		DebugInfo: make(map[string]string),
-	}
-
-	searchOptions := models.SearchOptions{
-		Query:           query,
-		MediaType:       mediaType,
-		Season:          season,
-		Episode:         episode,
-		SpecificEpisode: specificEpisode,
-	}
-
+func (ts *TorrentSearch) searchWithoutMetadata(run *searchRun, searchOptions models.SearchOptions) error {
 	// Search all providers in parallel
-	ts.searchAllProvidersParallel(searchOptions, combined)
+	ts.searchAllProvidersParallel(run, searchOptions)
+	if err := run.ctx.Err(); err != nil {
+		return err
+	}
 
-	if len(combined.Results) == 0 {
-		return nil, fmt.Errorf("no results found from any provider")
+	if len(run.combined.Results) == 0 {
+		return fmt.Errorf("no results found from any provider")
 	}
 
-	return combined, nil
+	return nil
 }
 
 // Search searches a specific provider by name.
-func (ts *TorrentSearch) Search(providerName string, options models.SearchOptions) (*models.SearchResults, error) {
-	provider, exists := ts.providers[providerName]
+func (ts *TorrentSearch) Search(ctx context.Context, providerName string, options models.SearchOptions) (*models.SearchResults, error) {
+	provider, exists := ts.getProvider(providerName)
 	if !exists {
 		return nil, fmt.Errorf("provider %s not found", providerName)
 	}
 
-	results, err := provider.Search(options)
+	results, err := provider.Search(ctx, options)
 	if err != nil {
 		return nil, err
 	}
@@ -310,110 +380,82 @@ func (ts *TorrentSearch) GetDebugInfo(torrents []models.TorrentInfo) []string {
 	return debugInfo
 }
 
-
-
-
-
-
-
-
 // GetProviderHash fetches the torrent hash from a specific provider.
-func (ts *TorrentSearch) GetProviderHash(providerName string, torrentID string) (string, error) {
-	provider, exists := ts.providers[providerName]
+func (ts *TorrentSearch) GetProviderHash(ctx context.Context, providerName string, torrentID string) (string, error) {
+	provider, exists := ts.getProvider(providerName)
 	if !exists {
 		return "", fmt.Errorf("provider %s not found", providerName)
 	}
 	
-	return provider.GetTorrentHash(torrentID)
-}
-
-// GetProviderErrors returns any errors that occurred during the last search.
-func (ts *TorrentSearch) GetProviderErrors() map[string]error {
-	return ts.providerErrors
-}
-
-// GetProviderURLs returns the API URLs used in the last search.
-func (ts *TorrentSearch) GetProviderURLs() map[string]string {
-	return ts.providerURLs
-}
-
-// searchProviderConcurrent is a thread-safe version of searchProvider for parallel execution.
-func (ts *TorrentSearch) searchProviderConcurrent(name string, provider TorrentProvider, options models.SearchOptions, combined *models.CombinedSearchResults, mu *sync.Mutex) {
-	// Build and store the API URL for debugging
-	apiURL := ts.buildProviderURL(name, options)
-	
-	mu.Lock()
-	if ts.providerURLs == nil {
-		ts.providerURLs = make(map[string]string)
-	}
-	ts.providerURLs[name] = apiURL
-	combined.DebugInfo[name] = apiURL
-	mu.Unlock()
-	
-	results, err := provider.Search(options)
-	
-	mu.Lock()
-	defer mu.Unlock()
-	
-	if err != nil {
-		// Store the error for debugging
-		if ts.providerErrors == nil {
-			ts.providerErrors = make(map[string]error)
-		}
-		ts.providerErrors[name] = err
-		
-		// Return empty results so the provider appears in the output
-		combined.Results[name] = &models.SearchResults{
-			MovieTorrents:          []models.TorrentInfo{},
-			CompleteSeriesTorrents: []models.TorrentInfo{},
-			CompleteSeasonTorrents: []models.TorrentInfo{},
-			EpisodeTorrents:        []models.TorrentInfo{},
-		}
-		return
-	}
-	
-	ts.sorter.SortResults(results)
-	combined.Results[name] = results
+	return provider.GetTorrentHash(ctx, torrentID)
 }
 
 // searchAllProvidersParallel searches all providers in parallel.
-func (ts *TorrentSearch) searchAllProvidersParallel(options models.SearchOptions, combined *models.CombinedSearchResults) {
+func (ts *TorrentSearch) searchAllProvidersParallel(run *searchRun, options models.SearchOptions) {
 	var wg sync.WaitGroup
-	var mu sync.Mutex
 	
-	for name, provider := range ts.providers {
+	for name, provider := range run.providers {
 		wg.Add(1)
 		go func(n string, p TorrentProvider) {
 			defer wg.Done()
-			ts.searchProviderConcurrent(n, p, options, combined, &mu)
+			ts.searchProvider(run, n, p, options)
 		}(name, provider)
 	}
 	
 	wg.Wait()
 }
 
-// buildProviderURL builds the API URL for a provider (for debugging).
-func (ts *TorrentSearch) buildProviderURL(name string, options models.SearchOptions) string {
-	query := utils.BuildSearchQuery(options.Query, options.MediaType, options.Season, options.Episode, options.SpecificEpisode)
-	
-	// Query already has + for spaces from formatQueryString, no need to escape it
-	switch name {
-	case providers.ProviderYGG:
-		categories := ""
-		if options.MediaType == "movie" {
-			categories = "&category_id=2178&category_id=2181&category_id=2183"
-		} else if options.MediaType == "series" {
-			categories = "&category_id=2179&category_id=2181&category_id=2182&category_id=2184"
-		}
-		return fmt.Sprintf("https://yggapi.eu/torrents?q=%s&page=1&per_page=100%s", query, categories)
-		
-	case providers.ProviderApiBay:
-		return fmt.Sprintf("https://apibay.org/q.php?q=%s&cat=video", query)
-		
-	case providers.ProviderTorrentsCSV:
-		return fmt.Sprintf("https://torrents-csv.com/service/search?q=%s&size=100", query)
-		
-	default:
-		return fmt.Sprintf("unknown provider: %s", name)
+// searchRun collects the results of a single search. It is never shared between searches,
+// so concurrent callers cannot observe each other's errors or URLs.
+type searchRun struct {
+	ctx         context.Context
+	log         Logger
+	mu          sync.Mutex
+	providers   map[string]TorrentProvider
+	settings    map[string]providerSettings
+	rules       []RoutingRule
+	combined    *models.CombinedSearchResults
+	diagnostics map[string]ProviderDiagnostics
+}
+
+func newSearchRun(ctx context.Context, providers map[string]TorrentProvider, settings map[string]providerSettings) *searchRun {
+	return &searchRun{
+		ctx:       ctx,
+		log:       nopLogger{},
+		providers: providers,
+		settings:  settings,
+		combined: &models.CombinedSearchResults{
+			Results:   make(map[string]*models.SearchResults),
+			DebugInfo: make(map[string]string),
+			Weights:   make(map[string]float64),
+		},
+		diagnostics: make(map[string]ProviderDiagnostics),
 	}
-}
\ No newline at end of file
+}
+
+// record stores the results and diagnostics of one provider.
+func (r *searchRun) record(name string, results *models.SearchResults, diagnostics ProviderDiagnostics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.combined.Results[name] = results
+	r.combined.DebugInfo[name] = diagnostics.URL
+	r.combined.Weights[name] = r.settings[name].weight
+	r.diagnostics[name] = diagnostics
+}
+
+// result builds the SearchResult once every provider has finished.
+func (r *searchRun) result(metadata *SearchMetadata) *SearchResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &SearchResult{
+		Results:     r.combined,
+		Metadata:    metadata,
+		Diagnostics: r.diagnostics,
+	}
+}
+
+// countTorrents returns the number of torrents across all result categories.
+func countTorrents(results *models.SearchResults) int {
+	return len(results.MovieTorrents) + len(results.CompleteSeriesTorrents) +
+		len(results.CompleteSeasonTorrents) + len(results.EpisodeTorrents)
+}
@@ -0,0 +1,163 @@
+package torrentsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/models"
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/providers"
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/translator"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// stubProvider returns one torrent named after the query, or a fixed error
+type stubProvider struct {
+	err error
+}
+
+func (p *stubProvider) Search(ctx context.Context, options models.SearchOptions) (*models.SearchResults, error) {
+	time.Sleep(time.Millisecond)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &models.SearchResults{
+		MovieTorrents: []models.TorrentInfo{{Title: options.Query + ".2020.1080p.WEB"}},
+	}, nil
+}
+
+func (p *stubProvider) GetTorrentHash(ctx context.Context, torrentID string) (string, error) {
+	return torrentID, nil
+}
+func (p *stubProvider) SetCache(cache interface{}) {}
+func (p *stubProvider) SearchURL(options models.SearchOptions) string {
+	return "stub://search?q=" + options.Query
+}
+
+// stubMetadata resolves every query to English content with the query as title
+type stubMetadata struct{}
+
+func (stubMetadata) FetchMetadata(ctx context.Context, query string, mediaType string) (*translator.ContentMetadata, error) {
+	return &translator.ContentMetadata{OriginalLanguage: "en", EnglishTitle: query}, nil
+}
+
+func (stubMetadata) FetchMetadataByIMDBID(ctx context.Context, imdbID string, mediaType string) (*translator.ContentMetadata, error) {
+	return nil, errors.New("not found")
+}
+
+func TestSearchSmartConcurrentCallers(t *testing.T) {
+	ts := New(nil)
+	ts.newMetadataSource = func(apiKey string) metadataSource { return stubMetadata{} }
+	ts.RegisterProvider(providers.ProviderApiBay, &stubProvider{})
+	ts.RegisterProvider(providers.ProviderTorrentsCSV, &stubProvider{err: errors.New("unavailable")})
+
+	const callers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			query := fmt.Sprintf("Title%d", i)
+
+			result, err := ts.SearchSmart(context.Background(), SearchRequest{TMDBAPIKey: "key", Query: query, MediaType: "movie"})
+			if err != nil {
+				errs <- fmt.Errorf("%s: unexpected error: %v", query, err)
+				return
+			}
+
+			ok := result.Diagnostics[providers.ProviderApiBay]
+			if ok.Err != nil || ok.Count != 1 || !strings.Contains(ok.URL, query) {
+				errs <- fmt.Errorf("%s: unexpected apibay diagnostics: %+v", query, ok)
+			}
+			if torrents := result.Results.Results[providers.ProviderApiBay].MovieTorrents; len(torrents) != 1 || !strings.HasPrefix(torrents[0].Title, query+".") {
+				errs <- fmt.Errorf("%s: got results of another search: %+v", query, torrents)
+			}
+			if failed := result.Diagnostics[providers.ProviderTorrentsCSV]; failed.Err == nil || failed.Count != 0 {
+				errs <- fmt.Errorf("%s: expected torrentscsv error, got %+v", query, failed)
+			}
+		}(i)
+	}
+
+	// Registering providers while searches run must not race with them
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ts.RegisterProvider(fmt.Sprintf("extra%d", i), &stubProvider{})
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestSearchSmartSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	ts := New(nil)
+	ts.tracer = provider.Tracer(tracerName)
+	ts.newMetadataSource = func(apiKey string) metadataSource { return stubMetadata{} }
+	ts.RegisterProvider(providers.ProviderApiBay, &stubProvider{})
+	ts.RegisterProvider(providers.ProviderTorrentsCSV, &stubProvider{err: errors.New("unavailable")})
+
+	if _, err := ts.SearchSmart(context.Background(), SearchRequest{TMDBAPIKey: "key", Query: "Title", MediaType: "movie"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	var root tracetest.SpanStub
+	for _, span := range spans {
+		if span.Name == "torrentsearch.SearchSmart" {
+			root = span
+		}
+	}
+	if !root.SpanContext.IsValid() {
+		t.Fatalf("no SearchSmart span in %d spans", len(spans))
+	}
+
+	children := make(map[string]tracetest.SpanStub)
+	for _, span := range spans {
+		if span.Parent.SpanID() != root.SpanContext.SpanID() {
+			continue
+		}
+		name := span.Name
+		for _, attr := range span.Attributes {
+			if attr.Key == "provider" {
+				name += " " + attr.Value.AsString()
+			}
+		}
+		children[name] = span
+	}
+
+	tests := []struct {
+		name   string
+		status codes.Code
+	}{
+		{"torrentsearch.fetchMetadata", codes.Unset},
+		{"torrentsearch.searchProvider " + providers.ProviderApiBay, codes.Unset},
+		{"torrentsearch.searchProvider " + providers.ProviderTorrentsCSV, codes.Error},
+	}
+	for _, tt := range tests {
+		span, ok := children[tt.name]
+		if !ok {
+			t.Errorf("missing child span %q, got %v", tt.name, children)
+			continue
+		}
+		if span.Status.Code != tt.status {
+			t.Errorf("%s: status = %v, want %v", tt.name, span.Status.Code, tt.status)
+		}
+	}
+}
@@ -5,13 +5,22 @@ import (
 	"sort"
 
 	"github.com/amaumene/gostremiofr/pkg/torrentsearch/models"
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/scoring"
 	"github.com/cehbz/torrentname"
 )
 
-type TorrentSorter struct{}
+type TorrentSorter struct {
+	engine *scoring.Engine
+}
 
+// NewTorrentSorter creates a sorter ranking torrents with the default scoring weights.
 func NewTorrentSorter() *TorrentSorter {
-	return &TorrentSorter{}
+	return NewTorrentSorterWithWeights(scoring.DefaultWeights())
+}
+
+// NewTorrentSorterWithWeights creates a sorter ranking torrents with the given scoring weights.
+func NewTorrentSorterWithWeights(weights scoring.Weights) *TorrentSorter {
+	return &TorrentSorter{engine: scoring.New(weights)}
 }
 
 func (ts *TorrentSorter) ParseAndScore(torrents []models.TorrentInfo) []models.TorrentInfo {
@@ -48,11 +57,33 @@ func (ts *TorrentSorter) SortByConfidence(torrents []models.TorrentInfo) []model
 	return torrents
 }
 
+// SortByScore parses the torrents and orders them by descending score.
+func (ts *TorrentSorter) SortByScore(torrents []models.TorrentInfo) []models.TorrentInfo {
+	torrents = ts.ParseAndScore(torrents)
+	scoring.Sort(ts.engine, torrents, scoringInput)
+	return torrents
+}
+
+// Score returns the score breakdown of a parsed torrent.
+func (ts *TorrentSorter) Score(torrent models.TorrentInfo) scoring.Breakdown {
+	return ts.engine.Score(scoringInput(torrent))
+}
+
+// scoringInput converts a torrent to the scoring engine input.
+func scoringInput(torrent models.TorrentInfo) scoring.Torrent {
+	return scoring.Torrent{
+		Title:      torrent.Title,
+		Size:       torrent.Size,
+		Seeders:    torrent.Seeders,
+		Confidence: torrent.ConfidenceScore,
+	}
+}
+
 func (ts *TorrentSorter) SortResults(results *models.SearchResults) {
-	results.MovieTorrents = ts.SortByConfidence(results.MovieTorrents)
-	results.CompleteSeriesTorrents = ts.SortByConfidence(results.CompleteSeriesTorrents)
-	results.CompleteSeasonTorrents = ts.SortByConfidence(results.CompleteSeasonTorrents)
-	results.EpisodeTorrents = ts.SortByConfidence(results.EpisodeTorrents)
+	results.MovieTorrents = ts.SortByScore(results.MovieTorrents)
+	results.CompleteSeriesTorrents = ts.SortByScore(results.CompleteSeriesTorrents)
+	results.CompleteSeasonTorrents = ts.SortByScore(results.CompleteSeasonTorrents)
+	results.EpisodeTorrents = ts.SortByScore(results.EpisodeTorrents)
 }
 
 func (ts *TorrentSorter) FilterByMinConfidence(torrents []models.TorrentInfo, minConfidence float64) []models.TorrentInfo {
@@ -91,9 +122,9 @@ func (ts *TorrentSorter) IsCompleteSeries(torrent models.TorrentInfo) bool {
 	return torrent.ParsedInfo.IsComplete || (torrent.ParsedInfo.Season == 0 && torrent.ParsedInfo.Episode == 0)
 }
 
-// GetSortedWithDebugInfo returns sorted torrents with debug information
+// GetSortedWithDebugInfo returns torrents sorted by score with their confidence and score breakdown
 func (ts *TorrentSorter) GetSortedWithDebugInfo(torrents []models.TorrentInfo) ([]models.TorrentInfo, []string) {
-	sorted := ts.SortByConfidence(torrents)
+	sorted := ts.SortByScore(torrents)
 	var debugInfo []string
 	
 	for i, t := range sorted {
@@ -106,11 +137,12 @@ func (ts *TorrentSorter) GetSortedWithDebugInfo(torrents []models.TorrentInfo) (
 				t.ParsedInfo.Source,
 				t.ParsedInfo.Codec)
 		}
-		debugInfo = append(debugInfo, fmt.Sprintf("%d. %.0f%% - %s%s", 
+		debugInfo = append(debugInfo, fmt.Sprintf("%d. %.0f%% - %s%s - %s", 
 			i+1, 
 			t.ConfidenceScore, 
 			t.Title,
-			details))
+			details,
+			ts.Score(t)))
 	}
 	
 	return sorted, debugInfo
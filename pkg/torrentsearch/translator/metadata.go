@@ -1,6 +1,7 @@
 package translator
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -54,11 +55,13 @@ type TMDBTVDetail struct {
 type TMDBFindResponse struct {
 	MovieResults []struct {
 		ID               int    `json:"id"`
+		OriginalTitle    string `json:"original_title"`
 		OriginalLanguage string `json:"original_language"`
 		ReleaseDate      string `json:"release_date"`
 	} `json:"movie_results"`
 	TVResults []struct {
 		ID               int    `json:"id"`
+		OriginalName     string `json:"original_name"`
 		OriginalLanguage string `json:"original_language"`
 		FirstAirDate     string `json:"first_air_date"`
 	} `json:"tv_results"`
@@ -74,7 +77,7 @@ func NewMetadataFetcher(apiKey string, cache Cache) *MetadataFetcher {
 	}
 }
 
-func (mf *MetadataFetcher) FetchMetadata(query string, mediaType string) (*ContentMetadata, error) {
+func (mf *MetadataFetcher) FetchMetadata(ctx context.Context, query string, mediaType string) (*ContentMetadata, error) {
 	if mf.tmdbAPIKey == "" {
 		return nil, fmt.Errorf("TMDB API key not configured")
 	}
@@ -89,7 +92,7 @@ func (mf *MetadataFetcher) FetchMetadata(query string, mediaType string) (*Conte
 	}
 
 	// Search for the content
-	searchResult, err := mf.searchTMDB(query, mediaType)
+	searchResult, err := mf.searchTMDB(ctx, query, mediaType)
 	if err != nil || len(searchResult.Results) == 0 {
 		return nil, fmt.Errorf("content not found on TMDB")
 	}
@@ -115,7 +118,7 @@ func (mf *MetadataFetcher) FetchMetadata(query string, mediaType string) (*Conte
 	// Fetch English title
 	go func() {
 		defer wg.Done()
-		if englishTitle := mf.fetchTitleInLanguage(tmdbID, mediaType, "en-US"); englishTitle != "" {
+		if englishTitle := mf.fetchTitleInLanguage(ctx, tmdbID, mediaType, "en-US"); englishTitle != "" {
 			metadata.EnglishTitle = englishTitle
 		}
 	}()
@@ -123,7 +126,7 @@ func (mf *MetadataFetcher) FetchMetadata(query string, mediaType string) (*Conte
 	// Fetch French title
 	go func() {
 		defer wg.Done()
-		if frenchTitle := mf.fetchTitleInLanguage(tmdbID, mediaType, "fr-FR"); frenchTitle != "" {
+		if frenchTitle := mf.fetchTitleInLanguage(ctx, tmdbID, mediaType, "fr-FR"); frenchTitle != "" {
 			metadata.FrenchTitle = frenchTitle
 		}
 	}()
@@ -154,7 +157,7 @@ func (mf *MetadataFetcher) FetchMetadata(query string, mediaType string) (*Conte
 }
 
 // FetchMetadataByIMDBID fetches metadata using IMDB ID lookup
-func (mf *MetadataFetcher) FetchMetadataByIMDBID(imdbID string, mediaType string) (*ContentMetadata, error) {
+func (mf *MetadataFetcher) FetchMetadataByIMDBID(ctx context.Context, imdbID string, mediaType string) (*ContentMetadata, error) {
 	if mf.tmdbAPIKey == "" {
 		return nil, fmt.Errorf("TMDB API key not configured")
 	}
@@ -169,13 +172,14 @@ func (mf *MetadataFetcher) FetchMetadataByIMDBID(imdbID string, mediaType string
 	}
 
 	// Use find API to get content by IMDB ID
-	findResult, err := mf.findByIMDBID(imdbID)
+	findResult, err := mf.findByIMDBID(ctx, imdbID)
 	if err != nil {
 		return nil, err
 	}
 
 	var tmdbID int
 	var originalLanguage string
+	var originalTitle string
 	var year int
 
 	// Extract data from find result
@@ -183,6 +187,7 @@ func (mf *MetadataFetcher) FetchMetadataByIMDBID(imdbID string, mediaType string
 		movie := findResult.MovieResults[0]
 		tmdbID = movie.ID
 		originalLanguage = movie.OriginalLanguage
+		originalTitle = movie.OriginalTitle
 		if movie.ReleaseDate != "" {
 			fmt.Sscanf(movie.ReleaseDate, "%d", &year)
 		}
@@ -190,6 +195,7 @@ func (mf *MetadataFetcher) FetchMetadataByIMDBID(imdbID string, mediaType string
 		tv := findResult.TVResults[0]
 		tmdbID = tv.ID
 		originalLanguage = tv.OriginalLanguage
+		originalTitle = tv.OriginalName
 		if tv.FirstAirDate != "" {
 			fmt.Sscanf(tv.FirstAirDate, "%d", &year)
 		}
@@ -198,6 +204,7 @@ func (mf *MetadataFetcher) FetchMetadataByIMDBID(imdbID string, mediaType string
 	}
 
 	metadata := &ContentMetadata{
+		OriginalTitle:    originalTitle,
 		OriginalLanguage: originalLanguage,
 		Year:             year,
 	}
@@ -209,7 +216,7 @@ func (mf *MetadataFetcher) FetchMetadataByIMDBID(imdbID string, mediaType string
 	// Fetch English title
 	go func() {
 		defer wg.Done()
-		if englishTitle := mf.fetchTitleInLanguage(tmdbID, mediaType, "en-US"); englishTitle != "" {
+		if englishTitle := mf.fetchTitleInLanguage(ctx, tmdbID, mediaType, "en-US"); englishTitle != "" {
 			metadata.EnglishTitle = englishTitle
 		}
 	}()
@@ -217,7 +224,7 @@ func (mf *MetadataFetcher) FetchMetadataByIMDBID(imdbID string, mediaType string
 	// Fetch French title
 	go func() {
 		defer wg.Done()
-		if frenchTitle := mf.fetchTitleInLanguage(tmdbID, mediaType, "fr-FR"); frenchTitle != "" {
+		if frenchTitle := mf.fetchTitleInLanguage(ctx, tmdbID, mediaType, "fr-FR"); frenchTitle != "" {
 			metadata.FrenchTitle = frenchTitle
 		}
 	}()
@@ -231,7 +238,7 @@ func (mf *MetadataFetcher) FetchMetadataByIMDBID(imdbID string, mediaType string
 	return metadata, nil
 }
 
-func (mf *MetadataFetcher) searchTMDB(query string, mediaType string) (*TMDBSearchResponse, error) {
+func (mf *MetadataFetcher) searchTMDB(ctx context.Context, query string, mediaType string) (*TMDBSearchResponse, error) {
 	endpoint := "movie"
 	if mediaType == "series" || mediaType == "tv" {
 		endpoint = "tv"
@@ -240,7 +247,7 @@ func (mf *MetadataFetcher) searchTMDB(query string, mediaType string) (*TMDBSear
 	url := fmt.Sprintf("https://api.themoviedb.org/3/search/%s?api_key=%s&query=%s",
 		endpoint, mf.tmdbAPIKey, query)
 
-	resp, err := mf.httpClient.Get(url)
+	resp, err := mf.get(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -263,11 +270,11 @@ func (mf *MetadataFetcher) searchTMDB(query string, mediaType string) (*TMDBSear
 	return &result, nil
 }
 
-func (mf *MetadataFetcher) findByIMDBID(imdbID string) (*TMDBFindResponse, error) {
+func (mf *MetadataFetcher) findByIMDBID(ctx context.Context, imdbID string) (*TMDBFindResponse, error) {
 	url := fmt.Sprintf("https://api.themoviedb.org/3/find/%s?api_key=%s&external_source=imdb_id",
 		imdbID, mf.tmdbAPIKey)
 
-	resp, err := mf.httpClient.Get(url)
+	resp, err := mf.get(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -290,7 +297,7 @@ func (mf *MetadataFetcher) findByIMDBID(imdbID string) (*TMDBFindResponse, error
 	return &result, nil
 }
 
-func (mf *MetadataFetcher) fetchTitleInLanguage(tmdbID int, mediaType string, language string) string {
+func (mf *MetadataFetcher) fetchTitleInLanguage(ctx context.Context, tmdbID int, mediaType string, language string) string {
 	endpoint := "movie"
 	if mediaType == "series" || mediaType == "tv" {
 		endpoint = "tv"
@@ -299,7 +306,7 @@ func (mf *MetadataFetcher) fetchTitleInLanguage(tmdbID int, mediaType string, la
 	url := fmt.Sprintf("https://api.themoviedb.org/3/%s/%d?api_key=%s&language=%s",
 		endpoint, tmdbID, mf.tmdbAPIKey, language)
 
-	resp, err := mf.httpClient.Get(url)
+	resp, err := mf.get(ctx, url)
 	if err != nil {
 		return ""
 	}
@@ -327,4 +334,12 @@ func (mf *MetadataFetcher) fetchTitleInLanguage(tmdbID int, mediaType string, la
 		}
 		return detail.Name
 	}
-}
\ No newline at end of file
+}
+// get performs a GET request that is abandoned when ctx is cancelled
+func (mf *MetadataFetcher) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return mf.httpClient.Do(req)
+}
@@ -3,8 +3,10 @@ package utils
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/amaumene/gostremiofr/pkg/torrentsearch/filematch"
 	"github.com/cehbz/torrentname"
 )
 
@@ -23,6 +25,27 @@ func BuildSearchQuery(query string, mediaType string, season, episode int, speci
 	}
 }
 
+// BuildCompleteSeriesQuery builds a search query for packs holding every season of a series.
+func BuildCompleteSeriesQuery(query string, language string) string {
+	title, _ := extractTitleAndYear(query)
+	return formatQueryString(title) + "+" + CompleteSeriesKeyword(language)
+}
+
+// CompleteSeriesKeyword returns the word releases use for complete-series packs in the search language.
+func CompleteSeriesKeyword(language string) string {
+	if language == "fr" {
+		return "integrale"
+	}
+	return "complete"
+}
+
+// BuildAnimeQuery builds a search query for an anime episode, which releases number
+// absolutely ("[Group] Title - 1043") rather than with s01e05.
+func BuildAnimeQuery(query string, episode int) string {
+	title, _ := extractTitleAndYear(query)
+	return fmt.Sprintf("%s+%02d", formatQueryString(title), episode)
+}
+
 // buildMovieQuery constructs a movie search query.
 func buildMovieQuery(title, year string) string {
 	if year != "" {
@@ -84,8 +107,46 @@ func MatchesEpisode(fileName string, season, episode int) bool {
 	return parsed != nil && parsed.Season == season && parsed.Episode == episode
 }
 
+// MatchesAbsoluteEpisode checks if filename is an anime release of the given episode, e.g. "[Group] Title - 1043".
+// Episode 0 never matches.
+func MatchesAbsoluteEpisode(fileName string, episode int) bool {
+	if episode <= 0 {
+		return false
+	}
+	parsed, ok := filematch.Parse(fileName)
+	return ok && parsed.Season == 0 && parsed.Absolute == episode
+}
+
 // MatchesSeason checks if filename matches specific season.
 func MatchesSeason(fileName string, season int) bool {
 	parsed := torrentname.Parse(fileName)
 	return parsed != nil && parsed.Season == season && parsed.Episode == 0
-}
\ No newline at end of file
+}
+
+var (
+	// Intégrale, Complete Series, The Complete Collection, Série complète
+	completeSeriesRegex = regexp.MustCompile(`(?i)\b(?:int[ée]grale|complete[ ._-]+(?:series|collection)|s[ée]rie[ ._-]+compl[èe]te)`)
+	// S01-S05, Season 1-5, Saisons 1 à 5
+	seasonRangeRegex = regexp.MustCompile(`(?i)\b(?:s|seasons?[ ._]?|saisons?[ ._]?)(\d{1,2})[ ._]*(?:-|à|au|to)[ ._]*(?:s|seasons?[ ._]?|saisons?[ ._]?)?(\d{1,2})\b`)
+	// S02, Season 2, Saison 2
+	singleSeasonRegex = regexp.MustCompile(`(?i)\b(?:s|season|saison)[ ._]?\d{1,2}\b`)
+)
+
+// MatchesCompleteSeries checks if filename is a pack of several seasons including the given one.
+// Season 0 matches any complete-series pack.
+func MatchesCompleteSeries(fileName string, season int) bool {
+	if match := seasonRangeRegex.FindStringSubmatch(fileName); match != nil {
+		first, _ := strconv.Atoi(match[1])
+		last, _ := strconv.Atoi(match[2])
+		if last > first {
+			return season == 0 || (season >= first && season <= last)
+		}
+	}
+
+	// "Saison 2 Intégrale" is a complete season, not a complete series
+	parsed := torrentname.Parse(fileName)
+	if singleSeasonRegex.MatchString(fileName) || (parsed != nil && (parsed.Season > 0 || parsed.Episode > 0)) {
+		return false
+	}
+	return completeSeriesRegex.MatchString(fileName) || (parsed != nil && parsed.IsComplete)
+}
@@ -0,0 +1,69 @@
+package utils
+
+import "testing"
+
+func TestMatchesCompleteSeries(t *testing.T) {
+	tests := []struct {
+		name   string
+		title  string
+		season int
+		want   bool
+	}{
+		{"french integrale", "Kaamelott.Integrale.FRENCH.1080p.WEB.x264", 3, true},
+		{"accented integrale", "Kaamelott Intégrale MULTi 1080p", 0, true},
+		{"complete series", "Breaking.Bad.Complete.Series.1080p.BluRay.x265", 2, true},
+		{"season range", "The.Wire.S01-S05.1080p.BluRay.x264", 4, true},
+		{"season range excludes season", "The.Wire.S01-S03.1080p.BluRay.x264", 4, false},
+		{"worded range", "Dark Saisons 1 à 3 FRENCH 720p", 2, true},
+		{"complete season", "Dark.S02.COMPLETE.1080p.WEB", 2, false},
+		{"integrale of a season", "Dark Saison 2 Integrale FRENCH 1080p", 2, false},
+		{"single episode", "Dark.S02E03.1080p.WEB", 2, false},
+		{"episode range", "Dark.S01E01-03.1080p.WEB", 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchesCompleteSeries(tt.title, tt.season); got != tt.want {
+				t.Errorf("MatchesCompleteSeries(%q, %d) = %v, want %v", tt.title, tt.season, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCompleteSeriesQuery(t *testing.T) {
+	if got := BuildCompleteSeriesQuery("The Wire 2002", "en"); got != "The+Wire+complete" {
+		t.Errorf("english query = %q", got)
+	}
+	if got := BuildCompleteSeriesQuery("Dark", "fr"); got != "Dark+integrale" {
+		t.Errorf("french query = %q", got)
+	}
+}
+
+func TestMatchesAbsoluteEpisode(t *testing.T) {
+	tests := []struct {
+		title   string
+		episode int
+		want    bool
+	}{
+		{"[SubsPlease] One Piece - 1043 (1080p) [8E1B6D35]", 1043, true},
+		{"[Erai-raws] Frieren - 05v2 [1080p][Multiple Subtitle]", 5, true},
+		{"[SubsPlease] One Piece - 1044 (1080p)", 1043, false},
+		{"One.Piece.S01E05.1080p.WEB", 5, false},
+		{"[SubsPlease] One Piece - 1043 (1080p)", 0, false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchesAbsoluteEpisode(tt.title, tt.episode); got != tt.want {
+			t.Errorf("MatchesAbsoluteEpisode(%q, %d) = %v, want %v", tt.title, tt.episode, got, tt.want)
+		}
+	}
+}
+
+func TestBuildAnimeQuery(t *testing.T) {
+	if got := BuildAnimeQuery("One Piece", 1043); got != "One+Piece+1043" {
+		t.Errorf("BuildAnimeQuery = %q", got)
+	}
+	if got := BuildAnimeQuery("Frieren", 5); got != "Frieren+05" {
+		t.Errorf("BuildAnimeQuery = %q", got)
+	}
+}